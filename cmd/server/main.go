@@ -2,13 +2,19 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
+	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"tech-news-agent/internal/api"
 	"tech-news-agent/internal/config"
 	"tech-news-agent/internal/services"
+	"time"
 
 	"github.com/robfig/cron/v3"
 )
@@ -17,6 +23,10 @@ func main() {
 	// Command line flags
 	testMode := flag.Bool("test", false, "Run once immediately for testing")
 	testConnection := flag.Bool("test-connection", false, "Test connections only")
+	noCache := flag.Bool("no-cache", false, "Bypass the LLM response cache")
+	refreshCache := flag.Bool("refresh-cache", false, "Ignore cached LLM responses and store fresh ones")
+	ask := flag.String("ask", "", "Answer a question from the article archive and exit")
+	askDays := flag.Int("ask-days", 30, "Number of days of archive to search with -ask")
 	flag.Parse()
 
 	// Initialize logger
@@ -30,8 +40,53 @@ func main() {
 		logger.Fatalf("Failed to load configuration: %v", err)
 	}
 	logger.Printf("Configuration loaded successfully")
+
+	switch {
+	case *noCache:
+		cfg.LLMCacheMode = services.CacheModeBypass
+	case *refreshCache:
+		cfg.LLMCacheMode = services.CacheModeRefresh
+	}
+	// Answering a question delivers nothing, so no notifier (or Telegram
+	// bot) is created for it
+	if *ask != "" {
+		cfg.Personas = nil
+	}
+	if cfg.LLMCacheDir != "" && cfg.LLMCacheMode != services.CacheModeBypass {
+		logger.Printf("LLM cache: %s (ttl %s)", cfg.LLMCacheDir, cfg.LLMCacheTTL)
+	}
 	logger.Printf("Using Gemini model: %s", cfg.GeminiModel)
+	logger.Printf("Summary parameters: %s", cfg.SummaryParams)
+	if cfg.EnrichArticles {
+		logger.Printf("Enrichment parameters: %s", cfg.EnrichmentParams)
+	}
+	for provider, overrides := range cfg.ProviderParams {
+		if !overrides.IsZero() {
+			logger.Printf("%s overrides: %s", provider, cfg.SummaryParams.With(overrides))
+		}
+	}
+	if len(cfg.GeminiFallbackModels) > 0 {
+		logger.Printf("Fallback models: %s", strings.Join(cfg.GeminiFallbackModels, ", "))
+	}
+	if cfg.OllamaURL != "" {
+		logger.Printf("Local fallback: ollama/%s at %s", cfg.OllamaModel, cfg.OllamaURL)
+	}
+	if cfg.TopicClustering {
+		logger.Printf("Topic clustering: %s embeddings", cfg.EmbeddingProvider)
+	}
 	logger.Printf("Schedule: %s", cfg.CronSchedule)
+	for _, persona := range cfg.Personas {
+		for _, chat := range persona.Chats {
+			logger.Printf("Telegram recipient: chat %d (%s, persona %s)", chat.ID, chat.Language, persona.Name)
+		}
+		for _, dest := range persona.Destinations {
+			target := dest.Target
+			if dest.IsWebhook() {
+				target = "webhook"
+			}
+			logger.Printf("%s recipient: %s (%s, persona %s)", dest.Kind, target, dest.Language, persona.Name)
+		}
+	}
 
 	// Create news agent
 	agent, err := services.NewNewsAgent(cfg, logger)
@@ -50,12 +105,37 @@ func main() {
 		return
 	}
 
+	// Q&A mode - answer a question from the archive
+	if *ask != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+
+		answer, err := agent.Ask(ctx, *ask, time.Now().AddDate(0, 0, -*askDays))
+		if err != nil {
+			logger.Fatalf("Answering question failed: %v", err)
+		}
+
+		fmt.Println(answer.Answer)
+		if len(answer.Citations) > 0 {
+			fmt.Println()
+			for _, c := range answer.Citations {
+				fmt.Printf("[%d] %s (%s, %s)\n    %s\n", c.Index, c.Title, c.Source, c.PublishedAt.Format("2006-01-02"), c.URL)
+			}
+		}
+		return
+	}
+
 	// Test mode - run once immediately
 	if *testMode {
 		logger.Println("Running in test mode (single execution)...")
-		if err := agent.TestRun(); err != nil {
+		result, err := agent.TestRun()
+		if err != nil {
 			logger.Fatalf("Test run failed: %v", err)
 		}
+		if failed := result.Failed(); len(failed) > 0 {
+			logger.Printf("⚠️ Test run completed with %d failed deliveries", len(failed))
+			return
+		}
 		logger.Println("Test run completed successfully!")
 		return
 	}
@@ -82,14 +162,35 @@ func main() {
 	_, err = c.AddFunc(cfg.CronSchedule, func() {
 		logger.Println("Cron job triggered")
 		ctx := context.Background()
-		if err := agent.Run(ctx); err != nil {
+		result, err := agent.Run(ctx)
+		if err != nil {
 			logger.Printf("❌ Job execution failed: %v", err)
+			return
+		}
+		if failed := result.Failed(); len(failed) > 0 {
+			logger.Printf("⚠️ Job delivered to %d of %d destinations", result.Delivered(), len(result.Deliveries))
 		}
 	})
 	if err != nil {
 		logger.Fatalf("Failed to add cron job: %v", err)
 	}
 
+	// Start the HTTP API (archive Q&A, digest pages and metrics) if configured
+	var server *http.Server
+	if cfg.HTTPAddr != "" {
+		server = &http.Server{
+			Addr:              cfg.HTTPAddr,
+			Handler:           api.NewHandler(agent, agent.DigestDir(), cfg.APIToken, cfg.PublicDigests, logger),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		go func() {
+			logger.Printf("🌐 HTTP API listening on %s", cfg.HTTPAddr)
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Printf("❌ HTTP server failed: %v", err)
+			}
+		}()
+	}
+
 	// Start the scheduler
 	c.Start()
 	logger.Println("✅ Scheduler started successfully")
@@ -101,6 +202,13 @@ func main() {
 	<-sigChan
 
 	logger.Println("Shutting down gracefully...")
+	if server != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := server.Shutdown(ctx); err != nil {
+			logger.Printf("HTTP server shutdown: %v", err)
+		}
+		cancel()
+	}
 	c.Stop()
 	logger.Println("Goodbye!")
 }
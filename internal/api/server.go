@@ -0,0 +1,154 @@
+// Package api exposes the agent over HTTP: archive Q&A, stored digests and
+// runtime metrics
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"tech-news-agent/internal/models"
+	"time"
+)
+
+// defaultAskDays is the archive window searched when none is given
+const defaultAskDays = 30
+
+// maxAskBody is the largest JSON body accepted by POST /api/ask
+const maxAskBody = 64 << 10
+
+// Asker answers questions over the article archive
+type Asker interface {
+	Ask(ctx context.Context, question string, since time.Time) (*models.Answer, error)
+}
+
+// askRequest is the JSON body accepted by POST /api/ask
+type askRequest struct {
+	Question string `json:"question"`
+	Days     int    `json:"days"`
+}
+
+// NewHandler returns the HTTP routes of the agent. Q&A and metrics require
+// token as a bearer token. Full digest pages are served from digestDir under
+// /digests/, behind the same token unless publicDigests is set so links in
+// messages open in a browser.
+func NewHandler(asker Asker, digestDir, token string, publicDigests bool, logger *log.Logger) http.Handler {
+	var digests http.Handler = http.StripPrefix("/digests/", http.FileServer(filesOnly{http.Dir(digestDir)}))
+	if !publicDigests {
+		digests = requireToken(token, digests)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/api/ask", requireToken(token, askHandler(asker, logger)))
+	mux.Handle("/digests/", digests)
+	mux.Handle("/debug/vars", requireToken(token, expvar.Handler()))
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	return mux
+}
+
+// requireToken rejects requests without "Authorization: Bearer <token>".
+// An empty token rejects every request.
+func requireToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// filesOnly is a file system whose directories cannot be opened, so a file
+// server on it serves single files and never lists a directory
+type filesOnly struct {
+	fs http.FileSystem
+}
+
+func (f filesOnly) Open(name string) (http.File, error) {
+	file, err := f.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		file.Close()
+		return nil, os.ErrNotExist
+	}
+	return file, nil
+}
+
+// askHandler serves GET /api/ask?q=...&days=30 and POST /api/ask with a
+// JSON body
+func askHandler(asker Asker, logger *log.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req askRequest
+
+		switch r.Method {
+		case http.MethodGet:
+			req.Question = r.URL.Query().Get("q")
+			if days := r.URL.Query().Get("days"); days != "" {
+				parsed, err := strconv.Atoi(days)
+				if err != nil {
+					writeError(w, http.StatusBadRequest, "days must be a number")
+					return
+				}
+				req.Days = parsed
+			}
+		case http.MethodPost:
+			r.Body = http.MaxBytesReader(w, r.Body, maxAskBody)
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					writeError(w, http.StatusRequestEntityTooLarge, "request body too large")
+					return
+				}
+				writeError(w, http.StatusBadRequest, "invalid JSON body")
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		if strings.TrimSpace(req.Question) == "" {
+			writeError(w, http.StatusBadRequest, "question is required")
+			return
+		}
+		if req.Days <= 0 {
+			req.Days = defaultAskDays
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 2*time.Minute)
+		defer cancel()
+
+		answer, err := asker.Ask(ctx, req.Question, time.Now().AddDate(0, 0, -req.Days))
+		if err != nil {
+			logger.Printf("❌ Answering %q failed: %v", req.Question, err)
+			writeError(w, http.StatusInternalServerError, "could not answer the question")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, answer)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
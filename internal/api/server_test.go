@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"tech-news-agent/internal/models"
+	"testing"
+	"time"
+)
+
+const testToken = "secret"
+
+type fakeAsker struct{}
+
+func (fakeAsker) Ask(ctx context.Context, question string, since time.Time) (*models.Answer, error) {
+	return &models.Answer{Question: question, Answer: "yes"}, nil
+}
+
+func newTestHandler(t *testing.T, publicDigests bool) http.Handler {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "2026-10-11.html"), []byte("<p>digest</p>"), 0o644); err != nil {
+		t.Fatalf("writing digest: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatalf("creating directory: %v", err)
+	}
+	return NewHandler(fakeAsker{}, dir, testToken, publicDigests, log.New(io.Discard, "", 0))
+}
+
+func TestHandlerRoutes(t *testing.T) {
+	tests := []struct {
+		name          string
+		publicDigests bool
+		method        string
+		target        string
+		body          string
+		token         string
+		want          int
+	}{
+		{"ask without token", false, http.MethodGet, "/api/ask?q=go", "", "", http.StatusUnauthorized},
+		{"ask with wrong token", false, http.MethodGet, "/api/ask?q=go", "", "wrong", http.StatusUnauthorized},
+		{"ask", false, http.MethodGet, "/api/ask?q=go", "", testToken, http.StatusOK},
+		{"ask by post", false, http.MethodPost, "/api/ask", `{"question": "go"}`, testToken, http.StatusOK},
+		{"invalid json", false, http.MethodPost, "/api/ask", `{"question":`, testToken, http.StatusBadRequest},
+		{"body too large", false, http.MethodPost, "/api/ask", `{"question": "` + strings.Repeat("a", maxAskBody) + `"}`, testToken, http.StatusRequestEntityTooLarge},
+		{"metrics without token", false, http.MethodGet, "/debug/vars", "", "", http.StatusUnauthorized},
+		{"digest without token", false, http.MethodGet, "/digests/2026-10-11.html", "", "", http.StatusUnauthorized},
+		{"digest with token", false, http.MethodGet, "/digests/2026-10-11.html", "", testToken, http.StatusOK},
+		{"public digest", true, http.MethodGet, "/digests/2026-10-11.html", "", "", http.StatusOK},
+		{"public missing digest", true, http.MethodGet, "/digests/2026-10-04.html", "", "", http.StatusNotFound},
+		{"no listing", true, http.MethodGet, "/digests/", "", "", http.StatusNotFound},
+		{"no sub listing", true, http.MethodGet, "/digests/sub/", "", "", http.StatusNotFound},
+		{"no listing with token", false, http.MethodGet, "/digests/", "", testToken, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			newTestHandler(t, tt.publicDigests).ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("%s %s = %d, want %d: %s", tt.method, tt.target, rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Company is a listed company that market annotations are normalized to
+type Company struct {
+	Name    string   `json:"name"`
+	Ticker  string   `json:"ticker"`
+	Aliases []string `json:"aliases"`
+}
+
+// defaultCompanies covers the companies most often in tech business news,
+// extendable via COMPANIES_FILE
+var defaultCompanies = []Company{
+	{Name: "Apple", Ticker: "AAPL", Aliases: []string{"Apple Inc"}},
+	{Name: "Microsoft", Ticker: "MSFT", Aliases: []string{"Azure", "GitHub", "LinkedIn"}},
+	{Name: "Alphabet", Ticker: "GOOGL", Aliases: []string{"Google", "YouTube", "DeepMind", "Waymo"}},
+	{Name: "Amazon", Ticker: "AMZN", Aliases: []string{"AWS", "Amazon Web Services"}},
+	{Name: "Meta", Ticker: "META", Aliases: []string{"Meta Platforms", "Facebook", "Instagram", "WhatsApp"}},
+	{Name: "Nvidia", Ticker: "NVDA", Aliases: []string{"NVIDIA"}},
+	{Name: "Tesla", Ticker: "TSLA"},
+	{Name: "AMD", Ticker: "AMD", Aliases: []string{"Advanced Micro Devices"}},
+	{Name: "Intel", Ticker: "INTC"},
+	{Name: "TSMC", Ticker: "TSM", Aliases: []string{"Taiwan Semiconductor"}},
+	{Name: "Broadcom", Ticker: "AVGO", Aliases: []string{"VMware"}},
+	{Name: "Oracle", Ticker: "ORCL"},
+	{Name: "Salesforce", Ticker: "CRM", Aliases: []string{"Slack"}},
+	{Name: "IBM", Ticker: "IBM", Aliases: []string{"Red Hat"}},
+	{Name: "Netflix", Ticker: "NFLX"},
+	{Name: "Samsung Electronics", Ticker: "005930.KS", Aliases: []string{"Samsung"}},
+	{Name: "Qualcomm", Ticker: "QCOM"},
+	{Name: "Arm Holdings", Ticker: "ARM", Aliases: []string{"Arm"}},
+	{Name: "Palantir", Ticker: "PLTR"},
+	{Name: "Snowflake", Ticker: "SNOW"},
+}
+
+// loadCompanies returns the default company list, with entries from
+// COMPANIES_FILE added or replacing defaults of the same name
+func loadCompanies(path string) ([]Company, error) {
+	companies := append([]Company(nil), defaultCompanies...)
+	if path == "" {
+		return companies, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading COMPANIES_FILE: %w", err)
+	}
+
+	var entries []Company
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("decoding COMPANIES_FILE: %w", err)
+	}
+
+	for _, entry := range entries {
+		if strings.TrimSpace(entry.Name) == "" {
+			return nil, fmt.Errorf("every company in COMPANIES_FILE needs a name")
+		}
+		replaced := false
+		for i, c := range companies {
+			if strings.EqualFold(c.Name, entry.Name) {
+				companies[i] = entry
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			companies = append(companies, entry)
+		}
+	}
+
+	return companies, nil
+}
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"tech-news-agent/internal/i18n"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -11,12 +14,145 @@ import (
 type Config struct {
 	GeminiAPIKey     string
 	TelegramBotToken string
-	TelegramChatID   int64
 	NewsAPIKey       string
 	CronSchedule     string
 	MaxNewsArticles  int
 	GeminiModel      string
 	NewsCategories   []string
+
+	// Fallback chain for AI analysis
+	GeminiFallbackModels []string
+	OllamaURL            string
+	OllamaModel          string
+	LLMMaxRetries        int
+
+	// Per-article enrichment
+	EnrichArticles      bool
+	EnrichConcurrency   int
+	EnrichRatePerMinute int
+	TopArticlesCount    int
+
+	// LLM response cache
+	LLMCacheDir  string
+	LLMCacheTTL  time.Duration
+	LLMCacheMode string
+
+	// Usage and cost accounting
+	DataDir          string
+	LLMPrices        map[string]ModelPrice
+	MonthlyBudgetUSD float64
+	BudgetAction     string
+	DigestCostFooter bool
+
+	// Generation parameters per digest, with per-provider overrides
+	SummaryParams    GenerationParams
+	EnrichmentParams GenerationParams
+	ProviderParams   map[string]ParamOverrides
+
+	// Telegram recipients and their digest language
+	TelegramChats []TelegramChat
+	// TelegramParseMode is markdownv2 or html
+	TelegramParseMode string
+
+	// Slack delivery through the chat.postMessage API; incoming webhooks
+	// need no token
+	SlackBotToken string
+	SlackAPIURL   string
+
+	// SMTP server for the email newsletter. SMTPTLS is starttls, implicit
+	// or none; EmailUnsubscribeURL may contain an {email} placeholder.
+	SMTPHost            string
+	SMTPPort            int
+	SMTPUsername        string
+	SMTPPassword        string
+	SMTPFrom            string
+	SMTPTLS             string
+	EmailUnsubscribeURL string
+
+	// Matrix delivery through the client-server API, as the user owning
+	// the access token
+	MatrixHomeserverURL string
+	MatrixAccessToken   string
+
+	// Generic JSON webhooks: the HMAC-SHA256 signing secret, extra request
+	// headers and an optional text/template shaping the body
+	WebhookSecret     string
+	WebhookHeaders    map[string]string
+	WebhookTemplate   string
+	WebhookMaxRetries int
+
+	// Recipients on other channels than Telegram, configured per channel
+	// in the environment. They receive the default persona's digest.
+	Destinations []Destination
+
+	// Audiences sharing one collection run, each with its own recipients.
+	// The default persona delivers to TelegramChats and Destinations.
+	Personas []Persona
+
+	// Source languages requested from NewsAPI, and the detected languages
+	// kept after ingestion (empty keeps everything)
+	NewsLanguages    []string
+	ArticleLanguages []string
+
+	// Hallucination guard: off, mark or remove unsupported sentences
+	GroundingMode      string
+	GroundingLLMVerify bool
+
+	// Embedding based topic clustering
+	TopicClustering   bool
+	TopicClusters     int
+	EmbeddingProvider string
+	EmbeddingModel    string
+	OpenAIBaseURL     string
+	OpenAIAPIKey      string
+
+	// Sentiment and company annotations for business stories
+	MarketPulse bool
+	Companies   []Company
+
+	// Entities whose stories always get their own digest section
+	Watchlist []WatchEntity
+
+	// Base URL under which DATA_DIR/digests is served, used to link the
+	// full version of shortened digests
+	PublicBaseURL string
+
+	// Q&A over the article archive
+	QASearch string
+	QATopK   int
+	HTTPAddr string
+	// Bearer token required by /api/ask, /debug/vars and, unless
+	// PublicDigests is set, /digests/
+	APIToken string
+	// PublicDigests serves the full digest pages without the token, so the
+	// links in shortened messages open in a browser
+	PublicDigests bool
+}
+
+// TelegramChat is a Telegram recipient with its preferred output language
+// and the maximum length of a digest message
+type TelegramChat struct {
+	ID       int64
+	Language string
+	MaxChars int
+}
+
+// charsPerToken converts token budgets into character budgets
+const charsPerToken = 4
+
+// ModelPrice is the USD price per one million tokens for a model
+type ModelPrice struct {
+	InputPerMillion  float64
+	OutputPerMillion float64
+}
+
+// defaultPrices covers the Gemini models we use, overridable via LLM_PRICES.
+// Models without an entry (e.g. local Ollama models) are treated as free.
+var defaultPrices = map[string]ModelPrice{
+	"gemini-2.5-pro":        {InputPerMillion: 1.25, OutputPerMillion: 10.00},
+	"gemini-2.5-flash":      {InputPerMillion: 0.30, OutputPerMillion: 2.50},
+	"gemini-2.5-flash-lite": {InputPerMillion: 0.10, OutputPerMillion: 0.40},
+	"gemini-2.0-flash":      {InputPerMillion: 0.10, OutputPerMillion: 0.40},
 }
 
 // Load holds all application configuration
@@ -24,11 +160,91 @@ func Load() (*Config, error) {
 
 	_ = godotenv.Load()
 
-	chatID, err := strconv.ParseInt(os.Getenv("TELEGRAM_CHAT_ID"), 10, 64)
+	var err error
+
+	var chatID int64
+	if value := os.Getenv("TELEGRAM_CHAT_ID"); value != "" {
+		chatID, err = strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TELEGRAM_CHAT_ID: %w", err)
+		}
+	}
+
+	maxChars := 4000
+	if value := os.Getenv("TELEGRAM_MAX_CHARS"); value != "" {
+		maxChars, err = parseLengthBudget(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TELEGRAM_MAX_CHARS: %w", err)
+		}
+	}
+
+	chats, err := parseTelegramChats(os.Getenv("TELEGRAM_CHATS"), chatID, os.Getenv("DIGEST_LANGUAGE"), maxChars)
 	if err != nil {
 		return nil, err
 	}
 
+	digestLanguage := strings.ToLower(strings.TrimSpace(os.Getenv("DIGEST_LANGUAGE")))
+	if digestLanguage == "" {
+		digestLanguage = "en"
+	}
+
+	destinations, err := loadDestinations(digestLanguage)
+	if err != nil {
+		return nil, err
+	}
+
+	personas, err := loadPersonas(os.Getenv("PERSONAS_FILE"), chats, destinations, digestLanguage, maxChars)
+	if err != nil {
+		return nil, err
+	}
+
+	smtpPort := envInt("SMTP_PORT", 587)
+	smtpTLS := strings.ToLower(os.Getenv("SMTP_TLS"))
+	if smtpTLS == "" {
+		smtpTLS = "starttls"
+		if smtpPort == 465 {
+			smtpTLS = "implicit"
+		}
+	}
+
+	webhookHeaders, err := parseWebhookHeaders(os.Getenv("WEBHOOK_HEADERS"))
+	if err != nil {
+		return nil, err
+	}
+
+	webhookTemplate, err := loadWebhookTemplate(os.Getenv("WEBHOOK_TEMPLATE_FILE"))
+	if err != nil {
+		return nil, err
+	}
+
+	// 0 is valid and disables retries
+	webhookMaxRetries := 3
+	if value := os.Getenv("WEBHOOK_MAX_RETRIES"); value != "" {
+		webhookMaxRetries, err = strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || webhookMaxRetries < 0 {
+			return nil, fmt.Errorf("invalid WEBHOOK_MAX_RETRIES: %q", value)
+		}
+	}
+
+	// 0 is valid and disables retries
+	llmMaxRetries := 3
+	if value := os.Getenv("LLM_MAX_RETRIES"); value != "" {
+		llmMaxRetries, err = strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || llmMaxRetries < 0 {
+			return nil, fmt.Errorf("invalid LLM_MAX_RETRIES: %q", value)
+		}
+	}
+
+	telegramParseMode := strings.ToLower(os.Getenv("TELEGRAM_PARSE_MODE"))
+	if telegramParseMode == "" {
+		telegramParseMode = "markdownv2"
+	}
+
+	slackAPIURL := os.Getenv("SLACK_API_URL")
+	if slackAPIURL == "" {
+		slackAPIURL = "https://slack.com/api"
+	}
+
 	maxArticles := 20
 	if max := os.Getenv("MAX_NEWS_ARTICLES"); max != "" {
 		if parsed, err := strconv.Atoi(max); err == nil {
@@ -43,19 +259,187 @@ func Load() (*Config, error) {
 	}
 
 	geminiModel := os.Getenv("GEMINI_MODEL")
-	if geminiModel != "" {
+	if geminiModel == "" {
 		geminiModel = "gemini-2.5-flash"
 	}
 
+	ollamaURL := os.Getenv("OLLAMA_URL")
+	ollamaModel := os.Getenv("OLLAMA_MODEL")
+	if ollamaURL != "" && ollamaModel == "" {
+		ollamaModel = "llama3.1"
+	}
+
+	enrichArticles, _ := strconv.ParseBool(os.Getenv("ENRICH_ARTICLES"))
+
+	cacheTTL := 7 * 24 * time.Hour
+	if ttl := os.Getenv("LLM_CACHE_TTL"); ttl != "" {
+		parsed, err := time.ParseDuration(ttl)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LLM_CACHE_TTL: %w", err)
+		}
+		cacheTTL = parsed
+	}
+
+	prices, err := parsePrices(os.Getenv("LLM_PRICES"))
+	if err != nil {
+		return nil, err
+	}
+
+	var budget float64
+	if value := os.Getenv("MONTHLY_BUDGET_USD"); value != "" {
+		budget, err = strconv.ParseFloat(value, 64)
+		if err != nil || budget < 0 {
+			return nil, fmt.Errorf("invalid MONTHLY_BUDGET_USD: %q", value)
+		}
+	}
+
+	budgetAction := strings.ToLower(os.Getenv("BUDGET_ACTION"))
+	if budgetAction == "" {
+		budgetAction = "downgrade"
+	}
+
+	dataDir := os.Getenv("DATA_DIR")
+	if dataDir == "" {
+		dataDir = "data"
+	}
+
+	costFooter, _ := strconv.ParseBool(os.Getenv("DIGEST_COST_FOOTER"))
+
+	groundingMode := strings.ToLower(os.Getenv("GROUNDING_MODE"))
+	if groundingMode == "" {
+		groundingMode = "off"
+	}
+	groundingLLMVerify, _ := strconv.ParseBool(os.Getenv("GROUNDING_LLM_VERIFY"))
+
+	topicClustering, _ := strconv.ParseBool(os.Getenv("TOPIC_CLUSTERING"))
+
+	publicDigests, _ := strconv.ParseBool(os.Getenv("PUBLIC_DIGESTS"))
+
+	embeddingProvider := strings.ToLower(os.Getenv("EMBEDDING_PROVIDER"))
+	if embeddingProvider == "" {
+		embeddingProvider = "gemini"
+	}
+
+	embeddingModel := os.Getenv("EMBEDDING_MODEL")
+	if embeddingModel == "" {
+		switch embeddingProvider {
+		case "gemini":
+			embeddingModel = "text-embedding-004"
+		case "openai":
+			embeddingModel = "text-embedding-3-small"
+		}
+	}
+
+	openAIBaseURL := os.Getenv("OPENAI_BASE_URL")
+	if openAIBaseURL == "" {
+		openAIBaseURL = "https://api.openai.com/v1"
+	}
+
+	marketPulse, _ := strconv.ParseBool(os.Getenv("MARKET_PULSE"))
+
+	companies, err := loadCompanies(os.Getenv("COMPANIES_FILE"))
+	if err != nil {
+		return nil, err
+	}
+
+	watchlist, err := loadWatchlist(os.Getenv("WATCHLIST_FILE"))
+	if err != nil {
+		return nil, err
+	}
+
+	qaSearch := strings.ToLower(os.Getenv("QA_SEARCH"))
+	if qaSearch == "" {
+		qaSearch = "keyword"
+	}
+
+	newsLanguages := lowerList(splitList(os.Getenv("NEWS_LANGUAGES")))
+	if len(newsLanguages) == 0 {
+		newsLanguages = []string{"en"}
+	}
+
 	cfg := &Config{
 		GeminiAPIKey:     os.Getenv("GEMINI_API_KEY"),
 		TelegramBotToken: os.Getenv("TELEGRAM_BOT_TOKEN"),
-		TelegramChatID:   chatID,
 		NewsAPIKey:       os.Getenv("NEWS_API_KEY"),
 		CronSchedule:     cronSchedule,
 		MaxNewsArticles:  maxArticles,
 		GeminiModel:      geminiModel,
 		NewsCategories:   []string{"technology", "science", "business"},
+
+		GeminiFallbackModels: splitList(os.Getenv("GEMINI_FALLBACK_MODELS")),
+		OllamaURL:            ollamaURL,
+		OllamaModel:          ollamaModel,
+		LLMMaxRetries:        llmMaxRetries,
+
+		EnrichArticles:      enrichArticles,
+		EnrichConcurrency:   envInt("ENRICH_CONCURRENCY", 4),
+		EnrichRatePerMinute: envInt("ENRICH_RATE_PER_MINUTE", 30),
+		TopArticlesCount:    envInt("TOP_ARTICLES", 10),
+
+		LLMCacheDir: os.Getenv("LLM_CACHE_DIR"),
+		LLMCacheTTL: cacheTTL,
+
+		DataDir:          dataDir,
+		LLMPrices:        prices,
+		MonthlyBudgetUSD: budget,
+		BudgetAction:     budgetAction,
+		DigestCostFooter: costFooter,
+
+		TelegramChats:     chats,
+		TelegramParseMode: telegramParseMode,
+		Personas:          personas,
+
+		SlackBotToken: os.Getenv("SLACK_BOT_TOKEN"),
+		SlackAPIURL:   slackAPIURL,
+
+		SMTPHost:            os.Getenv("SMTP_HOST"),
+		SMTPPort:            smtpPort,
+		SMTPUsername:        os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:        os.Getenv("SMTP_PASSWORD"),
+		SMTPFrom:            os.Getenv("SMTP_FROM"),
+		SMTPTLS:             smtpTLS,
+		EmailUnsubscribeURL: os.Getenv("EMAIL_UNSUBSCRIBE_URL"),
+
+		MatrixHomeserverURL: strings.TrimRight(os.Getenv("MATRIX_HOMESERVER_URL"), "/"),
+		MatrixAccessToken:   os.Getenv("MATRIX_ACCESS_TOKEN"),
+
+		WebhookSecret:     os.Getenv("WEBHOOK_SECRET"),
+		WebhookHeaders:    webhookHeaders,
+		WebhookTemplate:   webhookTemplate,
+		WebhookMaxRetries: webhookMaxRetries,
+
+		Destinations: destinations,
+
+		NewsLanguages:    newsLanguages,
+		ArticleLanguages: lowerList(splitList(os.Getenv("ARTICLE_LANGUAGES"))),
+
+		GroundingMode:      groundingMode,
+		GroundingLLMVerify: groundingLLMVerify,
+
+		TopicClustering:   topicClustering,
+		TopicClusters:     envInt("TOPIC_CLUSTERS", 0),
+		EmbeddingProvider: embeddingProvider,
+		EmbeddingModel:    embeddingModel,
+		OpenAIBaseURL:     openAIBaseURL,
+		OpenAIAPIKey:      os.Getenv("OPENAI_API_KEY"),
+
+		QASearch: qaSearch,
+		QATopK:   envInt("QA_TOP_K", 8),
+		HTTPAddr: os.Getenv("HTTP_ADDR"),
+		APIToken: os.Getenv("API_TOKEN"),
+
+		PublicDigests: publicDigests,
+
+		PublicBaseURL: os.Getenv("PUBLIC_BASE_URL"),
+
+		MarketPulse: marketPulse,
+		Companies:   companies,
+
+		Watchlist: watchlist,
+	}
+
+	if err := loadGenerationParams(cfg); err != nil {
+		return nil, err
 	}
 
 	if err := cfg.Validate(); err != nil {
@@ -70,14 +454,165 @@ func (c *Config) Validate() error {
 	if c.GeminiAPIKey == "" {
 		return fmt.Errorf("GEMINI_API_KEY is required")
 	}
-	if c.TelegramBotToken == "" {
-		return fmt.Errorf("TELEGRAM_BOT_TOKEN is required")
+	switch c.GroundingMode {
+	case "off", "mark", "remove":
+	default:
+		return fmt.Errorf("GROUNDING_MODE must be off, mark or remove, got %q", c.GroundingMode)
+	}
+	switch c.TelegramParseMode {
+	case "markdownv2", "html":
+	default:
+		return fmt.Errorf("TELEGRAM_PARSE_MODE must be markdownv2 or html, got %q", c.TelegramParseMode)
+	}
+	if len(c.Personas) == 0 {
+		return fmt.Errorf("TELEGRAM_CHAT_ID, TELEGRAM_CHATS, a channel destination or PERSONAS_FILE is required")
+	}
+	if err := validatePersonas(c.Personas); err != nil {
+		return err
 	}
-	if c.TelegramChatID == 0 {
-		return fmt.Errorf("TELEGRAM_CHAT_ID is required")
+	for _, p := range c.Personas {
+		if len(p.Chats) > 0 && c.TelegramBotToken == "" {
+			return fmt.Errorf("TELEGRAM_BOT_TOKEN is required")
+		}
+		if err := c.validateDestinations(p.Destinations); err != nil {
+			return fmt.Errorf("persona %q: %w", p.Name, err)
+		}
 	}
 	if c.NewsAPIKey == "" {
 		return fmt.Errorf("NEWS_API_KEY is required")
 	}
+	if c.LLMMaxRetries < 0 {
+		return fmt.Errorf("LLM_MAX_RETRIES must not be negative, got %d", c.LLMMaxRetries)
+	}
+	if c.HTTPAddr != "" && c.APIToken == "" {
+		return fmt.Errorf("API_TOKEN is required with HTTP_ADDR")
+	}
+	if c.BudgetAction != "stop" && c.BudgetAction != "downgrade" {
+		return fmt.Errorf("BUDGET_ACTION must be \"stop\" or \"downgrade\", got %q", c.BudgetAction)
+	}
+	switch c.EmbeddingProvider {
+	case "gemini", "openai", "local":
+	default:
+		return fmt.Errorf("EMBEDDING_PROVIDER must be gemini, openai or local, got %q", c.EmbeddingProvider)
+	}
+	if c.QASearch != "keyword" && c.QASearch != "hybrid" {
+		return fmt.Errorf("QA_SEARCH must be \"keyword\" or \"hybrid\", got %q", c.QASearch)
+	}
 	return nil
 }
+
+// splitList parses a comma separated env value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// lowerList lower-cases every item of list in place
+func lowerList(list []string) []string {
+	for i, item := range list {
+		list[i] = strings.ToLower(item)
+	}
+	return list
+}
+
+// envInt reads a positive integer env value, falling back to def
+func envInt(key string, def int) int {
+	if parsed, err := strconv.Atoi(os.Getenv(key)); err == nil && parsed > 0 {
+		return parsed
+	}
+	return def
+}
+
+// parsePrices merges LLM_PRICES entries of the form
+// "model=input:output,..." (USD per 1M tokens) over the default table
+func parsePrices(value string) (map[string]ModelPrice, error) {
+	prices := make(map[string]ModelPrice, len(defaultPrices))
+	for model, price := range defaultPrices {
+		prices[model] = price
+	}
+
+	for _, entry := range splitList(value) {
+		model, rates, ok := strings.Cut(entry, "=")
+		input, output, ok2 := strings.Cut(rates, ":")
+		if !ok || !ok2 {
+			return nil, fmt.Errorf("invalid LLM_PRICES entry %q, expected model=input:output", entry)
+		}
+		in, err := strconv.ParseFloat(strings.TrimSpace(input), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid input price in LLM_PRICES entry %q", entry)
+		}
+		out, err := strconv.ParseFloat(strings.TrimSpace(output), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid output price in LLM_PRICES entry %q", entry)
+		}
+		prices[strings.TrimSpace(model)] = ModelPrice{InputPerMillion: in, OutputPerMillion: out}
+	}
+
+	return prices, nil
+}
+
+// parseTelegramChats parses TELEGRAM_CHATS entries of the form
+// "id:lang[:budget]". When the list is empty the single TELEGRAM_CHAT_ID
+// is used with the default digest language.
+func parseTelegramChats(value string, defaultChat int64, defaultLanguage string, defaultMaxChars int) ([]TelegramChat, error) {
+	language := strings.ToLower(strings.TrimSpace(defaultLanguage))
+	if language == "" {
+		language = "en"
+	}
+	if !i18n.Supported(language) {
+		return nil, fmt.Errorf("unsupported DIGEST_LANGUAGE %q (valid: %s)", language, strings.Join(i18n.Languages(), ", "))
+	}
+
+	entries := splitList(value)
+	if len(entries) == 0 {
+		if defaultChat == 0 {
+			return nil, nil
+		}
+		return []TelegramChat{{ID: defaultChat, Language: language, MaxChars: defaultMaxChars}}, nil
+	}
+
+	chats := make([]TelegramChat, 0, len(entries))
+	for _, entry := range entries {
+		rawID, rest, _ := strings.Cut(entry, ":")
+		lang, budget, _ := strings.Cut(rest, ":")
+		id, err := strconv.ParseInt(strings.TrimSpace(rawID), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chat ID in TELEGRAM_CHATS entry %q", entry)
+		}
+		lang = strings.ToLower(strings.TrimSpace(lang))
+		if lang == "" {
+			lang = language
+		}
+		if !i18n.Supported(lang) {
+			return nil, fmt.Errorf("unsupported language %q in TELEGRAM_CHATS entry %q (valid: %s)", lang, entry, strings.Join(i18n.Languages(), ", "))
+		}
+		maxChars := defaultMaxChars
+		if budget != "" {
+			if maxChars, err = parseLengthBudget(budget); err != nil {
+				return nil, fmt.Errorf("invalid budget in TELEGRAM_CHATS entry %q: %w", entry, err)
+			}
+		}
+		chats = append(chats, TelegramChat{ID: id, Language: lang, MaxChars: maxChars})
+	}
+	return chats, nil
+}
+
+// parseLengthBudget parses a message budget in characters ("3000") or
+// tokens ("800t"), returning characters
+func parseLengthBudget(value string) (int, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	multiplier := 1
+	if tokens, ok := strings.CutSuffix(value, "t"); ok {
+		value, multiplier = tokens, charsPerToken
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("expected a positive number of characters or tokens (e.g. 800t), got %q", value)
+	}
+	return n * multiplier, nil
+}
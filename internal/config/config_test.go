@@ -0,0 +1,45 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadLLMMaxRetries(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{"", 3, false},
+		// 0 disables retries rather than restoring the default
+		{"0", 0, false},
+		{" 5 ", 5, false},
+		{"-1", 0, true},
+		{"three", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv("GEMINI_API_KEY", "key")
+			t.Setenv("NEWS_API_KEY", "key")
+			t.Setenv("TELEGRAM_BOT_TOKEN", "token")
+			t.Setenv("TELEGRAM_CHAT_ID", "42")
+			t.Setenv("DATA_DIR", t.TempDir())
+			t.Setenv("LLM_MAX_RETRIES", tt.value)
+			cfg, err := Load()
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "invalid LLM_MAX_RETRIES") {
+					t.Errorf("Load error = %v, want an invalid LLM_MAX_RETRIES", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if cfg.LLMMaxRetries != tt.want {
+				t.Errorf("LLMMaxRetries = %d, want %d", cfg.LLMMaxRetries, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"tech-news-agent/internal/i18n"
+)
+
+// Destination kinds other than Telegram
+const (
+	DestinationSlack      = "slack"
+	DestinationDiscord    = "discord"
+	DestinationEmail      = "email"
+	DestinationTeams      = "teams"
+	DestinationMattermost = "mattermost"
+	DestinationWebhook    = "webhook"
+	DestinationMatrix     = "matrix"
+)
+
+// destinationKinds lists the supported destination kinds
+var destinationKinds = []string{DestinationSlack, DestinationDiscord, DestinationEmail, DestinationTeams, DestinationMattermost, DestinationWebhook, DestinationMatrix}
+
+// Destination is a digest recipient on a channel other than Telegram.
+// Credentials come from the channel's env settings; Target picks the
+// recipient, e.g. a Slack channel ID or an incoming webhook URL.
+type Destination struct {
+	Kind     string `json:"kind"`
+	Target   string `json:"target"`
+	Language string `json:"language"`
+	// MaxChars is the length budget of a digest (0 is unlimited)
+	MaxChars int `json:"max_chars"`
+}
+
+// loadDestinations reads the channel destinations from the environment
+func loadDestinations(defaultLanguage string) ([]Destination, error) {
+	destinations := urlTargets(DestinationSlack, os.Getenv("SLACK_WEBHOOK_URLS"), defaultLanguage)
+
+	slack, err := parseChannelTargets(DestinationSlack, "SLACK_CHANNELS", os.Getenv("SLACK_CHANNELS"), defaultLanguage)
+	if err != nil {
+		return nil, err
+	}
+	destinations = append(destinations, slack...)
+
+	destinations = append(destinations, urlTargets(DestinationDiscord, os.Getenv("DISCORD_WEBHOOK_URLS"), defaultLanguage)...)
+
+	email, err := parseChannelTargets(DestinationEmail, "EMAIL_RECIPIENTS", os.Getenv("EMAIL_RECIPIENTS"), defaultLanguage)
+	if err != nil {
+		return nil, err
+	}
+	destinations = append(destinations, email...)
+
+	destinations = append(destinations, urlTargets(DestinationTeams, os.Getenv("TEAMS_WEBHOOK_URLS"), defaultLanguage)...)
+	destinations = append(destinations, urlTargets(DestinationMattermost, os.Getenv("MATTERMOST_WEBHOOK_URLS"), defaultLanguage)...)
+	destinations = append(destinations, urlTargets(DestinationWebhook, os.Getenv("WEBHOOK_URLS"), defaultLanguage)...)
+
+	matrix, err := parseChannelTargets(DestinationMatrix, "MATRIX_ROOMS", os.Getenv("MATRIX_ROOMS"), defaultLanguage)
+	if err != nil {
+		return nil, err
+	}
+	destinations = append(destinations, matrix...)
+
+	return destinations, nil
+}
+
+// parseChannelTargets parses entries of the form "target[:lang[:budget]]"
+// as used by SLACK_CHANNELS, EMAIL_RECIPIENTS and MATRIX_ROOMS
+func parseChannelTargets(kind, env, value, defaultLanguage string) ([]Destination, error) {
+	var destinations []Destination
+	for _, entry := range splitList(value) {
+		target, rest := cutTarget(kind, entry)
+		lang, budget, _ := strings.Cut(rest, ":")
+		dest := Destination{
+			Kind:     kind,
+			Target:   strings.TrimSpace(target),
+			Language: strings.ToLower(strings.TrimSpace(lang)),
+		}
+		if dest.Target == "" {
+			return nil, fmt.Errorf("missing target in %s entry %q", env, entry)
+		}
+		if dest.Language == "" {
+			dest.Language = defaultLanguage
+		}
+		if budget != "" {
+			maxChars, err := parseLengthBudget(budget)
+			if err != nil {
+				return nil, fmt.Errorf("invalid budget in %s entry %q: %w", env, entry, err)
+			}
+			dest.MaxChars = maxChars
+		}
+		destinations = append(destinations, dest)
+	}
+	return destinations, nil
+}
+
+// cutTarget splits the target off a channel entry. Matrix room IDs contain
+// the server name and maybe its port, e.g. "!abc:example.org:8448:en".
+func cutTarget(kind, entry string) (target, rest string) {
+	if kind != DestinationMatrix {
+		target, rest, _ = strings.Cut(entry, ":")
+		return target, rest
+	}
+
+	parts := strings.Split(entry, ":")
+	n := min(2, len(parts))
+	if len(parts) > 2 && isDigits(parts[2]) {
+		n = 3
+	}
+	return strings.Join(parts[:n], ":"), strings.Join(parts[n:], ":")
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// urlTargets turns a comma separated list of webhook URLs into
+// destinations in the default language
+func urlTargets(kind, value, defaultLanguage string) []Destination {
+	var destinations []Destination
+	for _, url := range splitList(value) {
+		destinations = append(destinations, Destination{Kind: kind, Target: url, Language: defaultLanguage})
+	}
+	return destinations
+}
+
+// IsWebhook reports whether the destination posts to a webhook URL rather
+// than addressing a channel through an API
+func (d Destination) IsWebhook() bool {
+	return strings.HasPrefix(d.Target, "https://") || strings.HasPrefix(d.Target, "http://")
+}
+
+// validateDestinations checks that every destination has a known kind, a
+// supported language and the credentials its channel needs
+func (c *Config) validateDestinations(destinations []Destination) error {
+	for _, d := range destinations {
+		switch d.Kind {
+		case DestinationSlack:
+			if !d.IsWebhook() && c.SlackBotToken == "" {
+				return fmt.Errorf("SLACK_BOT_TOKEN is required to post to Slack channel %q", d.Target)
+			}
+		case DestinationDiscord, DestinationTeams, DestinationMattermost, DestinationWebhook:
+			if !d.IsWebhook() {
+				return fmt.Errorf("%s destinations need a webhook URL as target", d.Kind)
+			}
+		case DestinationMatrix:
+			if c.MatrixHomeserverURL == "" || c.MatrixAccessToken == "" {
+				return fmt.Errorf("MATRIX_HOMESERVER_URL and MATRIX_ACCESS_TOKEN are required to post to Matrix room %q", d.Target)
+			}
+			if !strings.HasPrefix(d.Target, "!") || !strings.Contains(d.Target, ":") {
+				return fmt.Errorf("invalid Matrix room ID %q, expected e.g. !abc123:example.org", d.Target)
+			}
+		case DestinationEmail:
+			if c.SMTPHost == "" || c.SMTPFrom == "" {
+				return fmt.Errorf("SMTP_HOST and SMTP_FROM are required to email %s", d.Target)
+			}
+			if !strings.Contains(d.Target, "@") {
+				return fmt.Errorf("invalid email recipient %q", d.Target)
+			}
+			switch c.SMTPTLS {
+			case "starttls", "implicit", "none":
+			default:
+				return fmt.Errorf("SMTP_TLS must be starttls, implicit or none, got %q", c.SMTPTLS)
+			}
+		default:
+			return fmt.Errorf("unknown destination kind %q (valid: %s)", d.Kind, strings.Join(destinationKinds, ", "))
+		}
+		if d.Target == "" {
+			return fmt.Errorf("%s destination without a target", d.Kind)
+		}
+		if d.MaxChars < 0 {
+			return fmt.Errorf("%s destination %q: max_chars must not be negative", d.Kind, d.Target)
+		}
+		if !i18n.Supported(d.Language) {
+			return fmt.Errorf("%s destination %q: unsupported language %q (valid: %s)", d.Kind, d.Target, d.Language, strings.Join(i18n.Languages(), ", "))
+		}
+	}
+	return nil
+}
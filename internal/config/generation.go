@@ -0,0 +1,199 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// GenerationParams holds the sampling parameters sent with an LLM call
+type GenerationParams struct {
+	Temperature     float32
+	TopP            float32
+	TopK            int32
+	MaxOutputTokens int32
+	StopSequences   []string
+	// SafetySettings maps a harm category to a block threshold, e.g.
+	// "harassment" -> "block_only_high". Only Gemini applies them.
+	SafetySettings map[string]string
+}
+
+// ParamOverrides is a partial set of generation parameters layered on top
+// of a base set. Nil fields leave the base value untouched.
+type ParamOverrides struct {
+	Temperature     *float32
+	TopP            *float32
+	TopK            *int32
+	MaxOutputTokens *int32
+	StopSequences   []string
+}
+
+// DefaultGenerationParams returns the parameters used for the weekly digest
+func DefaultGenerationParams() GenerationParams {
+	return GenerationParams{
+		Temperature:     0.7,
+		TopP:            0.9,
+		TopK:            40,
+		MaxOutputTokens: 2048,
+	}
+}
+
+// DefaultEnrichmentParams returns the parameters used for the per-article
+// pass, which should be short and deterministic
+func DefaultEnrichmentParams() GenerationParams {
+	params := DefaultGenerationParams()
+	params.Temperature = 0.2
+	params.MaxOutputTokens = 1024
+	return params
+}
+
+// With returns a copy of p with the overrides applied
+func (p GenerationParams) With(o ParamOverrides) GenerationParams {
+	if o.Temperature != nil {
+		p.Temperature = *o.Temperature
+	}
+	if o.TopP != nil {
+		p.TopP = *o.TopP
+	}
+	if o.TopK != nil {
+		p.TopK = *o.TopK
+	}
+	if o.MaxOutputTokens != nil {
+		p.MaxOutputTokens = *o.MaxOutputTokens
+	}
+	if o.StopSequences != nil {
+		p.StopSequences = o.StopSequences
+	}
+	return p
+}
+
+// IsZero reports whether no override is set
+func (o ParamOverrides) IsZero() bool {
+	return o.Temperature == nil && o.TopP == nil && o.TopK == nil &&
+		o.MaxOutputTokens == nil && o.StopSequences == nil
+}
+
+// String formats the parameters for logging
+func (p GenerationParams) String() string {
+	s := fmt.Sprintf("temperature=%.2f top_p=%.2f top_k=%d max_tokens=%d",
+		p.Temperature, p.TopP, p.TopK, p.MaxOutputTokens)
+	if len(p.StopSequences) > 0 {
+		s += fmt.Sprintf(" stop=%q", p.StopSequences)
+	}
+	if len(p.SafetySettings) > 0 {
+		s += fmt.Sprintf(" safety=%v", p.SafetySettings)
+	}
+	return s
+}
+
+// ParseParamOverrides parses a compact override list such as
+// "temperature=0.3,top_k=20,max_tokens=1024,stop=END|###"
+func ParseParamOverrides(value string) (ParamOverrides, error) {
+	var o ParamOverrides
+
+	for _, entry := range splitList(value) {
+		key, raw, ok := strings.Cut(entry, "=")
+		if !ok {
+			return o, fmt.Errorf("invalid parameter %q, expected key=value", entry)
+		}
+		key, raw = strings.TrimSpace(key), strings.TrimSpace(raw)
+
+		switch key {
+		case "temperature":
+			v, err := strconv.ParseFloat(raw, 32)
+			if err != nil {
+				return o, fmt.Errorf("invalid temperature %q", raw)
+			}
+			f := float32(v)
+			o.Temperature = &f
+		case "top_p":
+			v, err := strconv.ParseFloat(raw, 32)
+			if err != nil {
+				return o, fmt.Errorf("invalid top_p %q", raw)
+			}
+			f := float32(v)
+			o.TopP = &f
+		case "top_k":
+			v, err := strconv.ParseInt(raw, 10, 32)
+			if err != nil {
+				return o, fmt.Errorf("invalid top_k %q", raw)
+			}
+			i := int32(v)
+			o.TopK = &i
+		case "max_tokens":
+			v, err := strconv.ParseInt(raw, 10, 32)
+			if err != nil {
+				return o, fmt.Errorf("invalid max_tokens %q", raw)
+			}
+			i := int32(v)
+			o.MaxOutputTokens = &i
+		case "stop":
+			o.StopSequences = []string{}
+			for _, seq := range strings.Split(raw, "|") {
+				if seq != "" {
+					o.StopSequences = append(o.StopSequences, seq)
+				}
+			}
+		default:
+			return o, fmt.Errorf("unknown generation parameter %q", key)
+		}
+	}
+
+	return o, nil
+}
+
+// parseSafetySettings parses "category=threshold,..." pairs
+func parseSafetySettings(value string) (map[string]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	settings := make(map[string]string)
+	for _, entry := range splitList(value) {
+		category, threshold, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid safety setting %q, expected category=threshold", entry)
+		}
+		settings[strings.ToLower(strings.TrimSpace(category))] = strings.ToLower(strings.TrimSpace(threshold))
+	}
+	return settings, nil
+}
+
+// loadGenerationParams builds the per-digest parameters and per-provider
+// overrides from LLM_PARAMS, LLM_PARAMS_<DIGEST> and LLM_PARAMS_<PROVIDER>
+func loadGenerationParams(cfg *Config) error {
+	base, err := ParseParamOverrides(os.Getenv("LLM_PARAMS"))
+	if err != nil {
+		return fmt.Errorf("LLM_PARAMS: %w", err)
+	}
+	summary, err := ParseParamOverrides(os.Getenv("LLM_PARAMS_SUMMARY"))
+	if err != nil {
+		return fmt.Errorf("LLM_PARAMS_SUMMARY: %w", err)
+	}
+	enrichment, err := ParseParamOverrides(os.Getenv("LLM_PARAMS_ENRICHMENT"))
+	if err != nil {
+		return fmt.Errorf("LLM_PARAMS_ENRICHMENT: %w", err)
+	}
+	safety, err := parseSafetySettings(os.Getenv("GEMINI_SAFETY_SETTINGS"))
+	if err != nil {
+		return fmt.Errorf("GEMINI_SAFETY_SETTINGS: %w", err)
+	}
+
+	cfg.SummaryParams = DefaultGenerationParams().With(base).With(summary)
+	cfg.SummaryParams.SafetySettings = safety
+	cfg.EnrichmentParams = DefaultEnrichmentParams().With(base).With(enrichment)
+	cfg.EnrichmentParams.SafetySettings = safety
+
+	cfg.ProviderParams = make(map[string]ParamOverrides)
+	for _, provider := range []string{"gemini", "ollama"} {
+		key := "LLM_PARAMS_" + strings.ToUpper(provider)
+		overrides, err := ParseParamOverrides(os.Getenv(key))
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		cfg.ProviderParams[provider] = overrides
+	}
+
+	return nil
+}
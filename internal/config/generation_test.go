@@ -0,0 +1,127 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestParseParamOverrides(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    ParamOverrides
+		wantErr string
+	}{
+		{value: "", want: ParamOverrides{}},
+		{
+			value: " temperature = 0.3 , top_p=0.8,top_k=20,max_tokens=1024",
+			want:  ParamOverrides{Temperature: ptr(float32(0.3)), TopP: ptr(float32(0.8)), TopK: ptr(int32(20)), MaxOutputTokens: ptr(int32(1024))},
+		},
+		{value: "stop=END|###", want: ParamOverrides{StopSequences: []string{"END", "###"}}},
+		// An empty list clears the stop sequences of the base parameters
+		{value: "stop=", want: ParamOverrides{StopSequences: []string{}}},
+		{value: "temperature", wantErr: "expected key=value"},
+		{value: "temperature=warm", wantErr: `invalid temperature "warm"`},
+		{value: "top_p=high", wantErr: `invalid top_p "high"`},
+		{value: "top_k=2.5", wantErr: `invalid top_k "2.5"`},
+		{value: "max_tokens=99999999999", wantErr: "invalid max_tokens"},
+		{value: "seed=1", wantErr: `unknown generation parameter "seed"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseParamOverrides(tt.value)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseParamOverrides error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseParamOverrides: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseParamOverrides = %+v, want %+v", got, tt.want)
+			}
+			if got.IsZero() != (tt.value == "") {
+				t.Errorf("IsZero = %v for %q", got.IsZero(), tt.value)
+			}
+		})
+	}
+}
+
+func TestGenerationParamsWith(t *testing.T) {
+	base := GenerationParams{Temperature: 0.7, TopP: 0.9, TopK: 40, MaxOutputTokens: 2048, StopSequences: []string{"END"}}
+
+	if got := base.With(ParamOverrides{}); !reflect.DeepEqual(got, base) {
+		t.Errorf("With no overrides = %+v, want %+v", got, base)
+	}
+
+	got := base.With(ParamOverrides{TopK: ptr(int32(10)), StopSequences: []string{}})
+	want := GenerationParams{Temperature: 0.7, TopP: 0.9, TopK: 10, MaxOutputTokens: 2048, StopSequences: []string{}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("With = %+v, want %+v", got, want)
+	}
+	if base.TopK != 40 || len(base.StopSequences) != 1 {
+		t.Errorf("With modified the base: %+v", base)
+	}
+}
+
+func TestLoadGenerationParamsPrecedence(t *testing.T) {
+	t.Setenv("LLM_PARAMS", "temperature=0.5,top_k=30")
+	t.Setenv("LLM_PARAMS_SUMMARY", "temperature=0.9")
+	t.Setenv("LLM_PARAMS_ENRICHMENT", "max_tokens=256")
+	t.Setenv("LLM_PARAMS_GEMINI", "top_k=64")
+	t.Setenv("LLM_PARAMS_OLLAMA", "")
+	t.Setenv("GEMINI_SAFETY_SETTINGS", "Harassment=BLOCK_ONLY_HIGH")
+
+	var cfg Config
+	if err := loadGenerationParams(&cfg); err != nil {
+		t.Fatalf("loadGenerationParams: %v", err)
+	}
+
+	safety := map[string]string{"harassment": "block_only_high"}
+	// Defaults, then LLM_PARAMS, then the digest's own settings
+	wantSummary := GenerationParams{Temperature: 0.9, TopP: 0.9, TopK: 30, MaxOutputTokens: 2048, SafetySettings: safety}
+	wantEnrichment := GenerationParams{Temperature: 0.5, TopP: 0.9, TopK: 30, MaxOutputTokens: 256, SafetySettings: safety}
+	if !reflect.DeepEqual(cfg.SummaryParams, wantSummary) {
+		t.Errorf("summary params = %+v, want %+v", cfg.SummaryParams, wantSummary)
+	}
+	if !reflect.DeepEqual(cfg.EnrichmentParams, wantEnrichment) {
+		t.Errorf("enrichment params = %+v, want %+v", cfg.EnrichmentParams, wantEnrichment)
+	}
+
+	// Provider overrides apply last, on top of either digest
+	if got := cfg.SummaryParams.With(cfg.ProviderParams["gemini"]); got.TopK != 64 || got.Temperature != 0.9 {
+		t.Errorf("gemini summary params = %+v, want top_k 64 and temperature 0.9", got)
+	}
+	if !cfg.ProviderParams["ollama"].IsZero() {
+		t.Errorf("ollama overrides = %+v, want none", cfg.ProviderParams["ollama"])
+	}
+}
+
+func TestLoadGenerationParamsNamesTheVariable(t *testing.T) {
+	tests := []struct {
+		env, value string
+	}{
+		{"LLM_PARAMS", "temperature=hot"},
+		{"LLM_PARAMS_SUMMARY", "top_p"},
+		{"LLM_PARAMS_ENRICHMENT", "seed=1"},
+		{"LLM_PARAMS_OLLAMA", "top_k=x"},
+		{"GEMINI_SAFETY_SETTINGS", "harassment"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			t.Setenv(tt.env, tt.value)
+			err := loadGenerationParams(&Config{})
+			if err == nil || !strings.HasPrefix(err.Error(), tt.env+": ") {
+				t.Errorf("loadGenerationParams error = %v, want one naming %s", err, tt.env)
+			}
+		})
+	}
+}
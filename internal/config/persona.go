@@ -0,0 +1,149 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"slices"
+	"strings"
+	"tech-news-agent/internal/models"
+)
+
+// DefaultPersona is the name of the persona built from TELEGRAM_CHATS and
+// the destinations configured in the environment
+const DefaultPersona = "default"
+
+// Persona is a named audience with its own digest shape and recipients.
+// All personas share one collection and enrichment run.
+type Persona struct {
+	Name string
+	// Prompt replaces the analyst instructions at the top of the summary
+	// prompt, e.g. "Focus on business impact for executives"
+	Prompt string
+	// Sections limits the rendered digest sections (empty renders all)
+	Sections []string
+	// MaxWords caps the length of the executive summary (0 is unlimited)
+	MaxWords int
+	// Categories keeps only articles of these categories (empty keeps all)
+	Categories   []string
+	Chats        []TelegramChat
+	Destinations []Destination
+}
+
+// personaFile is the JSON form of a persona in PERSONAS_FILE
+type personaFile struct {
+	Name       string   `json:"name"`
+	Prompt     string   `json:"prompt"`
+	Sections   []string `json:"sections"`
+	MaxWords   int      `json:"max_words"`
+	Categories []string `json:"categories"`
+	// Chats uses the TELEGRAM_CHATS format, e.g. ["-100123:en:3000"]
+	Chats        []string      `json:"chats"`
+	Destinations []Destination `json:"destinations"`
+}
+
+// loadPersonas reads PERSONAS_FILE. Chats listed in TELEGRAM_CHATS and the
+// destinations from the environment always receive the default persona.
+func loadPersonas(path string, defaultChats []TelegramChat, defaultDestinations []Destination, defaultLanguage string, defaultMaxChars int) ([]Persona, error) {
+	var personas []Persona
+	if len(defaultChats) > 0 || len(defaultDestinations) > 0 {
+		personas = append(personas, Persona{Name: DefaultPersona, Chats: defaultChats, Destinations: defaultDestinations})
+	}
+	if path == "" {
+		return personas, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading PERSONAS_FILE: %w", err)
+	}
+
+	var entries []personaFile
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("decoding PERSONAS_FILE: %w", err)
+	}
+
+	for _, entry := range entries {
+		chats, err := parseTelegramChats(strings.Join(entry.Chats, ","), 0, defaultLanguage, defaultMaxChars)
+		if err != nil {
+			return nil, fmt.Errorf("persona %q: %w", entry.Name, err)
+		}
+		for i, dest := range entry.Destinations {
+			entry.Destinations[i].Kind = strings.ToLower(strings.TrimSpace(dest.Kind))
+			entry.Destinations[i].Language = strings.ToLower(strings.TrimSpace(dest.Language))
+			if entry.Destinations[i].Language == "" {
+				entry.Destinations[i].Language = defaultLanguage
+			}
+		}
+		personas = append(personas, Persona{
+			Name:         strings.TrimSpace(entry.Name),
+			Prompt:       strings.TrimSpace(entry.Prompt),
+			Sections:     lowerList(entry.Sections),
+			MaxWords:     entry.MaxWords,
+			Categories:   lowerList(entry.Categories),
+			Chats:        chats,
+			Destinations: entry.Destinations,
+		})
+	}
+
+	return personas, nil
+}
+
+// validatePersonas checks names, sections and recipients of all personas
+func validatePersonas(personas []Persona) error {
+	seen := make(map[string]string)
+	for _, p := range personas {
+		if p.Name == "" {
+			return fmt.Errorf("every persona needs a name")
+		}
+		// Digest pages are named by slug, so two names must not share one
+		slug := Slug(p.Name)
+		if other, ok := seen[slug]; ok {
+			if other == p.Name {
+				return fmt.Errorf("duplicate persona %q", p.Name)
+			}
+			return fmt.Errorf("personas %q and %q are too similar, both become %q", other, p.Name, slug)
+		}
+		seen[slug] = p.Name
+
+		if len(p.Chats)+len(p.Destinations) == 0 {
+			return fmt.Errorf("persona %q has no chats or destinations", p.Name)
+		}
+		if p.MaxWords < 0 {
+			return fmt.Errorf("persona %q: max_words must not be negative", p.Name)
+		}
+		for _, section := range p.Sections {
+			if !slices.Contains(models.AllSections, section) {
+				return fmt.Errorf("persona %q: unknown section %q (valid: %s)",
+					p.Name, section, strings.Join(models.AllSections, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// Slug returns name reduced to lowercase letters, digits and dashes for
+// use in file names and URLs. A name without any of those is replaced by
+// its hash.
+func Slug(name string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			sb.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	if sb.Len() == 0 {
+		h := fnv.New32a()
+		h.Write([]byte(name))
+		return fmt.Sprintf("%08x", h.Sum32())
+	}
+	return sb.String()
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPersonaDestinationLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		wantErr  string
+	}{
+		{"default", "", ""},
+		{"supported", " TR ", ""},
+		{"unsupported", "xx", `unsupported language "xx"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "personas.json")
+			data := `[{"name": "execs", "destinations": [{"kind": "discord", "target": "https://discord.test/hook", "language": "` + tt.language + `"}]}]`
+			if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			personas, err := loadPersonas(path, nil, nil, "en", 4000)
+			if err != nil {
+				t.Fatalf("loadPersonas: %v", err)
+			}
+			cfg := &Config{Personas: personas}
+			err = cfg.validateDestinations(personas[0].Destinations)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateDestinations: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateDestinations error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
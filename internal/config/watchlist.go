@@ -0,0 +1,49 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// WatchEntity is a company, product or person the reader always wants to
+// hear about, whether or not its stories make the top list
+type WatchEntity struct {
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases"`
+	// CaseSensitive avoids hits on common words, e.g. "Go" or "Rust"
+	CaseSensitive bool `json:"case_sensitive"`
+}
+
+// loadWatchlist reads WATCHLIST_FILE, a JSON list of watch entities
+func loadWatchlist(path string) ([]WatchEntity, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading WATCHLIST_FILE: %w", err)
+	}
+
+	var entities []WatchEntity
+	if err := json.Unmarshal(data, &entities); err != nil {
+		return nil, fmt.Errorf("decoding WATCHLIST_FILE: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	for i, entity := range entities {
+		name := strings.TrimSpace(entity.Name)
+		if name == "" {
+			return nil, fmt.Errorf("every entity in WATCHLIST_FILE needs a name")
+		}
+		if seen[strings.ToLower(name)] {
+			return nil, fmt.Errorf("duplicate watchlist entity %q", name)
+		}
+		seen[strings.ToLower(name)] = true
+		entities[i].Name = name
+	}
+
+	return entities, nil
+}
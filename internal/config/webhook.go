@@ -0,0 +1,37 @@
+package config
+
+import (
+	"fmt"
+	"net/textproto"
+	"os"
+	"strings"
+)
+
+// parseWebhookHeaders parses WEBHOOK_HEADERS entries of the form
+// "Name: value,..."
+func parseWebhookHeaders(value string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, entry := range splitList(value) {
+		name, headerValue, ok := strings.Cut(entry, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("invalid WEBHOOK_HEADERS entry %q, expected \"Name: value\"", entry)
+		}
+		headers[textproto.CanonicalMIMEHeaderKey(name)] = strings.TrimSpace(headerValue)
+	}
+	return headers, nil
+}
+
+// loadWebhookTemplate reads WEBHOOK_TEMPLATE_FILE, a text/template that
+// renders the JSON body of webhook posts
+func loadWebhookTemplate(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("reading WEBHOOK_TEMPLATE_FILE: %w", err)
+	}
+	return string(data), nil
+}
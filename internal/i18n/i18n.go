@@ -0,0 +1,182 @@
+// Package i18n holds the localized strings used when rendering digests
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultLanguage is used when a recipient has no language configured
+const DefaultLanguage = "en"
+
+// Message keys
+const (
+	DigestTitle      = "digest_title"
+	ArticlesAnalyzed = "articles_analyzed"
+	TopStories       = "top_stories"
+	KeyTopics        = "key_topics"
+	TrendingStories  = "trending_stories"
+	GeneratedOn      = "generated_on"
+	PoweredBy        = "powered_by"
+	ErrorTitle       = "error_title"
+	Connected        = "connected"
+	TrendsTitle      = "trends_title"
+	TrendEmerging    = "trend_emerging"
+	TrendFading      = "trend_fading"
+	FullVersion      = "full_version"
+	MarketPulse      = "market_pulse"
+	Sentiment        = "sentiment"
+	Positive         = "positive"
+	Neutral          = "neutral"
+	Negative         = "negative"
+	Watchlist        = "watchlist"
+	WatchlistEmpty   = "watchlist_empty"
+	Unsubscribe      = "unsubscribe"
+)
+
+var messages = map[string]map[string]string{
+	"en": {
+		DigestTitle:      "Weekly Tech News Summary",
+		ArticlesAnalyzed: "Articles analyzed: %d",
+		TopStories:       "Top %d Stories",
+		KeyTopics:        "Key Topics",
+		TrendingStories:  "Trending Stories",
+		GeneratedOn:      "Generated on %s",
+		PoweredBy:        "Powered by Gemini AI & Go",
+		ErrorTitle:       "Tech News Agent Error",
+		Connected:        "Tech News Agent is connected and ready!",
+		TrendsTitle:      "Trends vs Last Period",
+		TrendEmerging:    "New: %s (%d)",
+		TrendFading:      "Fading: %s (was %d)",
+		FullVersion:      "Read the full digest",
+		MarketPulse:      "Market Pulse",
+		Sentiment:        "Overall: %s",
+		Positive:         "positive",
+		Neutral:          "neutral",
+		Negative:         "negative",
+		Watchlist:        "Your Watchlist",
+		WatchlistEmpty:   "No mentions this period",
+		Unsubscribe:      "Unsubscribe",
+	},
+	"tr": {
+		DigestTitle:      "Haftalık Teknoloji Haberleri Özeti",
+		ArticlesAnalyzed: "İncelenen haber sayısı: %d",
+		TopStories:       "Öne Çıkan %d Haber",
+		KeyTopics:        "Ana Konular",
+		TrendingStories:  "Gündemdeki Haberler",
+		GeneratedOn:      "Oluşturulma: %s",
+		PoweredBy:        "Gemini AI & Go ile hazırlandı",
+		ErrorTitle:       "Tech News Agent Hatası",
+		Connected:        "Tech News Agent bağlandı ve hazır!",
+		TrendsTitle:      "Önceki Döneme Göre Eğilimler",
+		TrendEmerging:    "Yeni: %s (%d)",
+		TrendFading:      "Azalan: %s (önceki: %d)",
+		FullVersion:      "Özetin tamamını okuyun",
+		MarketPulse:      "Piyasa Nabzı",
+		Sentiment:        "Genel görünüm: %s",
+		Positive:         "olumlu",
+		Neutral:          "nötr",
+		Negative:         "olumsuz",
+		Watchlist:        "İzleme Listeniz",
+		WatchlistEmpty:   "Bu dönemde hiç anılmadı",
+		Unsubscribe:      "Abonelikten çık",
+	},
+}
+
+// languageNames names the languages of digests and source articles
+var languageNames = map[string]string{
+	"en": "English",
+	"tr": "Turkish",
+	"de": "German",
+	"fr": "French",
+	"es": "Spanish",
+	"it": "Italian",
+	"pt": "Portuguese",
+	"nl": "Dutch",
+	"ru": "Russian",
+	"ar": "Arabic",
+	"zh": "Chinese",
+	"ja": "Japanese",
+	"ko": "Korean",
+}
+
+var monthNames = map[string][12]string{
+	"tr": {"Oca", "Şub", "Mar", "Nis", "May", "Haz", "Tem", "Ağu", "Eyl", "Eki", "Kas", "Ara"},
+}
+
+// Normalize lower-cases a language code and falls back to the default
+func Normalize(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if lang == "" {
+		return DefaultLanguage
+	}
+	return lang
+}
+
+// Supported reports whether digests can be rendered in lang, which needs a
+// message table. Other known languages are only named in prompts.
+func Supported(lang string) bool {
+	_, ok := messages[Normalize(lang)]
+	return ok
+}
+
+// Languages returns the supported digest languages, sorted
+func Languages() []string {
+	languages := make([]string, 0, len(messages))
+	for lang := range messages {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Name returns the English name of a language, used in LLM prompts
+func Name(lang string) string {
+	if name, ok := languageNames[Normalize(lang)]; ok {
+		return name
+	}
+	return lang
+}
+
+// T returns the localized message for key, formatted with args.
+// Languages without translations fall back to English.
+func T(lang, key string, args ...any) string {
+	msg, ok := messages[Normalize(lang)][key]
+	if !ok {
+		msg = messages[DefaultLanguage][key]
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// FormatDate formats t as "Jan 02" or, with year, "Jan 02, 2006" using
+// localized month names where available
+func FormatDate(lang string, t time.Time, withYear bool) string {
+	months, ok := monthNames[Normalize(lang)]
+	if !ok {
+		if withYear {
+			return t.Format("Jan 02, 2006")
+		}
+		return t.Format("Jan 02")
+	}
+
+	s := fmt.Sprintf("%02d %s", t.Day(), months[t.Month()-1])
+	if withYear {
+		s += fmt.Sprintf(" %d", t.Year())
+	}
+	return s
+}
+
+// FormatWeekRange formats the digest period, e.g. "Jan 01 - Jan 08, 2026"
+func FormatWeekRange(lang string, start, end time.Time) string {
+	return FormatDate(lang, start, false) + " - " + FormatDate(lang, end, true)
+}
+
+// FormatTimestamp formats the generation time shown in digest footers
+func FormatTimestamp(lang string, t time.Time) string {
+	return FormatDate(lang, t, true) + t.Format(" 15:04 MST")
+}
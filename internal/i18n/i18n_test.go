@@ -0,0 +1,96 @@
+package i18n
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMessageTablesAreComplete(t *testing.T) {
+	for lang, table := range messages {
+		if _, ok := languageNames[lang]; !ok {
+			t.Errorf("%s has messages but no language name", lang)
+		}
+		for key, english := range messages[DefaultLanguage] {
+			msg, ok := table[key]
+			if !ok {
+				t.Errorf("%s is missing %s", lang, key)
+				continue
+			}
+			// Translations take the same arguments
+			if got, want := strings.Count(msg, "%"), strings.Count(english, "%"); got != want {
+				t.Errorf("%s %s has %d verbs, English has %d", lang, key, got, want)
+			}
+		}
+		for key := range table {
+			if _, ok := messages[DefaultLanguage][key]; !ok {
+				t.Errorf("%s has unknown key %s", lang, key)
+			}
+		}
+	}
+}
+
+func TestSupported(t *testing.T) {
+	tests := []struct {
+		lang string
+		want bool
+	}{
+		{"en", true},
+		{" TR ", true},
+		{"", true},
+		// Known for prompting, but digests would fall back to English
+		{"de", false},
+		{"xx", false},
+	}
+
+	for _, tt := range tests {
+		if got := Supported(tt.lang); got != tt.want {
+			t.Errorf("Supported(%q) = %v, want %v", tt.lang, got, tt.want)
+		}
+	}
+	if got := Languages(); !reflect.DeepEqual(got, []string{"en", "tr"}) {
+		t.Errorf("Languages = %q", got)
+	}
+}
+
+func TestT(t *testing.T) {
+	tests := []struct {
+		lang, key string
+		args      []any
+		want      string
+	}{
+		{"en", TopStories, []any{5}, "Top 5 Stories"},
+		{"TR", TopStories, []any{5}, "Öne Çıkan 5 Haber"},
+		{"tr", KeyTopics, nil, "Ana Konular"},
+		{"de", KeyTopics, nil, "Key Topics"},
+		{"en", "missing", nil, ""},
+	}
+
+	for _, tt := range tests {
+		if got := T(tt.lang, tt.key, tt.args...); got != tt.want {
+			t.Errorf("T(%q, %q) = %q, want %q", tt.lang, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestFormatDates(t *testing.T) {
+	start := time.Date(2026, 8, 3, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 8, 10, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		lang, weekRange, timestamp string
+	}{
+		{"en", "Aug 03 - Aug 10, 2026", "Aug 10, 2026 09:30 UTC"},
+		{"tr", "03 Ağu - 10 Ağu 2026", "10 Ağu 2026 09:30 UTC"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatWeekRange(tt.lang, start, end); got != tt.weekRange {
+			t.Errorf("FormatWeekRange(%s) = %q, want %q", tt.lang, got, tt.weekRange)
+		}
+		if got := FormatTimestamp(tt.lang, end); got != tt.timestamp {
+			t.Errorf("FormatTimestamp(%s) = %q, want %q", tt.lang, got, tt.timestamp)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// Package langdetect provides a small offline language detector for news
+// titles and descriptions. It combines Unicode script detection with
+// stopword and diacritic scoring, which is reliable enough for short texts
+// in the languages NewsAPI serves.
+package langdetect
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Unknown is returned when no language could be determined
+const Unknown = ""
+
+var stopwords = map[string][]string{
+	"en": {"the", "and", "of", "to", "in", "is", "for", "on", "with", "that", "as", "by", "from", "at", "its", "new", "are", "has", "will", "how"},
+	"tr": {"ve", "bir", "bu", "için", "ile", "da", "de", "ne", "gibi", "daha", "olarak", "yeni", "çok", "en", "mi", "sonra", "oldu", "olan", "kadar", "ama"},
+	"de": {"der", "die", "und", "das", "ist", "nicht", "mit", "den", "ein", "eine", "für", "auf", "von", "im", "sich", "auch", "des", "dem", "wie", "neue"},
+	"fr": {"le", "la", "les", "et", "des", "est", "un", "une", "du", "pour", "dans", "que", "sur", "avec", "par", "au", "aux", "plus", "nouveau", "qui"},
+	"es": {"el", "la", "los", "las", "y", "de", "que", "en", "un", "una", "por", "con", "para", "del", "es", "se", "al", "como", "más", "nuevo"},
+	"it": {"il", "lo", "la", "gli", "le", "di", "che", "è", "per", "con", "una", "del", "della", "sono", "non", "nel", "alla", "anche", "più", "nuovo"},
+	"pt": {"o", "os", "as", "de", "que", "do", "da", "em", "um", "uma", "para", "com", "não", "dos", "das", "no", "na", "mais", "ao", "novo"},
+	"nl": {"de", "het", "een", "en", "van", "is", "op", "te", "dat", "met", "voor", "niet", "zijn", "aan", "ook", "bij", "naar", "nieuwe", "wordt", "om"},
+}
+
+// distinctive characters that strongly hint at a Latin-script language
+var distinctive = map[string]string{
+	"tr": "ğışİĞŞı",
+	"de": "ßäöü",
+	"fr": "çèêëàâîôùœ",
+	"es": "ñ¿¡áíóú",
+	"pt": "ãõâêçá",
+	"it": "àèìòù",
+}
+
+var stopwordIndex = buildIndex()
+
+func buildIndex() map[string][]string {
+	index := make(map[string][]string)
+	for lang, words := range stopwords {
+		for _, w := range words {
+			index[w] = append(index[w], lang)
+		}
+	}
+	return index
+}
+
+// Detect returns the ISO 639-1 code of the most likely language of text
+// and a confidence between 0 and 1. Unknown is returned for empty or
+// ambiguous input.
+func Detect(text string) (string, float64) {
+	if lang, ok := detectScript(text); ok {
+		return lang, 1
+	}
+
+	scores := make(map[string]float64)
+	var words int
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), isSeparator) {
+		words++
+		for _, lang := range stopwordIndex[word] {
+			scores[lang]++
+		}
+	}
+
+	for lang, chars := range distinctive {
+		for _, r := range text {
+			if strings.ContainsRune(chars, r) {
+				scores[lang] += 0.5
+			}
+		}
+	}
+
+	best, second := Unknown, 0.0
+	var bestScore float64
+	for lang, score := range scores {
+		if score > bestScore || (score == bestScore && lang < best) {
+			second = bestScore
+			best, bestScore = lang, score
+		} else if score > second {
+			second = score
+		}
+	}
+
+	if bestScore < 1 || words == 0 {
+		return Unknown, 0
+	}
+
+	confidence := (bestScore - second) / bestScore
+	return best, confidence
+}
+
+// detectScript identifies languages that use their own script
+func detectScript(text string) (string, bool) {
+	counts := make(map[string]int)
+	var letters int
+
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			counts["ja"]++
+		case unicode.Is(unicode.Hangul, r):
+			counts["ko"]++
+		case unicode.Is(unicode.Han, r):
+			counts["zh"]++
+		case unicode.Is(unicode.Cyrillic, r):
+			counts["ru"]++
+		case unicode.Is(unicode.Arabic, r):
+			counts["ar"]++
+		case unicode.Is(unicode.Greek, r):
+			counts["el"]++
+		}
+	}
+
+	if letters == 0 {
+		return Unknown, false
+	}
+
+	// Japanese text mixes Kana with Han characters
+	if counts["ja"] > 0 {
+		counts["ja"] += counts["zh"]
+		counts["zh"] = 0
+	}
+
+	for lang, n := range counts {
+		if n*2 > letters {
+			return lang, true
+		}
+	}
+	return Unknown, false
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && r != '\''
+}
@@ -0,0 +1,49 @@
+package langdetect
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"english", "Apple unveils the new iPhone with a faster chip", "en"},
+		{"turkish", "Yeni yapay zeka modeli için büyük yatırım", "tr"},
+		{"german", "Die neue Grafikkarte ist schneller als gedacht", "de"},
+		{"french", "Le nouveau processeur est plus rapide que prévu", "fr"},
+		{"spanish", "El nuevo modelo de lenguaje es más rápido", "es"},
+		{"italian", "Il nuovo telefono è più veloce della concorrenza", "it"},
+		{"portuguese", "O novo chip da empresa não chega ao Brasil", "pt"},
+		{"dutch", "De nieuwe chip is sneller dan het vorige model", "nl"},
+		{"japanese", "新しいスマートフォンが発表されました", "ja"},
+		{"chinese", "苹果发布新款手机", "zh"},
+		{"korean", "삼성전자가 새로운 칩을 공개했다", "ko"},
+		{"russian", "Новый процессор оказался быстрее", "ru"},
+		{"arabic", "شركة أبل تكشف عن هاتف جديد", "ar"},
+		{"greek", "Νέος επεξεργαστής από την εταιρεία", "el"},
+		{"kana with han and latin", "iPhoneの新モデルが発表", "ja"},
+		{"empty", "", Unknown},
+		{"digits only", "2026 42 3.5", Unknown},
+		{"no stopwords", "iPhone Pixel Galaxy", Unknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, confidence := Detect(tt.text)
+			if got != tt.want {
+				t.Errorf("Detect(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+			if confidence < 0 || confidence > 1 || (got == Unknown && confidence != 0) {
+				t.Errorf("Detect(%q) confidence = %v", tt.text, confidence)
+			}
+		})
+	}
+}
+
+func TestDetectTieHasNoConfidence(t *testing.T) {
+	// "de" is a stopword of several languages with nothing to tell them apart
+	if lang, confidence := Detect("de"); confidence != 0 {
+		t.Errorf("Detect(%q) = %q with confidence %v, want 0", "de", lang, confidence)
+	}
+}
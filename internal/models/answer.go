@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Answer is the response to a question over the article archive
+type Answer struct {
+	Question  string     `json:"question"`
+	Answer    string     `json:"answer"`
+	Citations []Citation `json:"citations"`
+}
+
+// Citation is an archived article referenced as [Index] in an answer
+type Citation struct {
+	Index       int       `json:"index"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	Source      string    `json:"source"`
+	PublishedAt time.Time `json:"publishedAt"`
+}
@@ -1,21 +1,112 @@
 package models
 
-import "time"
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"time"
+)
+
+// Taxonomy is the fixed set of categories used for per-article classification
+var Taxonomy = []string{
+	"ai", "cloud", "security", "hardware", "software", "mobile",
+	"science", "business", "policy", "other",
+}
 
 type Article struct {
+	ID          string    `json:"id"`
 	Title       string    `json:"title"`
 	Desc        string    `json:"desc"`
+	Content     string    `json:"content,omitempty"`
 	URL         string    `json:"url"`
 	Source      string    `json:"source"`
 	PublishedAt time.Time `json:"publishedAt"`
 	Category    string    `json:"category"`
+	Language    string    `json:"language"`
+
+	Enrichment *ArticleEnrichment `json:"enrichment,omitempty"`
+	Market     *MarketAnnotation  `json:"market,omitempty"`
+}
+
+// ArticleEnrichment is the result of the optional per-article AI pass
+type ArticleEnrichment struct {
+	TLDR       string   `json:"tldr"`
+	Category   string   `json:"category"`
+	Importance int      `json:"importance"`
+	Tags       []string `json:"tags"`
+}
+
+// ArticleBrief is a compact reference to a ranked article
+type ArticleBrief struct {
+	Title      string `json:"title"`
+	URL        string `json:"url"`
+	Source     string `json:"source"`
+	Category   string `json:"category,omitempty"`
+	TLDR       string `json:"tldr,omitempty"`
+	Importance int    `json:"importance,omitempty"`
+}
+
+// Topic is a theme detected across the week's articles. Size and
+// Articles are only set when topics come from embedding clusters.
+type Topic struct {
+	Name     string         `json:"name"`
+	Size     int            `json:"size,omitempty"`
+	Articles []ArticleBrief `json:"articles,omitempty"`
+}
+
+// ArticleID derives a stable article identifier from its URL
+func ArticleID(url string) string {
+	sum := sha1.Sum([]byte(url))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Importance returns the AI assigned importance, or 0 when not enriched
+func (a Article) Importance() int {
+	if a.Enrichment == nil {
+		return 0
+	}
+	return a.Enrichment.Importance
+}
+
+// Brief returns the compact representation of the article
+func (a Article) Brief() ArticleBrief {
+	brief := ArticleBrief{
+		Title:    a.Title,
+		URL:      a.URL,
+		Source:   a.Source,
+		Category: a.Category,
+	}
+	if a.Enrichment != nil {
+		brief.Category = a.Enrichment.Category
+		brief.TLDR = a.Enrichment.TLDR
+		brief.Importance = a.Enrichment.Importance
+	}
+	return brief
 }
 
 type NewsSummary struct {
-	WeekRange       string    `json:"weekRange"`
-	TotalArticles   int       `json:"totalArticles"`
-	Summary         string    `json:"summary"`
-	KeyTopics       []string  `json:"keyTopics"`
-	TrendingStories []string  `json:"trendingStories"`
-	GeneratedAt     time.Time `json:"generatedAt"`
+	// Persona is the audience the digest was written for; Sections limits
+	// the rendered sections (empty renders all)
+	Persona         string           `json:"persona,omitempty"`
+	Sections        []string         `json:"sections,omitempty"`
+	Language        string           `json:"language"`
+	PeriodStart     time.Time        `json:"periodStart"`
+	PeriodEnd       time.Time        `json:"periodEnd"`
+	WeekRange       string           `json:"weekRange"`
+	TotalArticles   int              `json:"totalArticles"`
+	Summary         string           `json:"summary"`
+	KeyTopics       []Topic          `json:"keyTopics"`
+	TrendingStories []string         `json:"trendingStories"`
+	TopArticles     []ArticleBrief   `json:"topArticles,omitempty"`
+	Trends          *Trends          `json:"trends,omitempty"`
+	MarketPulse     *MarketPulse     `json:"marketPulse,omitempty"`
+	Watchlist       []WatchlistMatch `json:"watchlist,omitempty"`
+	Usage           *UsageReport     `json:"usage,omitempty"`
+	// FullURL links to the stored full version when the message is shortened
+	FullURL string `json:"fullUrl,omitempty"`
+	// GroundingScore is the share of entities and figures in the summary
+	// that were found in the input articles (1 when it has none). It is nil,
+	// and omitted, when grounding verification is off.
+	GroundingScore    *float64  `json:"groundingScore,omitempty"`
+	UnsupportedClaims []string  `json:"unsupportedClaims,omitempty"`
+	GeneratedAt       time.Time `json:"generatedAt"`
 }
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// HistoryEntry is the stored record of a single digest run
+type HistoryEntry struct {
+	GeneratedAt time.Time `json:"generatedAt"`
+	PeriodStart time.Time `json:"periodStart"`
+	PeriodEnd   time.Time `json:"periodEnd"`
+	Summary     string    `json:"summary"`
+	Topics      []Topic   `json:"topics"`
+	// TopicCounts is the number of articles per normalized topic term
+	TopicCounts map[string]int `json:"topicCounts"`
+	// TopicSource is where the topic terms came from, "enrichment" or
+	// "titles"; counts from different sources are not comparable
+	TopicSource string       `json:"topicSource,omitempty"`
+	Articles    []Article    `json:"articles"`
+	MarketPulse *MarketPulse `json:"marketPulse,omitempty"`
+}
+
+// TopicTrend is the change in coverage of a topic between two periods
+type TopicTrend struct {
+	Topic    string `json:"topic"`
+	Current  int    `json:"current"`
+	Previous int    `json:"previous"`
+}
+
+// Delta returns the change in article count
+func (t TopicTrend) Delta() int {
+	return t.Current - t.Previous
+}
+
+// Trends compares the current period with the previous stored run
+type Trends struct {
+	PreviousPeriodEnd time.Time    `json:"previousPeriodEnd"`
+	Changes           []TopicTrend `json:"changes,omitempty"`
+	Emerging          []TopicTrend `json:"emerging,omitempty"`
+	Fading            []TopicTrend `json:"fading,omitempty"`
+}
+
+// IsEmpty reports whether there is nothing worth showing
+func (t *Trends) IsEmpty() bool {
+	return t == nil || len(t.Changes)+len(t.Emerging)+len(t.Fading) == 0
+}
@@ -0,0 +1,37 @@
+package models
+
+// Sentiment labels
+const (
+	SentimentPositive = "positive"
+	SentimentNeutral  = "neutral"
+	SentimentNegative = "negative"
+)
+
+// MarketAnnotation is the market signal extracted from a business article
+type MarketAnnotation struct {
+	Sentiment string       `json:"sentiment"`
+	Companies []CompanyRef `json:"companies,omitempty"`
+}
+
+// CompanyRef is a company mentioned in an article, normalized against the
+// configured company list. Ticker is empty for unlisted companies.
+type CompanyRef struct {
+	Name   string `json:"name"`
+	Ticker string `json:"ticker,omitempty"`
+}
+
+// MarketPulse aggregates the sentiment of the week's business stories
+type MarketPulse struct {
+	Sentiment string          `json:"sentiment"`
+	Positive  int             `json:"positive"`
+	Neutral   int             `json:"neutral"`
+	Negative  int             `json:"negative"`
+	Companies []CompanySignal `json:"companies,omitempty"`
+}
+
+// CompanySignal is the aggregated sentiment of one company's mentions
+type CompanySignal struct {
+	CompanyRef
+	Mentions  int    `json:"mentions"`
+	Sentiment string `json:"sentiment"`
+}
@@ -0,0 +1,30 @@
+package models
+
+// Digest sections a persona can choose from, in rendering order
+const (
+	SectionSummary         = "summary"
+	SectionKeyTopics       = "key_topics"
+	SectionTopStories      = "top_stories"
+	SectionTrends          = "trends"
+	SectionMarketPulse     = "market_pulse"
+	SectionTrendingStories = "trending_stories"
+)
+
+// AllSections lists every digest section in rendering order
+var AllSections = []string{
+	SectionSummary, SectionKeyTopics, SectionTopStories, SectionTrends, SectionMarketPulse, SectionTrendingStories,
+}
+
+// HasSection reports whether the summary should render section. A summary
+// without an explicit section list renders everything.
+func (s *NewsSummary) HasSection(section string) bool {
+	if len(s.Sections) == 0 {
+		return true
+	}
+	for _, name := range s.Sections {
+		if name == section {
+			return true
+		}
+	}
+	return false
+}
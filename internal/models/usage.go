@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// UsageReport aggregates LLM token usage and cost for a single run
+type UsageReport struct {
+	StartedAt        time.Time              `json:"startedAt"`
+	Calls            int                    `json:"calls"`
+	CachedCalls      int                    `json:"cachedCalls"`
+	PromptTokens     int                    `json:"promptTokens"`
+	CompletionTokens int                    `json:"completionTokens"`
+	CostUSD          float64                `json:"costUsd"`
+	ByModel          map[string]*ModelUsage `json:"byModel"`
+}
+
+// ModelUsage is the usage attributed to a single provider/model pair
+type ModelUsage struct {
+	Calls            int     `json:"calls"`
+	PromptTokens     int     `json:"promptTokens"`
+	CompletionTokens int     `json:"completionTokens"`
+	CostUSD          float64 `json:"costUsd"`
+}
@@ -0,0 +1,7 @@
+package models
+
+// WatchlistMatch lists the collected articles mentioning a watched entity
+type WatchlistMatch struct {
+	Entity   string         `json:"entity"`
+	Articles []ArticleBrief `json:"articles,omitempty"`
+}
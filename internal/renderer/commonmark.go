@@ -0,0 +1,56 @@
+package renderer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// commonMark renders CommonMark as understood by Mattermost, Teams and most
+// other chat tools
+type commonMark struct{}
+
+var commonMarkEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", `\<`, ">", `\>`)
+
+func (commonMark) Escape(text string) string  { return commonMarkEscaper.Replace(text) }
+func (commonMark) Bold(inner string) string   { return "**" + inner + "**" }
+func (commonMark) Italic(inner string) string { return "*" + inner + "*" }
+func (commonMark) LineBreak() string          { return "\n" }
+func (commonMark) Paragraph(inner string) string {
+	return inner
+}
+func (commonMark) Quote(inner string) string { return "> " + inner }
+func (commonMark) Rule() string              { return "---" }
+
+func (commonMark) Code(text string) string {
+	if strings.Contains(text, "`") {
+		return "`` " + text + " ``"
+	}
+	return "`" + text + "`"
+}
+
+func (commonMark) CodeBlock(text string) string {
+	return "```\n" + text + "\n```"
+}
+
+func (commonMark) Link(inner, url string) string {
+	url = strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29").Replace(url)
+	return "[" + inner + "](" + url + ")"
+}
+
+func (commonMark) Heading(level int, inner string) string {
+	return strings.Repeat("#", level) + " " + inner
+}
+
+func (commonMark) List(ordered bool, items []string) string {
+	return textList(items, func(i int) string {
+		if ordered {
+			return fmt.Sprintf("%d. ", i+1)
+		}
+		return "- "
+	})
+}
+
+func (commonMark) Join(blocks []string) string {
+	return joinBlocks(blocks, "\n\n")
+}
@@ -0,0 +1,211 @@
+// Package renderer turns a NewsSummary into a markup-independent document
+// and renders it as Telegram MarkdownV2 or HTML, Slack mrkdwn, CommonMark,
+// HTML or plain text, so every channel delivers the same content.
+package renderer
+
+import (
+	"fmt"
+	"tech-news-agent/internal/i18n"
+	"tech-news-agent/internal/models"
+)
+
+// Ways the items of a section are listed
+const (
+	ListBullet  = "bullet"
+	ListOrdered = "ordered"
+	ListPlain   = "plain"
+)
+
+// SectionWatchlist names the watchlist section, which personas cannot
+// switch off
+const SectionWatchlist = "watchlist"
+
+// WatchlistArticles is the number of stories listed per watched entity
+const WatchlistArticles = 5
+
+// Document is the content of a digest independent of any markup
+type Document struct {
+	Icon  string
+	Title string
+	// Meta lines shown under the title, e.g. the week range
+	Meta     []Item
+	Sections []Section
+	Footer   []Item
+}
+
+// Section is one titled block of a digest. The generated summary is parsed
+// into Body; all other sections are lists of items.
+type Section struct {
+	Name  string
+	Icon  string
+	Title string
+	Body  []Block
+	List  string
+	Items []Item
+	// Empty is shown instead of the items when there are none
+	Empty string
+}
+
+// Heading returns the section title with its icon
+func (s Section) Heading() string {
+	if s.Icon == "" {
+		return s.Title
+	}
+	return s.Icon + " " + s.Title
+}
+
+// Item is one entry of a section; items with children are headed groups,
+// e.g. a watched entity and its articles
+type Item struct {
+	Icon     string
+	Text     string
+	URL      string
+	Count    int
+	Detail   string
+	Strong   bool
+	Emphasis bool
+	Children []Item
+}
+
+// Label returns the item text with its count, e.g. "AI agents (4)"
+func (it Item) Label() string {
+	if it.Count > 0 {
+		return fmt.Sprintf("%s (%d)", it.Text, it.Count)
+	}
+	return it.Text
+}
+
+// Build assembles the sections of summary that should be rendered, in
+// rendering order
+func Build(summary *models.NewsSummary) Document {
+	lang := summary.Language
+	doc := Document{
+		Icon:  "📰",
+		Title: i18n.T(lang, i18n.DigestTitle),
+		Meta: []Item{
+			{Icon: "📅", Text: summary.WeekRange, Strong: true},
+			{Icon: "📊", Text: i18n.T(lang, i18n.ArticlesAnalyzed, summary.TotalArticles)},
+		},
+	}
+
+	if summary.HasSection(models.SectionSummary) {
+		doc.Sections = append(doc.Sections, Section{Name: models.SectionSummary, Body: ParseMarkdown(summary.Summary)})
+	}
+
+	if len(summary.KeyTopics) > 0 && summary.HasSection(models.SectionKeyTopics) {
+		section := Section{Name: models.SectionKeyTopics, Icon: "🔑", Title: i18n.T(lang, i18n.KeyTopics), List: ListBullet}
+		for _, topic := range summary.KeyTopics {
+			section.Items = append(section.Items, Item{Text: topic.Name, Count: topic.Size})
+		}
+		doc.Sections = append(doc.Sections, section)
+	}
+
+	if len(summary.TopArticles) > 0 && summary.HasSection(models.SectionTopStories) {
+		section := Section{Name: models.SectionTopStories, Icon: "⭐", Title: i18n.T(lang, i18n.TopStories, len(summary.TopArticles)), List: ListOrdered}
+		for _, article := range summary.TopArticles {
+			section.Items = append(section.Items, Item{Text: article.Title, URL: article.URL, Detail: article.TLDR})
+		}
+		doc.Sections = append(doc.Sections, section)
+	}
+
+	// Watched entities, shown even when their stories missed the top list
+	if len(summary.Watchlist) > 0 {
+		section := Section{Name: SectionWatchlist, Icon: "👀", Title: i18n.T(lang, i18n.Watchlist), List: ListBullet}
+		for _, match := range summary.Watchlist {
+			if len(match.Articles) == 0 {
+				continue
+			}
+			group := Item{Text: match.Entity, Count: len(match.Articles), Strong: true}
+			for _, article := range match.Articles[:min(len(match.Articles), WatchlistArticles)] {
+				group.Children = append(group.Children, Item{Text: article.Title, URL: article.URL})
+			}
+			section.Items = append(section.Items, group)
+		}
+		if len(section.Items) == 0 {
+			section.Empty = i18n.T(lang, i18n.WatchlistEmpty)
+		}
+		doc.Sections = append(doc.Sections, section)
+	}
+
+	if trends := summary.Trends; !trends.IsEmpty() && summary.HasSection(models.SectionTrends) {
+		doc.Sections = append(doc.Sections, plainSection(models.SectionTrends, "📈", i18n.T(lang, i18n.TrendsTitle), TrendLines(lang, trends)))
+	}
+
+	if pulse := summary.MarketPulse; pulse != nil && summary.HasSection(models.SectionMarketPulse) {
+		doc.Sections = append(doc.Sections, plainSection(models.SectionMarketPulse, "💹", i18n.T(lang, i18n.MarketPulse), PulseLines(lang, pulse)))
+	}
+
+	if len(summary.TrendingStories) > 0 && summary.HasSection(models.SectionTrendingStories) {
+		section := Section{Name: models.SectionTrendingStories, Icon: "🔥", Title: i18n.T(lang, i18n.TrendingStories), List: ListOrdered}
+		for _, story := range summary.TrendingStories {
+			section.Items = append(section.Items, Item{Text: StripMarkdown(story)})
+		}
+		doc.Sections = append(doc.Sections, section)
+	}
+
+	if summary.FullURL != "" {
+		doc.Footer = append(doc.Footer, Item{Icon: "📄", Text: i18n.T(lang, i18n.FullVersion), URL: summary.FullURL})
+	}
+	doc.Footer = append(doc.Footer, Item{Icon: "🤖", Text: i18n.T(lang, i18n.GeneratedOn, i18n.FormatTimestamp(lang, summary.GeneratedAt))})
+	if usage := summary.Usage; usage != nil {
+		doc.Footer = append(doc.Footer, Item{Icon: "💰", Text: fmt.Sprintf("%d tokens · $%.4f", usage.PromptTokens+usage.CompletionTokens, usage.CostUSD)})
+	}
+	doc.Footer = append(doc.Footer, Item{Text: i18n.T(lang, i18n.PoweredBy), Emphasis: true})
+
+	return doc
+}
+
+func plainSection(name, icon, title string, lines []string) Section {
+	section := Section{Name: name, Icon: icon, Title: title, List: ListPlain}
+	for _, line := range lines {
+		section.Items = append(section.Items, Item{Text: line})
+	}
+	return section
+}
+
+// TrendLines renders the week-over-week trends as one line per topic
+func TrendLines(lang string, trends *models.Trends) []string {
+	var lines []string
+	for _, t := range trends.Changes {
+		arrow := "↑"
+		if t.Delta() < 0 {
+			arrow = "↓"
+		}
+		lines = append(lines, fmt.Sprintf("%s %s: %d → %d (%+d%%)", arrow, t.Topic, t.Previous, t.Current, t.Delta()*100/t.Previous))
+	}
+	for _, t := range trends.Emerging {
+		lines = append(lines, "🆕 "+i18n.T(lang, i18n.TrendEmerging, t.Topic, t.Current))
+	}
+	for _, t := range trends.Fading {
+		lines = append(lines, "💤 "+i18n.T(lang, i18n.TrendFading, t.Topic, t.Previous))
+	}
+	return lines
+}
+
+// PulseLines renders the market pulse as an overall line followed by one
+// line per company
+func PulseLines(lang string, pulse *models.MarketPulse) []string {
+	lines := []string{fmt.Sprintf("%s %s (👍 %d · 😐 %d · 👎 %d)",
+		SentimentIcon(pulse.Sentiment), i18n.T(lang, i18n.Sentiment, i18n.T(lang, pulse.Sentiment)),
+		pulse.Positive, pulse.Neutral, pulse.Negative)}
+	for _, c := range pulse.Companies {
+		name := c.Name
+		if c.Ticker != "" {
+			name = fmt.Sprintf("%s $%s", c.Name, c.Ticker)
+		}
+		lines = append(lines, fmt.Sprintf("%s %s (%d)", SentimentIcon(c.Sentiment), name, c.Mentions))
+	}
+	return lines
+}
+
+// SentimentIcon returns the traffic light shown next to a sentiment
+func SentimentIcon(sentiment string) string {
+	switch sentiment {
+	case models.SentimentPositive:
+		return "🟢"
+	case models.SentimentNegative:
+		return "🔴"
+	default:
+		return "⚪"
+	}
+}
@@ -0,0 +1,49 @@
+package renderer
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// htmlMarkup renders HTML fragments for web pages, email and Matrix
+type htmlMarkup struct{}
+
+func (htmlMarkup) Escape(text string) string     { return html.EscapeString(text) }
+func (htmlMarkup) Bold(inner string) string      { return "<strong>" + inner + "</strong>" }
+func (htmlMarkup) Italic(inner string) string    { return "<em>" + inner + "</em>" }
+func (htmlMarkup) Code(text string) string       { return "<code>" + html.EscapeString(text) + "</code>" }
+func (htmlMarkup) LineBreak() string             { return "<br>" }
+func (htmlMarkup) Paragraph(inner string) string { return "<p>" + inner + "</p>" }
+func (htmlMarkup) Quote(inner string) string     { return "<blockquote>" + inner + "</blockquote>" }
+func (htmlMarkup) Rule() string                  { return "<hr>" }
+
+func (htmlMarkup) CodeBlock(text string) string {
+	return "<pre><code>" + html.EscapeString(text) + "</code></pre>"
+}
+
+func (htmlMarkup) Link(inner, url string) string {
+	return fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(url), inner)
+}
+
+func (htmlMarkup) Heading(level int, inner string) string {
+	return fmt.Sprintf("<h%d>%s</h%d>", level, inner, level)
+}
+
+func (htmlMarkup) List(ordered bool, items []string) string {
+	tag := "ul"
+	if ordered {
+		tag = "ol"
+	}
+	var sb strings.Builder
+	sb.WriteString("<" + tag + ">")
+	for _, item := range items {
+		sb.WriteString("<li>" + item + "</li>")
+	}
+	sb.WriteString("</" + tag + ">")
+	return sb.String()
+}
+
+func (htmlMarkup) Join(blocks []string) string {
+	return joinBlocks(blocks, "\n")
+}
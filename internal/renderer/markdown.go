@@ -0,0 +1,193 @@
+package renderer
+
+import (
+	"regexp"
+	"strings"
+)
+
+// BlockKind is the type of a block of generated text
+type BlockKind int
+
+const (
+	BlockParagraph BlockKind = iota
+	BlockHeading
+	BlockBullet
+	BlockNumbered
+	BlockQuote
+	BlockCode
+)
+
+// Block is a line-level element of the markdown the model writes.
+// Consecutive lines of a paragraph are joined by SpanBreak.
+type Block struct {
+	Kind BlockKind
+	// Level is the heading level (1-6)
+	Level int
+	Spans []Span
+	// Code holds the raw text of a fenced code block
+	Code string
+}
+
+// SpanKind is the type of an inline element
+type SpanKind int
+
+const (
+	SpanText SpanKind = iota
+	SpanBold
+	SpanItalic
+	SpanCode
+	SpanLink
+	SpanBreak
+)
+
+// Span is a run of inline text with one style. Styles do not nest; a link
+// carries its text as plain text.
+type Span struct {
+	Kind SpanKind
+	Text string
+	URL  string
+}
+
+var (
+	headingPattern  = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*$`)
+	bulletPattern   = regexp.MustCompile(`^[-*+•]\s+(.*)$`)
+	numberedPattern = regexp.MustCompile(`^\d{1,3}[.)]\s+(.*)$`)
+	quotePattern    = regexp.MustCompile(`^>\s?(.*)$`)
+	rulePattern     = regexp.MustCompile(`^([-*_])(\s*[-*_]){2,}$`)
+)
+
+// ParseMarkdown parses the markdown subset the model writes: headings,
+// bullet and numbered lists, quotes, fenced code and paragraphs with bold,
+// italic, code and link spans. Anything else is kept as text.
+func ParseMarkdown(markdown string) []Block {
+	var blocks []Block
+	var paragraph []Span
+	var code []string
+	inCode := false
+
+	flush := func() {
+		if len(paragraph) > 0 {
+			blocks = append(blocks, Block{Kind: BlockParagraph, Spans: paragraph})
+			paragraph = nil
+		}
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") {
+			if inCode {
+				blocks = append(blocks, Block{Kind: BlockCode, Code: strings.Join(code, "\n")})
+				code, inCode = nil, false
+			} else {
+				flush()
+				inCode = true
+			}
+			continue
+		}
+		if inCode {
+			code = append(code, line)
+			continue
+		}
+
+		switch {
+		case trimmed == "" || rulePattern.MatchString(trimmed):
+			flush()
+		case headingPattern.MatchString(trimmed):
+			flush()
+			m := headingPattern.FindStringSubmatch(trimmed)
+			blocks = append(blocks, Block{Kind: BlockHeading, Level: len(m[1]), Spans: ParseInline(m[2])})
+		case bulletPattern.MatchString(trimmed):
+			flush()
+			blocks = append(blocks, Block{Kind: BlockBullet, Spans: ParseInline(bulletPattern.FindStringSubmatch(trimmed)[1])})
+		case numberedPattern.MatchString(trimmed):
+			flush()
+			blocks = append(blocks, Block{Kind: BlockNumbered, Spans: ParseInline(numberedPattern.FindStringSubmatch(trimmed)[1])})
+		case quotePattern.MatchString(trimmed):
+			flush()
+			blocks = append(blocks, Block{Kind: BlockQuote, Spans: ParseInline(quotePattern.FindStringSubmatch(trimmed)[1])})
+		default:
+			if len(paragraph) > 0 {
+				paragraph = append(paragraph, Span{Kind: SpanBreak})
+			}
+			paragraph = append(paragraph, ParseInline(trimmed)...)
+		}
+	}
+	flush()
+	// An unclosed fence keeps its text
+	if inCode {
+		blocks = append(blocks, Block{Kind: BlockCode, Code: strings.Join(code, "\n")})
+	}
+
+	return blocks
+}
+
+// inlinePattern matches links, code, bold and italic runs. Markers without
+// a closing partner do not match and stay literal text. Runs end at the
+// first closing marker, so "**a** and **b**" is two runs, not one.
+var inlinePattern = regexp.MustCompile(
+	`\[([^\]]+)\]\((https?://[^\s()]+(?:\([^\s()]*\)[^\s()]*)*)\)` +
+		"|`([^`]+)`" +
+		`|\*\*([^\s*](?:.*?\S)??)\*\*` +
+		`|__([^\s_](?:.*?\S)??)__` +
+		`|\*([^\s*](?:[^*]*?[^\s*])?)\*` +
+		`|\b_([^\s_](?:[^_]*?[^\s_])?)_\b`)
+
+// ParseInline splits a line of markdown into styled spans
+func ParseInline(text string) []Span {
+	var spans []Span
+	addText := func(s string) {
+		if s == "" {
+			return
+		}
+		if n := len(spans); n > 0 && spans[n-1].Kind == SpanText {
+			spans[n-1].Text += s
+			return
+		}
+		spans = append(spans, Span{Kind: SpanText, Text: s})
+	}
+
+	// Adjacent runs of one style are merged, since markups like Telegram's
+	// cannot tell "_a__b_" from an underlined run. Markup nested in a run is
+	// dropped, since styles do not nest.
+	addStyled := func(kind SpanKind, s string) {
+		if n := len(spans); n > 0 && spans[n-1].Kind == kind {
+			spans[n-1].Text += s
+			return
+		}
+		spans = append(spans, Span{Kind: kind, Text: s})
+	}
+
+	last := 0
+	for _, m := range inlinePattern.FindAllStringSubmatchIndex(text, -1) {
+		addText(text[last:m[0]])
+		last = m[1]
+		group := func(i int) string { return text[m[2*i]:m[2*i+1]] }
+		switch {
+		case m[2] >= 0:
+			spans = append(spans, Span{Kind: SpanLink, Text: StripMarkdown(group(1)), URL: group(2)})
+		case m[6] >= 0:
+			spans = append(spans, Span{Kind: SpanCode, Text: group(3)})
+		case m[8] >= 0:
+			addStyled(SpanBold, StripMarkdown(group(4)))
+		case m[10] >= 0:
+			addStyled(SpanBold, StripMarkdown(group(5)))
+		case m[12] >= 0:
+			addStyled(SpanItalic, StripMarkdown(group(6)))
+		case m[14] >= 0:
+			addStyled(SpanItalic, StripMarkdown(group(7)))
+		}
+	}
+	addText(text[last:])
+
+	return spans
+}
+
+// StripMarkdown returns the text of a line of markdown without markup
+func StripMarkdown(text string) string {
+	var sb strings.Builder
+	for _, span := range ParseInline(text) {
+		sb.WriteString(span.Text)
+	}
+	return sb.String()
+}
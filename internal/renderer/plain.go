@@ -0,0 +1,52 @@
+package renderer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// plainText renders text without markup, e.g. for the plain part of an
+// email
+type plainText struct{}
+
+func (plainText) Escape(text string) string     { return text }
+func (plainText) Bold(inner string) string      { return inner }
+func (plainText) Italic(inner string) string    { return inner }
+func (plainText) Code(text string) string       { return text }
+func (plainText) CodeBlock(text string) string  { return text }
+func (plainText) LineBreak() string             { return "\n" }
+func (plainText) Paragraph(inner string) string { return inner }
+func (plainText) Quote(inner string) string     { return "> " + inner }
+func (plainText) Rule() string                  { return "--" }
+
+func (plainText) Link(inner, url string) string {
+	if inner == url {
+		return url
+	}
+	return inner + " <" + url + ">"
+}
+
+// Heading underlines the top two levels
+func (plainText) Heading(level int, inner string) string {
+	switch level {
+	case 1:
+		return inner + "\n" + strings.Repeat("=", len([]rune(inner)))
+	case 2:
+		return inner + "\n" + strings.Repeat("-", len([]rune(inner)))
+	default:
+		return inner
+	}
+}
+
+func (plainText) List(ordered bool, items []string) string {
+	return textList(items, func(i int) string {
+		if ordered {
+			return fmt.Sprintf("%d. ", i+1)
+		}
+		return "• "
+	})
+}
+
+func (plainText) Join(blocks []string) string {
+	return joinBlocks(blocks, "\n\n")
+}
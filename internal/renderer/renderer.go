@@ -0,0 +1,274 @@
+package renderer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Format names an output markup
+type Format string
+
+const (
+	TelegramMarkdownV2 Format = "telegram-markdownv2"
+	TelegramHTML       Format = "telegram-html"
+	SlackMrkdwn        Format = "slack-mrkdwn"
+	CommonMark         Format = "commonmark"
+	HTML               Format = "html"
+	PlainText          Format = "plain"
+)
+
+// Markup is the syntax of one output format. Arguments named inner are
+// already rendered; all other text is raw and must be escaped by the markup.
+type Markup interface {
+	Escape(text string) string
+	Bold(inner string) string
+	Italic(inner string) string
+	Code(text string) string
+	CodeBlock(text string) string
+	Link(inner, url string) string
+	LineBreak() string
+	Heading(level int, inner string) string
+	Paragraph(inner string) string
+	Quote(inner string) string
+	List(ordered bool, items []string) string
+	Rule() string
+	// Join separates blocks
+	Join(blocks []string) string
+}
+
+// Renderer renders documents with one markup
+type Renderer struct {
+	markup Markup
+}
+
+// New creates a renderer for format, falling back to plain text for unknown
+// formats
+func New(format Format) *Renderer {
+	switch format {
+	case TelegramMarkdownV2:
+		return &Renderer{markup: telegramMarkdownV2{}}
+	case TelegramHTML:
+		return &Renderer{markup: telegramHTML{}}
+	case SlackMrkdwn:
+		return &Renderer{markup: slackMrkdwn{}}
+	case CommonMark:
+		return &Renderer{markup: commonMark{}}
+	case HTML:
+		return &Renderer{markup: htmlMarkup{}}
+	default:
+		return &Renderer{markup: plainText{}}
+	}
+}
+
+// Document renders the header, all sections and the footer
+func (r *Renderer) Document(doc Document) string {
+	blocks := []string{r.Header(doc)}
+	for _, section := range doc.Sections {
+		blocks = append(blocks, r.Section(section))
+	}
+	blocks = append(blocks, r.Footer(doc))
+	return r.markup.Join(blocks)
+}
+
+// Header renders the title and the meta lines
+func (r *Renderer) Header(doc Document) string {
+	return r.markup.Join([]string{
+		r.markup.Heading(1, r.markup.Escape(doc.Icon+" "+doc.Title)),
+		r.Lines(doc.Meta),
+	})
+}
+
+// Section renders the heading and content of a section. The generated
+// summary has no heading of its own.
+func (r *Renderer) Section(s Section) string {
+	if s.Title == "" {
+		return r.Content(s)
+	}
+	return r.markup.Join([]string{r.markup.Heading(2, r.markup.Escape(s.Heading())), r.Content(s)})
+}
+
+// Content renders a section without its heading
+func (r *Renderer) Content(s Section) string {
+	if len(s.Body) > 0 {
+		return r.Blocks(s.Body)
+	}
+	if len(s.Items) == 0 {
+		if s.Empty == "" {
+			return ""
+		}
+		return r.markup.Paragraph(r.markup.Italic(r.markup.Escape(s.Empty)))
+	}
+	if s.List == ListPlain {
+		return r.Lines(s.Items)
+	}
+	return r.list(s.List == ListOrdered, s.Items)
+}
+
+// Footer renders a rule followed by the footer lines
+func (r *Renderer) Footer(doc Document) string {
+	return r.markup.Join([]string{r.markup.Rule(), r.Lines(doc.Footer)})
+}
+
+// Lines renders items as one paragraph with a line each
+func (r *Renderer) Lines(items []Item) string {
+	var lines []string
+	for _, item := range items {
+		lines = append(lines, r.Item(item))
+	}
+	return r.markup.Paragraph(strings.Join(lines, r.markup.LineBreak()))
+}
+
+func (r *Renderer) list(ordered bool, items []Item) string {
+	var rendered []string
+	for _, item := range items {
+		text := r.Item(item)
+		if item.Detail != "" {
+			text += r.markup.LineBreak() + r.markup.Escape(item.Detail)
+		}
+		// A nested list is a block of its own, so a newline is enough
+		if len(item.Children) > 0 {
+			text += "\n" + r.list(false, item.Children)
+		}
+		rendered = append(rendered, text)
+	}
+	return r.markup.List(ordered, rendered)
+}
+
+// Item renders one item: its icon, then its label, linked when it has a URL
+func (r *Renderer) Item(it Item) string {
+	var text string
+	switch {
+	case it.URL != "":
+		text = r.markup.Link(r.markup.Escape(it.Text), it.URL)
+		if it.Count > 0 {
+			text += r.markup.Escape(fmt.Sprintf(" (%d)", it.Count))
+		}
+	case it.Strong:
+		text = r.markup.Bold(r.markup.Escape(it.Text))
+		if it.Count > 0 {
+			text += r.markup.Escape(fmt.Sprintf(" (%d)", it.Count))
+		}
+	default:
+		text = r.markup.Escape(it.Label())
+	}
+	if it.Emphasis {
+		text = r.markup.Italic(text)
+	}
+	if it.Icon != "" {
+		text = r.markup.Escape(it.Icon+" ") + text
+	}
+	return text
+}
+
+// Blocks renders parsed markdown. Headings start at level 3 so they rank
+// below the section headings.
+func (r *Renderer) Blocks(blocks []Block) string {
+	var rendered []string
+	for i := 0; i < len(blocks); i++ {
+		block := blocks[i]
+		switch block.Kind {
+		case BlockHeading:
+			rendered = append(rendered, r.markup.Heading(min(max(block.Level, 3), 4), r.Spans(unbold(block.Spans))))
+		case BlockBullet, BlockNumbered:
+			// Consecutive items of the same kind form one list
+			var items []string
+			for ; i < len(blocks) && blocks[i].Kind == block.Kind; i++ {
+				items = append(items, r.Spans(blocks[i].Spans))
+			}
+			i--
+			rendered = append(rendered, r.markup.List(block.Kind == BlockNumbered, items))
+		case BlockQuote:
+			rendered = append(rendered, r.markup.Quote(r.Spans(block.Spans)))
+		case BlockCode:
+			rendered = append(rendered, r.markup.CodeBlock(block.Code))
+		default:
+			rendered = append(rendered, r.markup.Paragraph(r.Spans(block.Spans)))
+		}
+	}
+	return r.markup.Join(rendered)
+}
+
+// Spans renders inline markdown
+func (r *Renderer) Spans(spans []Span) string {
+	var sb strings.Builder
+	for _, span := range spans {
+		switch span.Kind {
+		case SpanBold:
+			sb.WriteString(r.markup.Bold(r.markup.Escape(span.Text)))
+		case SpanItalic:
+			sb.WriteString(r.markup.Italic(r.markup.Escape(span.Text)))
+		case SpanCode:
+			sb.WriteString(r.markup.Code(span.Text))
+		case SpanLink:
+			sb.WriteString(r.markup.Link(r.markup.Escape(span.Text), span.URL))
+		case SpanBreak:
+			sb.WriteString(r.markup.LineBreak())
+		default:
+			sb.WriteString(r.markup.Escape(span.Text))
+		}
+	}
+	return sb.String()
+}
+
+// unbold turns bold spans into text. Headings are bold already, and
+// Telegram rejects a bold entity nested in another.
+func unbold(spans []Span) []Span {
+	flat := make([]Span, 0, len(spans))
+	for _, span := range spans {
+		if span.Kind == SpanBold {
+			span.Kind = SpanText
+		}
+		flat = append(flat, span)
+	}
+	return flat
+}
+
+// Markdown parses and renders the model's markdown
+func (r *Renderer) Markdown(markdown string) string {
+	return r.Blocks(ParseMarkdown(markdown))
+}
+
+// Text escapes plain text for the markup
+func (r *Renderer) Text(text string) string {
+	return r.markup.Escape(text)
+}
+
+// Bold renders plain text in bold
+func (r *Renderer) Bold(text string) string {
+	return r.markup.Bold(r.markup.Escape(text))
+}
+
+// CodeBlock renders plain text as preformatted
+func (r *Renderer) CodeBlock(text string) string {
+	return r.markup.CodeBlock(text)
+}
+
+// textList renders list items as lines with a marker, indenting the
+// continuation lines of an item under its text
+func textList(items []string, marker func(i int) string) string {
+	var lines []string
+	for i, item := range items {
+		prefix := marker(i)
+		// Escaping backslashes take no room on screen
+		indent := strings.Repeat(" ", len([]rune(strings.ReplaceAll(prefix, `\`, ""))))
+		for j, line := range strings.Split(item, "\n") {
+			if j == 0 {
+				lines = append(lines, prefix+line)
+			} else {
+				lines = append(lines, indent+line)
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// joinBlocks joins the non-empty blocks with sep
+func joinBlocks(blocks []string, sep string) string {
+	var kept []string
+	for _, block := range blocks {
+		if block != "" {
+			kept = append(kept, block)
+		}
+	}
+	return strings.Join(kept, sep)
+}
@@ -0,0 +1,192 @@
+package renderer
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"tech-news-agent/internal/models"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+var formats = []Format{TelegramMarkdownV2, TelegramHTML, SlackMrkdwn, CommonMark, HTML, PlainText}
+
+// testSummary returns a digest that fills every section, with markup and
+// characters each format has to escape
+func testSummary() *models.NewsSummary {
+	return &models.NewsSummary{
+		Language:      "en",
+		PeriodStart:   time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:     time.Date(2026, 10, 11, 0, 0, 0, 0, time.UTC),
+		WeekRange:     "Oct 5 - Oct 11",
+		TotalArticles: 42,
+		Summary: "## Chips & *AI* ##\n\n" +
+			"Chipmakers raised **guidance** again (+12.5%) and *all* eyes are on `N2_node`.\n" +
+			"Read [the Go_(lang) page](https://en.wikipedia.org/wiki/Go_(programming_language)) for <context>.\n\n" +
+			"- **a** and **b** shipped\n" +
+			"- snake_case_name stays plain, a *lone star too\n\n" +
+			"1. First [step](https://example.com/a_b?x=1&y=2)\n" +
+			"2. Second step!\n\n" +
+			"> Quote with #hashtag and 3 > 2\n\n" +
+			"```\nfunc main() { fmt.Println(\"<hi>\") }\n```\n\n" +
+			"### C# tips",
+		KeyTopics: []models.Topic{{Name: "AI agents", Size: 4}, {Name: "Chips_&_fabs"}},
+		TopArticles: []models.ArticleBrief{
+			{Title: "TSMC beats [estimates]", URL: "https://example.com/tsmc", TLDR: "Revenue up 40% *again*."},
+			{Title: "Go 1.30 released", URL: "https://go.dev/blog/go1.30"},
+		},
+		Watchlist: []models.WatchlistMatch{
+			{Entity: "Nvidia", Articles: []models.ArticleBrief{{Title: "Nvidia_H300 ships", URL: "https://example.com/h300"}}},
+			{Entity: "Intel"},
+		},
+		Trends: &models.Trends{
+			PreviousPeriodEnd: time.Date(2026, 10, 4, 0, 0, 0, 0, time.UTC),
+			Changes:           []models.TopicTrend{{Topic: "ai", Current: 10, Previous: 6}},
+			Emerging:          []models.TopicTrend{{Topic: "quantum", Current: 3}},
+			Fading:            []models.TopicTrend{{Topic: "crypto", Previous: 5}},
+		},
+		MarketPulse: &models.MarketPulse{
+			Sentiment: "positive",
+			Positive:  5,
+			Neutral:   2,
+			Negative:  1,
+			Companies: []models.CompanySignal{
+				{CompanyRef: models.CompanyRef{Name: "TSMC", Ticker: "TSM"}, Mentions: 3, Sentiment: "positive"},
+			},
+		},
+		TrendingStories: []string{"**Chip** export rules tighten", "Rust 2.0_rc lands"},
+		FullURL:         "https://news.example.com/digests/2026-10-11.html",
+		Usage:           &models.UsageReport{PromptTokens: 12000, CompletionTokens: 800, CostUSD: 0.0123},
+		GeneratedAt:     time.Date(2026, 10, 12, 8, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestRenderGolden(t *testing.T) {
+	doc := Build(testSummary())
+	for _, format := range formats {
+		t.Run(string(format), func(t *testing.T) {
+			got := New(format).Document(doc)
+			path := filepath.Join("testdata", string(format)+".golden")
+			if *update {
+				if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+					t.Fatalf("writing golden file: %v", err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("reading golden file: %v", err)
+			}
+			if got != string(want) {
+				t.Errorf("output differs from %s; rerun with -update if the change is intended\ngot:\n%s", path, got)
+			}
+		})
+	}
+}
+
+func TestParseInline(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []Span
+	}{
+		{
+			name: "unbalanced star",
+			text: "a *b",
+			want: []Span{{Kind: SpanText, Text: "a *b"}},
+		},
+		{
+			name: "unbalanced double star",
+			text: "*a* and **b",
+			want: []Span{{Kind: SpanItalic, Text: "a"}, {Kind: SpanText, Text: " and **b"}},
+		},
+		{
+			name: "mismatched stars",
+			text: "**bold* text",
+			want: []Span{{Kind: SpanText, Text: "*"}, {Kind: SpanItalic, Text: "bold"}, {Kind: SpanText, Text: " text"}},
+		},
+		{
+			name: "bold runs end at the first closer",
+			text: "**a** and **b**",
+			want: []Span{{Kind: SpanBold, Text: "a"}, {Kind: SpanText, Text: " and "}, {Kind: SpanBold, Text: "b"}},
+		},
+		{
+			name: "nested markup is dropped",
+			text: "**bold *it* more**",
+			want: []Span{{Kind: SpanBold, Text: "bold it more"}},
+		},
+		{
+			name: "underscores inside words",
+			text: "snake_case_name and _it_",
+			want: []Span{{Kind: SpanText, Text: "snake_case_name and "}, {Kind: SpanItalic, Text: "it"}},
+		},
+		{
+			name: "underscore run inside a word",
+			text: "_a_b",
+			want: []Span{{Kind: SpanText, Text: "_a_b"}},
+		},
+		{
+			name: "url with parentheses",
+			text: "[docs](https://en.wikipedia.org/wiki/Go_(programming_language))",
+			want: []Span{{Kind: SpanLink, Text: "docs", URL: "https://en.wikipedia.org/wiki/Go_(programming_language)"}},
+		},
+		{
+			name: "parenthesis after a link",
+			text: "see [x](https://a.com/b) (c)",
+			want: []Span{{Kind: SpanText, Text: "see "}, {Kind: SpanLink, Text: "x", URL: "https://a.com/b"}, {Kind: SpanText, Text: " (c)"}},
+		},
+		{
+			name: "stray closing parenthesis",
+			text: "[x](https://a.com/b))",
+			want: []Span{{Kind: SpanLink, Text: "x", URL: "https://a.com/b"}, {Kind: SpanText, Text: ")"}},
+		},
+		{
+			name: "markup in link text",
+			text: "[a *b*](https://x.io)",
+			want: []Span{{Kind: SpanLink, Text: "a b", URL: "https://x.io"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseInline(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseInline(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMarkdownHeadings(t *testing.T) {
+	tests := []struct {
+		text string
+		want Block
+	}{
+		{
+			text: "## Key *points* ##",
+			want: Block{Kind: BlockHeading, Level: 2, Spans: []Span{{Kind: SpanText, Text: "Key "}, {Kind: SpanItalic, Text: "points"}}},
+		},
+		{
+			text: "### C# tips",
+			want: Block{Kind: BlockHeading, Level: 3, Spans: []Span{{Kind: SpanText, Text: "C# tips"}}},
+		},
+		{
+			text: "##NoSpace",
+			want: Block{Kind: BlockParagraph, Spans: []Span{{Kind: SpanText, Text: "##NoSpace"}}},
+		},
+		{
+			text: "####### seven",
+			want: Block{Kind: BlockParagraph, Spans: []Span{{Kind: SpanText, Text: "####### seven"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			blocks := ParseMarkdown(tt.text)
+			if len(blocks) != 1 || !reflect.DeepEqual(blocks[0], tt.want) {
+				t.Errorf("ParseMarkdown(%q) = %+v, want %+v", tt.text, blocks, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package renderer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// slackMrkdwn renders Slack's mrkdwn, which has no headings or nested
+// styles and escapes only &, < and >
+type slackMrkdwn struct{}
+
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+func (slackMrkdwn) Escape(text string) string  { return slackEscaper.Replace(text) }
+func (slackMrkdwn) Bold(inner string) string   { return "*" + inner + "*" }
+func (slackMrkdwn) Italic(inner string) string { return "_" + inner + "_" }
+func (slackMrkdwn) Code(text string) string    { return "`" + slackEscaper.Replace(text) + "`" }
+func (slackMrkdwn) CodeBlock(text string) string {
+	return "```\n" + slackEscaper.Replace(text) + "\n```"
+}
+func (slackMrkdwn) LineBreak() string             { return "\n" }
+func (slackMrkdwn) Paragraph(inner string) string { return inner }
+func (slackMrkdwn) Quote(inner string) string     { return "> " + inner }
+func (slackMrkdwn) Rule() string                  { return "───" }
+
+// Link uses Slack's <url|text> syntax, in which | cannot be escaped
+func (slackMrkdwn) Link(inner, url string) string {
+	return "<" + url + "|" + strings.ReplaceAll(inner, "|", "¦") + ">"
+}
+
+func (slackMrkdwn) Heading(level int, inner string) string {
+	return "*" + inner + "*"
+}
+
+func (slackMrkdwn) List(ordered bool, items []string) string {
+	return textList(items, func(i int) string {
+		if ordered {
+			return fmt.Sprintf("%d. ", i+1)
+		}
+		return "• "
+	})
+}
+
+func (slackMrkdwn) Join(blocks []string) string {
+	return joinBlocks(blocks, "\n\n")
+}
@@ -0,0 +1,488 @@
+package renderer
+
+import (
+	"strings"
+	"unicode/utf16"
+)
+
+// TelegramMaxMessage is the length limit of a Telegram message, counted in
+// UTF-16 code units
+const TelegramMaxMessage = 4096
+
+// Break levels, from the most to the least preferred place to split
+const (
+	breakSection   = 3
+	breakParagraph = 2
+	breakLine      = 1
+	breakWord      = 0
+	// breakNone marks tokens that are not separators
+	breakNone = -1
+)
+
+// maxEntityDepth bounds how deeply entities nest. Telegram's entities never
+// nest this deep; deeper markers are kept as text so a split reopens a
+// bounded number of entities.
+const maxEntityDepth = 16
+
+// maxReferenceLen is the length of the longest character reference Telegram
+// accepts, e.g. "&#x1F600;"
+const maxReferenceLen = 10
+
+// UTF16Len returns the length of text in UTF-16 code units, the unit of
+// Telegram's limits
+func UTF16Len(text string) int {
+	n := 0
+	for _, r := range text {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
+
+// entity is a formatting entity that a split message closes and the next
+// message reopens
+type entity struct {
+	open  string
+	close string
+}
+
+// splitToken is an atom of a rendered message: text that is never cut, a
+// separator, or an entity marker
+type splitToken struct {
+	text  string
+	width int
+	// level is the break level of a separator, breakNone otherwise
+	level int
+	open  *entity
+	// pops is the number of entities a closing marker closes
+	pops int
+}
+
+// SplitTelegram splits a message rendered as format into messages of at
+// most limit UTF-16 code units. It splits between sections, then between
+// paragraphs, lines and words, and cuts a single oversized word as a last
+// resort. Separators at a split are dropped. Entities open at a split are
+// closed at the end of the message and reopened at the start of the next.
+// Links are never cut, so only a link longer than limit exceeds it.
+func SplitTelegram(format Format, text string, limit int) []string {
+	if UTF16Len(text) <= limit {
+		return []string{text}
+	}
+
+	var tokens []splitToken
+	if format == TelegramHTML {
+		tokens = tokenizeHTML(text)
+	} else {
+		tokens = tokenizeMarkdownV2(text)
+	}
+	s := newSplitter(tokens, limit)
+
+	var messages []string
+	for _, r := range s.split(0, len(tokens), breakSection) {
+		if message := s.render(r[0], r[1]); strings.TrimSpace(message) != "" {
+			messages = append(messages, message)
+		}
+	}
+	return messages
+}
+
+type splitter struct {
+	tokens []splitToken
+	limit  int
+	// offsets[i] is the width of the tokens before token i
+	offsets []int
+	// stacks[i] holds the entities open before token i; reopens[i] and
+	// closes[i] are the widths of their opening and closing markers
+	stacks  [][]*entity
+	reopens []int
+	closes  []int
+}
+
+func newSplitter(tokens []splitToken, limit int) *splitter {
+	s := &splitter{
+		tokens:  tokens,
+		limit:   limit,
+		offsets: make([]int, len(tokens)+1),
+		stacks:  make([][]*entity, len(tokens)+1),
+		reopens: make([]int, len(tokens)+1),
+		closes:  make([]int, len(tokens)+1),
+	}
+	var stack []*entity
+	for i, t := range tokens {
+		s.offsets[i+1] = s.offsets[i] + t.width
+		s.setStack(i, stack)
+		switch {
+		case t.open != nil:
+			stack = append(stack[:len(stack):len(stack)], t.open)
+		case t.pops > 0:
+			n := max(len(stack)-t.pops, 0)
+			stack = stack[:n:n]
+		}
+	}
+	s.setStack(len(tokens), stack)
+	return s
+}
+
+func (s *splitter) setStack(i int, stack []*entity) {
+	s.stacks[i] = stack
+	for _, e := range stack {
+		s.reopens[i] += UTF16Len(e.open)
+		s.closes[i] += UTF16Len(e.close)
+	}
+}
+
+// width is the length of tokens [a, b) as a message of its own
+func (s *splitter) width(a, b int) int {
+	return s.reopens[a] + s.offsets[b] - s.offsets[a] + s.closes[b]
+}
+
+// render returns tokens [a, b) with the entities open at either end
+// reopened and closed
+func (s *splitter) render(a, b int) string {
+	// An entity closing right at the start or opening right at the end
+	// would be empty, which Telegram rejects, so it stays out
+	reopen, closing := s.stacks[a], s.stacks[b]
+	for a < b && s.tokens[a].pops > 0 {
+		reopen = reopen[:max(len(reopen)-s.tokens[a].pops, 0)]
+		a++
+	}
+	for b > a && s.tokens[b-1].open != nil {
+		closing = closing[:len(closing)-1]
+		b--
+	}
+	if a == b {
+		return ""
+	}
+
+	var sb strings.Builder
+	for _, e := range reopen {
+		sb.WriteString(e.open)
+	}
+	for _, t := range s.tokens[a:b] {
+		sb.WriteString(t.text)
+	}
+	for i := len(closing) - 1; i >= 0; i-- {
+		sb.WriteString(closing[i].close)
+	}
+	return sb.String()
+}
+
+// split returns the token ranges of [a, b) split at separators of level or
+// below, packing as many pieces into a message as fit
+func (s *splitter) split(a, b, level int) [][2]int {
+	if s.width(a, b) <= s.limit {
+		return [][2]int{{a, b}}
+	}
+	if level < breakWord {
+		return s.cut(a, b)
+	}
+
+	// Pieces between the separators of this level
+	var pieces [][2]int
+	start := a
+	for i := a; i < b; i++ {
+		if s.tokens[i].level >= level {
+			pieces = append(pieces, [2]int{start, i})
+			start = i + 1
+		}
+	}
+	pieces = append(pieces, [2]int{start, b})
+	if len(pieces) == 1 {
+		return s.split(a, b, level-1)
+	}
+
+	var ranges [][2]int
+	emit := func(r [2]int) {
+		if r[0] < r[1] {
+			ranges = append(ranges, s.split(r[0], r[1], level-1)...)
+		}
+	}
+	current := pieces[0]
+	for _, piece := range pieces[1:] {
+		if s.width(current[0], piece[1]) <= s.limit {
+			current[1] = piece[1]
+			continue
+		}
+		emit(current)
+		current = piece
+	}
+	emit(current)
+	return ranges
+}
+
+// cut splits [a, b) between any two tokens, never right after an entity
+// opens
+func (s *splitter) cut(a, b int) [][2]int {
+	var ranges [][2]int
+	start := a
+	for i := a + 1; i <= b; i++ {
+		if i < b && s.width(start, i+1) <= s.limit {
+			continue
+		}
+		end := i
+		for end > start+1 && s.tokens[end-1].open != nil {
+			end--
+		}
+		ranges = append(ranges, [2]int{start, end})
+		start = end
+		i = end
+	}
+	if start < b {
+		ranges = append(ranges, [2]int{start, b})
+	}
+	return ranges
+}
+
+// separatorToken returns a token for a run of whitespace, inside code or
+// not
+func separatorToken(run string, inCode bool, next string) splitToken {
+	t := splitToken{text: run, width: UTF16Len(run), level: breakWord}
+	switch newlines := strings.Count(run, "\n"); {
+	case newlines >= 2 && strings.HasPrefix(next, telegramRule):
+		t.level = breakSection
+	case newlines >= 2:
+		t.level = breakParagraph
+	case newlines == 1:
+		t.level = breakLine
+	case inCode:
+		// Spaces in code are content, not separators
+		t.level = breakNone
+	}
+	return t
+}
+
+// whitespaceRun returns the leading run of spaces and newlines of text
+func whitespaceRun(text string) string {
+	end := 0
+	for end < len(text) && (text[end] == ' ' || text[end] == '\n') {
+		end++
+	}
+	return text[:end]
+}
+
+// textToken returns a token for text that is never cut
+func textToken(text string) splitToken {
+	return splitToken{text: text, width: UTF16Len(text), level: breakNone}
+}
+
+// firstRune returns the first character of text
+func firstRune(text string) string {
+	for i := range text {
+		if i > 0 {
+			return text[:i]
+		}
+	}
+	return text
+}
+
+// markdownV2Markers are the MarkdownV2 entity markers, longest first
+var markdownV2Markers = []string{"```", "||", "__", "*", "_", "~", "`"}
+
+func tokenizeMarkdownV2(text string) []splitToken {
+	var tokens []splitToken
+	var open []string
+	isOpen := func(marker string) bool {
+		for _, m := range open {
+			if m == marker {
+				return true
+			}
+		}
+		return false
+	}
+	inCode := func() bool { return isOpen("`") || isOpen("```") }
+	// No link starts before noLink, as a failed scan has shown
+	noLink := 0
+
+	for rest := text; rest != ""; {
+		if run := whitespaceRun(rest); run != "" {
+			tokens = append(tokens, separatorToken(run, inCode(), rest[len(run):]))
+			rest = rest[len(run):]
+			continue
+		}
+		if rest[0] == '\\' && len(rest) > 1 {
+			escaped := `\` + firstRune(rest[1:])
+			tokens = append(tokens, textToken(escaped))
+			rest = rest[len(escaped):]
+			continue
+		}
+		if offset := len(text) - len(rest); !inCode() && rest[0] == '[' && offset >= noLink {
+			n, scanned := markdownV2LinkLen(rest)
+			if n > 0 {
+				tokens = append(tokens, textToken(rest[:n]))
+				rest = rest[n:]
+				continue
+			}
+			noLink = offset + scanned
+		}
+
+		marker := ""
+		for _, m := range markdownV2Markers {
+			// Inside code only the closing marker counts
+			if strings.HasPrefix(rest, m) && (!inCode() || m == open[len(open)-1]) {
+				marker = m
+				break
+			}
+		}
+		switch {
+		case marker != "" && isOpen(marker):
+			// A marker closes its entity and any left open inside it
+			pops := 0
+			for len(open) > 0 {
+				last := open[len(open)-1]
+				open = open[:len(open)-1]
+				pops++
+				if last == marker {
+					break
+				}
+			}
+			tokens = append(tokens, splitToken{text: marker, width: UTF16Len(marker), level: breakNone, pops: pops})
+			rest = rest[len(marker):]
+		case marker == "```":
+			// The opening fence takes its language and newline along
+			fence := marker
+			if nl := strings.IndexByte(rest, '\n'); nl >= 0 {
+				fence = rest[:nl+1]
+			}
+			open = append(open, marker)
+			tokens = append(tokens, splitToken{text: fence, width: UTF16Len(fence), level: breakNone,
+				open: &entity{open: fence, close: "\n```"}})
+			rest = rest[len(fence):]
+		case marker != "":
+			open = append(open, marker)
+			tokens = append(tokens, splitToken{text: marker, width: UTF16Len(marker), level: breakNone,
+				open: &entity{open: marker, close: marker}})
+			rest = rest[len(marker):]
+		default:
+			r := firstRune(rest)
+			tokens = append(tokens, textToken(r))
+			rest = rest[len(r):]
+		}
+	}
+	return tokens
+}
+
+// markdownV2LinkLen returns the length of the link at the start of text.
+// When text does not start with a complete link it returns 0 and the length
+// of the prefix of text in which no link can start either.
+func markdownV2LinkLen(text string) (n, scanned int) {
+	i := 1
+	for i < len(text) && text[i] != ']' {
+		if text[i] == '\\' {
+			i++
+		}
+		i++
+	}
+	if i >= len(text) {
+		return 0, len(text)
+	}
+	if !strings.HasPrefix(text[i:], "](") {
+		return 0, i
+	}
+	for i += 2; i < len(text) && text[i] != ')'; i++ {
+		if text[i] == '\\' {
+			i++
+		}
+	}
+	if i >= len(text) {
+		// No later link has a closing parenthesis either
+		return 0, len(text)
+	}
+	return i + 1, 0
+}
+
+// nextIndex finds the next occurrence of sep in text, scanning each part
+// of text at most once as the offset it is asked from grows
+type nextIndex struct {
+	text, sep string
+	// at is the last occurrence found, len(text) when there is none
+	at int
+}
+
+// from returns the index of the next sep relative to offset, or -1
+func (n *nextIndex) from(offset int) int {
+	if n.at < offset {
+		n.at = len(n.text)
+		if i := strings.Index(n.text[offset:], n.sep); i >= 0 {
+			n.at = offset + i
+		}
+	}
+	if n.at == len(n.text) {
+		return -1
+	}
+	return n.at - offset
+}
+
+func tokenizeHTML(text string) []splitToken {
+	var tokens []splitToken
+	var open []string
+	// skipped counts the tags nested too deeply to be entities
+	skipped := 0
+	tagEnds := &nextIndex{text: text, sep: ">", at: -1}
+	linkEnds := &nextIndex{text: text, sep: "</a>", at: -1}
+	inCode := func() bool {
+		for _, name := range open {
+			if name == "code" || name == "pre" {
+				return true
+			}
+		}
+		return false
+	}
+
+	for rest := text; rest != ""; {
+		if run := whitespaceRun(rest); run != "" {
+			tokens = append(tokens, separatorToken(run, inCode(), rest[len(run):]))
+			rest = rest[len(run):]
+			continue
+		}
+		offset := len(text) - len(rest)
+		tagEnd, linkEnd := -1, -1
+		if rest[0] == '<' {
+			tagEnd = tagEnds.from(offset)
+		}
+		if strings.HasPrefix(rest, "<a ") {
+			linkEnd = linkEnds.from(offset)
+		}
+		refEnd := -1
+		if rest[0] == '&' {
+			refEnd = strings.IndexByte(rest[:min(len(rest), maxReferenceLen)], ';')
+		}
+		switch {
+		case linkEnd > 0:
+			link := rest[:linkEnd+len("</a>")]
+			tokens = append(tokens, textToken(link))
+			rest = rest[len(link):]
+		case tagEnd > 0:
+			tag := rest[:tagEnd+1]
+			name := strings.TrimPrefix(strings.TrimSuffix(tag, ">"), "<")
+			if fields := strings.Fields(strings.TrimPrefix(name, "/")); len(fields) > 0 {
+				name = fields[0]
+			}
+			switch {
+			case strings.HasPrefix(tag, "</") && skipped > 0:
+				skipped--
+				tokens = append(tokens, textToken(tag))
+			case strings.HasPrefix(tag, "</"):
+				if len(open) > 0 {
+					open = open[:len(open)-1]
+				}
+				tokens = append(tokens, splitToken{text: tag, width: UTF16Len(tag), level: breakNone, pops: 1})
+			case len(open) >= maxEntityDepth:
+				skipped++
+				tokens = append(tokens, textToken(tag))
+			default:
+				open = append(open, name)
+				tokens = append(tokens, splitToken{text: tag, width: UTF16Len(tag), level: breakNone,
+					open: &entity{open: tag, close: "</" + name + ">"}})
+			}
+			rest = rest[len(tag):]
+		case refEnd > 0 && !strings.ContainsAny(rest[:refEnd], " \n<"):
+			// Character references are never cut
+			tokens = append(tokens, textToken(rest[:refEnd+1]))
+			rest = rest[refEnd+1:]
+		default:
+			r := firstRune(rest)
+			tokens = append(tokens, textToken(r))
+			rest = rest[len(r):]
+		}
+	}
+	return tokens
+}
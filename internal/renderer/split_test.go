@@ -0,0 +1,181 @@
+package renderer
+
+import (
+	"math/rand"
+	"strings"
+	"tech-news-agent/internal/models"
+	"testing"
+	"time"
+)
+
+var splitFormats = []Format{TelegramMarkdownV2, TelegramHTML}
+
+var splitLimits = []int{128, 300, 1000, TelegramMaxMessage}
+
+// markdownPieces are the fragments random summaries are built from
+var markdownPieces = []string{
+	"chips", "rose", "again", "日本語", "🚀", "x_y", "1.5%", "a*b", "<tag>", "&amp;", "#1", "C++",
+	"**bold words**", "*italic*", "__also bold__", "`go vet ./...`",
+	"[docs](https://example.com/a_(b))", "[**strong** link](https://example.com/?q=1&r=2)",
+	"\n", "\n\n", "\n\n- ", "\n\n1. ", "\n\n## ", "\n\n> ", "\n\n```\nfunc main() {\n\tprintln(1)\n}\n```\n\n",
+}
+
+// randomSummary returns a digest whose summary is n random pieces, with
+// an occasional link longer than any limit
+func randomSummary(rng *rand.Rand, n int) *models.NewsSummary {
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		if rng.Intn(200) == 0 {
+			sb.WriteString("[long](https://example.com/" + strings.Repeat("p", 5000) + ")")
+		} else {
+			sb.WriteString(markdownPieces[rng.Intn(len(markdownPieces))])
+		}
+		sb.WriteString(" ")
+	}
+	summary := testSummary()
+	summary.Summary = sb.String()
+	return summary
+}
+
+// checkSplit asserts that every part fits in limit unless it is a single
+// link longer than limit, that every part has balanced entities and that no text
+// is lost or repeated
+func checkSplit(t *testing.T, format Format, text string, limit int) {
+	t.Helper()
+	parts := SplitTelegram(format, text, limit)
+
+	var joined strings.Builder
+	for i, part := range parts {
+		tokens := tokenize(format, part)
+		if n := UTF16Len(part); n > limit && !isSingleLink(tokens) {
+			t.Fatalf("part %d is %d code units, limit %d:\n%s", i, n, limit, part)
+		}
+		if err := entityBalance(tokens); err != "" {
+			t.Fatalf("part %d: %s:\n%s", i, err, part)
+		}
+		joined.WriteString(plainTokens(tokens))
+	}
+	if want := plainTokens(tokenize(format, text)); joined.String() != want {
+		t.Fatalf("parts do not add up to the input text\ngot:  %q\nwant: %q", joined.String(), want)
+	}
+}
+
+func tokenize(format Format, text string) []splitToken {
+	if format == TelegramHTML {
+		return tokenizeHTML(text)
+	}
+	return tokenizeMarkdownV2(text)
+}
+
+// isSingleLink reports whether the only text of tokens, besides entity
+// markers and whitespace, is one link
+func isSingleLink(tokens []splitToken) bool {
+	text := plainTokens(tokens)
+	for _, tok := range tokens {
+		if tok.text == text {
+			return strings.HasPrefix(text, "<a ") || (strings.HasPrefix(text, "[") && len(text) > 1)
+		}
+	}
+	return false
+}
+
+// entityBalance returns a description of the first entity marker that does
+// not close the innermost open entity, or of an entity left open
+func entityBalance(tokens []splitToken) string {
+	var open []*entity
+	for _, tok := range tokens {
+		switch {
+		case tok.open != nil:
+			open = append(open, tok.open)
+		case tok.pops > 0:
+			if len(open) == 0 || tok.pops != 1 || strings.TrimPrefix(open[len(open)-1].close, "\n") != tok.text {
+				return "unbalanced " + tok.text
+			}
+			open = open[:len(open)-1]
+		}
+	}
+	if len(open) > 0 {
+		return "unclosed " + open[len(open)-1].open
+	}
+	return ""
+}
+
+// plainTokens returns the text of tokens without entity markers and
+// whitespace, which a split may drop or repeat
+func plainTokens(tokens []splitToken) string {
+	var sb strings.Builder
+	for _, tok := range tokens {
+		if tok.open == nil && tok.pops == 0 && strings.TrimSpace(tok.text) != "" {
+			sb.WriteString(tok.text)
+		}
+	}
+	return sb.String()
+}
+
+func TestSplitTelegramProperties(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		doc := Build(randomSummary(rng, 20+rng.Intn(400)))
+		for _, format := range splitFormats {
+			text := New(format).Document(doc)
+			for _, limit := range splitLimits {
+				checkSplit(t, format, text, limit)
+			}
+		}
+	}
+}
+
+func FuzzSplitTelegram(f *testing.F) {
+	f.Add("**a** and *b* in `code`\n\n- [x](https://x.io/a_(b))", 128)
+	f.Add(strings.Repeat("word ", 500), 300)
+	f.Add(strings.Repeat("日本語🚀", 800), 1000)
+	f.Add("```\n"+strings.Repeat("line\n", 1000)+"```", TelegramMaxMessage)
+
+	f.Fuzz(func(t *testing.T, markdown string, limit int) {
+		limit = 128 + abs(limit)%TelegramMaxMessage
+		summary := testSummary()
+		summary.Summary = markdown
+		doc := Build(summary)
+		for _, format := range splitFormats {
+			checkSplit(t, format, New(format).Document(doc), limit)
+		}
+	})
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// TestSplitTelegramLargeInput guards against splitting costs that grow
+// faster than the input
+func TestSplitTelegramLargeInput(t *testing.T) {
+	const n = 200_000
+	tests := []struct {
+		name   string
+		format Format
+		text   string
+	}{
+		{"unbroken word", TelegramMarkdownV2, strings.Repeat("a", n)},
+		{"unclosed links", TelegramMarkdownV2, strings.Repeat("[", n)},
+		{"unclosed tags", TelegramHTML, strings.Repeat("<", n)},
+		{"unclosed anchors", TelegramHTML, strings.Repeat("<a ", n/3)},
+		{"stray ampersands", TelegramHTML, strings.Repeat("&a", n/2)},
+		{"deep nesting", TelegramHTML, strings.Repeat("<b>", n/6) + "a" + strings.Repeat("</b>", n/6)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			parts := SplitTelegram(tt.format, tt.text, TelegramMaxMessage)
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("splitting %d bytes took %v", len(tt.text), elapsed)
+			}
+			if len(parts) < 2 {
+				t.Errorf("got %d parts", len(parts))
+			}
+		})
+	}
+}
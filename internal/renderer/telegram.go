@@ -0,0 +1,157 @@
+package renderer
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// telegramRule separates the sections of a Telegram digest, which has no
+// headings
+const telegramRule = "━━━━━━━━━━━━━━━━━"
+
+// telegramMarkdownV2 renders Telegram's MarkdownV2, in which every special
+// character outside an entity must be escaped
+type telegramMarkdownV2 struct{}
+
+var (
+	markdownV2Escaper = strings.NewReplacer(
+		`\`, `\\`, "_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`,
+		"~", `\~`, "`", "\\`", ">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`, "=", `\=`,
+		"|", `\|`, "{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`)
+	// Inside code only ` and \ are special; inside link URLs ) and \
+	markdownV2CodeEscaper = strings.NewReplacer(`\`, `\\`, "`", "\\`")
+	markdownV2URLEscaper  = strings.NewReplacer(`\`, `\\`, ")", `\)`)
+)
+
+func (telegramMarkdownV2) Escape(text string) string     { return markdownV2Escaper.Replace(text) }
+func (telegramMarkdownV2) Bold(inner string) string      { return "*" + inner + "*" }
+func (telegramMarkdownV2) Italic(inner string) string    { return "_" + inner + "_" }
+func (telegramMarkdownV2) LineBreak() string             { return "\n" }
+func (telegramMarkdownV2) Paragraph(inner string) string { return inner }
+func (telegramMarkdownV2) Quote(inner string) string     { return ">" + inner }
+func (telegramMarkdownV2) Rule() string                  { return telegramRule }
+
+func (telegramMarkdownV2) Code(text string) string {
+	return "`" + markdownV2CodeEscaper.Replace(text) + "`"
+}
+
+func (telegramMarkdownV2) CodeBlock(text string) string {
+	return "```\n" + markdownV2CodeEscaper.Replace(text) + "\n```"
+}
+
+func (telegramMarkdownV2) Link(inner, url string) string {
+	return "[" + inner + "](" + markdownV2URLEscaper.Replace(url) + ")"
+}
+
+func (telegramMarkdownV2) Heading(level int, inner string) string {
+	return telegramHeading(level, "*"+inner+"*")
+}
+
+func (telegramMarkdownV2) List(ordered bool, items []string) string {
+	return textList(items, func(i int) string {
+		if ordered {
+			return fmt.Sprintf(`%d\. `, i+1)
+		}
+		return "• "
+	})
+}
+
+func (telegramMarkdownV2) Join(blocks []string) string {
+	return joinBlocks(blocks, "\n\n")
+}
+
+// telegramHTML renders the HTML subset Telegram supports, which needs only
+// &, < and > escaped
+type telegramHTML struct{}
+
+var telegramHTMLEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+func (telegramHTML) Escape(text string) string  { return telegramHTMLEscaper.Replace(text) }
+func (telegramHTML) Bold(inner string) string   { return "<b>" + inner + "</b>" }
+func (telegramHTML) Italic(inner string) string { return "<i>" + inner + "</i>" }
+func (telegramHTML) Code(text string) string {
+	return "<code>" + telegramHTMLEscaper.Replace(text) + "</code>"
+}
+func (telegramHTML) CodeBlock(text string) string {
+	return "<pre>" + telegramHTMLEscaper.Replace(text) + "</pre>"
+}
+func (telegramHTML) LineBreak() string             { return "\n" }
+func (telegramHTML) Paragraph(inner string) string { return inner }
+func (telegramHTML) Quote(inner string) string     { return "<blockquote>" + inner + "</blockquote>" }
+func (telegramHTML) Rule() string                  { return telegramRule }
+
+func (telegramHTML) Link(inner, url string) string {
+	return fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(url), inner)
+}
+
+func (telegramHTML) Heading(level int, inner string) string {
+	return telegramHeading(level, "<b>"+inner+"</b>")
+}
+
+func (telegramHTML) List(ordered bool, items []string) string {
+	return textList(items, func(i int) string {
+		if ordered {
+			return fmt.Sprintf("%d. ", i+1)
+		}
+		return "• "
+	})
+}
+
+func (telegramHTML) Join(blocks []string) string {
+	return joinBlocks(blocks, "\n\n")
+}
+
+// telegramHeading puts a rule above section headings
+func telegramHeading(level int, bold string) string {
+	if level == 2 {
+		return telegramRule + "\n" + bold
+	}
+	return bold
+}
+
+var (
+	telegramLinkPattern = regexp.MustCompile(`<a href="([^"]*)">(.*?)</a>`)
+	telegramTagPattern  = regexp.MustCompile(`</?[a-z][^>]*>`)
+)
+
+// TelegramPlain strips the markup from a message rendered as format, so it
+// can be resent without a parse mode when Telegram rejects its entities.
+// Links keep their URL after the text.
+func TelegramPlain(format Format, text string) string {
+	if format == TelegramHTML {
+		text = telegramLinkPattern.ReplaceAllString(text, "$2 &lt;$1&gt;")
+		return html.UnescapeString(telegramTagPattern.ReplaceAllString(text, ""))
+	}
+
+	runes := []rune(text)
+	var sb strings.Builder
+	inCode := false
+	for i := 0; i < len(runes); i++ {
+		switch c := runes[i]; {
+		case c == '\\' && i+1 < len(runes):
+			i++
+			sb.WriteRune(runes[i])
+		case c == '`':
+			inCode = !inCode
+		case inCode:
+			sb.WriteRune(c)
+		case c == '*' || c == '_' || c == '~' || c == '|' || c == '[':
+			// Entity markers
+		case c == ']' && i+1 < len(runes) && runes[i+1] == '(':
+			// The link URL follows its text, up to an unescaped ")"
+			var url strings.Builder
+			for i += 2; i < len(runes) && runes[i] != ')'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				url.WriteRune(runes[i])
+			}
+			sb.WriteString(" <" + url.String() + ">")
+		default:
+			sb.WriteRune(c)
+		}
+	}
+	return sb.String()
+}
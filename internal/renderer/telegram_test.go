@@ -0,0 +1,32 @@
+package renderer
+
+import "testing"
+
+func TestTelegramPlain(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		text   string
+		want   string
+	}{
+		{"markdown escapes", TelegramMarkdownV2, `Revenue \+12\.5% \(again\)\!`, "Revenue +12.5% (again)!"},
+		{"markdown entities", TelegramMarkdownV2, `*bold* _it_ __under__ ~gone~ ||spoiler||`, "bold it under gone spoiler"},
+		{"markdown escaped markers", TelegramMarkdownV2, `snake\_case a\*b`, "snake_case a*b"},
+		{"markdown link", TelegramMarkdownV2, `[Go\_lang](https://go.dev/a_\(b\))`, "Go_lang <https://go.dev/a_(b)>"},
+		{"markdown code keeps markers", TelegramMarkdownV2, "`a*b_c` and *d*", "a*b_c and d"},
+		{"markdown code block", TelegramMarkdownV2, "```\nfmt.Println(\"*\")\n```", "\nfmt.Println(\"*\")\n"},
+		{"unbalanced markdown", TelegramMarkdownV2, `*open [text`, "open text"},
+		{"html tags", TelegramHTML, "<b>bold</b> <i>it</i> <code>x</code>", "bold it x"},
+		{"html entities", TelegramHTML, "&lt;tag&gt; &amp; 3 &gt; 2", "<tag> & 3 > 2"},
+		{"html link", TelegramHTML, `<a href="https://x.io/?a=1&amp;b=2"><b>Docs</b></a>`, "Docs <https://x.io/?a=1&b=2>"},
+		{"html pre", TelegramHTML, "<pre><code>if a &lt; b {}</code></pre>", "if a < b {}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TelegramPlain(tt.format, tt.text); got != tt.want {
+				t.Errorf("TelegramPlain(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -2,10 +2,17 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"tech-news-agent/internal/config"
+	"tech-news-agent/internal/i18n"
+	"tech-news-agent/internal/models"
+	"tech-news-agent/internal/renderer"
 	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 // NewsAgent orchestrates the entire news collection and analysis workflow
@@ -13,44 +20,160 @@ type NewsAgent struct {
 	config    *config.Config
 	collector *NewsCollector
 	analyzer  *AIAnalyzer
-	notifier  *TelegramNotifier
+	enricher  *ArticleEnricher
+	verifier  *GroundingVerifier
+	topics    *TopicClusterer
+	market    *MarketAnalyzer
+	watchlist *Watchlist
+	history   *HistoryStore
+	qa        *ArchiveQA
+	digests   *DigestArchive
+	embedder  EmbeddingProvider
+	usage     *UsageTracker
+	personas  []*personaTarget
+	notifiers []Notifier
 	logger    *log.Logger
 }
 
+// personaTarget routes the digest of a persona to its recipients
+type personaTarget struct {
+	persona   config.Persona
+	notifiers []Notifier
+}
+
 // NewNewsAgent creates a new news agent instance
 func NewNewsAgent(cfg *config.Config, logger *log.Logger) (*NewsAgent, error) {
-	collector := NewNewsCollector(cfg.NewsAPIKey, cfg.MaxNewsArticles)
+	collector := NewNewsCollector(cfg.NewsAPIKey, cfg.MaxNewsArticles, cfg.NewsLanguages, cfg.ArticleLanguages)
+
+	// Questions can be asked before the first run, so the month's spend is
+	// loaded up front
+	usage := NewUsageTracker(cfg)
+	if err := usage.LoadMonthToDate(); err != nil {
+		logger.Printf("Could not read usage ledger: %v", err)
+	}
 
-	analyzer, err := NewAIAnalyzer(cfg.GeminiAPIKey, cfg.GeminiModel)
+	analyzer, err := NewAIAnalyzer(cfg, usage, logger)
 	if err != nil {
 		return nil, fmt.Errorf("initializing AI analyzer: %w", err)
 	}
 
-	notifier, err := NewTelegramNotifier(cfg.TelegramBotToken, cfg.TelegramChatID)
-	if err != nil {
-		return nil, fmt.Errorf("initializing Telegram notifier: %w", err)
+	// One bot is shared by every Telegram chat
+	var bot *tgbotapi.BotAPI
+	var personas []*personaTarget
+	var notifiers []Notifier
+	for _, persona := range cfg.Personas {
+		target := &personaTarget{persona: persona}
+		for _, chat := range persona.Chats {
+			if bot == nil {
+				// The library ignores contexts, so a client timeout keeps a
+				// hanging request from blocking the whole fan-out
+				client := &http.Client{Timeout: 30 * time.Second}
+				if bot, err = tgbotapi.NewBotAPIWithClient(cfg.TelegramBotToken, tgbotapi.APIEndpoint, client); err != nil {
+					analyzer.Close()
+					return nil, fmt.Errorf("creating Telegram bot: %w", err)
+				}
+			}
+			notifier := NewTelegramNotifier(cfg, bot, chat, logger)
+			target.notifiers = append(target.notifiers, notifier)
+			notifiers = append(notifiers, notifier)
+		}
+		for _, dest := range persona.Destinations {
+			notifier, err := NewDestinationNotifier(cfg, dest)
+			if err != nil {
+				analyzer.Close()
+				return nil, fmt.Errorf("initializing %s notifier: %w", dest.Kind, err)
+			}
+			target.notifiers = append(target.notifiers, notifier)
+			notifiers = append(notifiers, notifier)
+		}
+		personas = append(personas, target)
+	}
+
+	var enricher *ArticleEnricher
+	if cfg.EnrichArticles {
+		enricher = NewArticleEnricher(analyzer, cfg.EnrichmentParams, cfg.EnrichConcurrency, cfg.EnrichRatePerMinute, cfg.DataDir, logger)
+	}
+
+	var verifier *GroundingVerifier
+	if cfg.GroundingMode != GroundingOff {
+		verifier = NewGroundingVerifier(cfg.GroundingMode, cfg.GroundingLLMVerify, analyzer, cfg.EnrichmentParams, logger)
+	}
+
+	// One embedding provider is shared by topic clustering and archive search
+	var embedder EmbeddingProvider
+	if cfg.TopicClustering || cfg.QASearch == SearchHybrid {
+		embedder, err = NewEmbeddingProvider(cfg)
+		if err != nil {
+			analyzer.Close()
+			return nil, fmt.Errorf("initializing embedding provider: %w", err)
+		}
 	}
 
+	var topics *TopicClusterer
+	if cfg.TopicClustering {
+		topics = NewTopicClusterer(embedder, analyzer, cfg.EnrichmentParams, cfg.TopicClusters, logger)
+	}
+
+	var market *MarketAnalyzer
+	if cfg.MarketPulse {
+		market = NewMarketAnalyzer(analyzer, cfg.EnrichmentParams, cfg.Companies, logger)
+	}
+
+	var watchlist *Watchlist
+	if len(cfg.Watchlist) > 0 {
+		watchlist = NewWatchlist(cfg.Watchlist)
+	}
+
+	history := NewHistoryStore(cfg.DataDir)
+
+	var searchEmbedder EmbeddingProvider
+	if cfg.QASearch == SearchHybrid {
+		searchEmbedder = embedder
+	}
+	qa := NewArchiveQA(history, analyzer, cfg.EnrichmentParams, searchEmbedder, cfg.QATopK, logger)
+
 	return &NewsAgent{
 		config:    cfg,
 		collector: collector,
 		analyzer:  analyzer,
-		notifier:  notifier,
+		enricher:  enricher,
+		verifier:  verifier,
+		topics:    topics,
+		market:    market,
+		watchlist: watchlist,
+		history:   history,
+		qa:        qa,
+		digests:   NewDigestArchive(cfg.DataDir, cfg.PublicBaseURL),
+		embedder:  embedder,
+		usage:     usage,
+		personas:  personas,
+		notifiers: notifiers,
 		logger:    logger,
 	}, nil
 }
 
 // Close cleans up resources
 func (na *NewsAgent) Close() error {
-	return na.analyzer.Close()
+	var errs []error
+	if na.embedder != nil {
+		errs = append(errs, na.embedder.Close())
+	}
+	errs = append(errs, na.analyzer.Close())
+	return errors.Join(errs...)
 }
 
-// Run executes the complete workflow
-func (na *NewsAgent) Run(ctx context.Context) error {
+// Run executes the complete workflow. Digests reaching only some of their
+// destinations do not fail the run; the failures are listed in the result.
+func (na *NewsAgent) Run(ctx context.Context) (*RunResult, error) {
 	na.logger.Println("Starting weekly news collection and analysis...")
 
+	if err := na.usage.StartRun(); err != nil {
+		na.logger.Printf("Could not read usage ledger: %v", err)
+	}
+	defer na.finishUsage()
+
 	// Step 1: Collect news
-	na.logger.Println("Step 1/3: Collecting news articles...")
+	na.logger.Println("Step 1/5: Collecting news articles...")
 	articles, err := na.collector.FetchWeeklyNews(na.config.NewsCategories)
 	if err != nil {
 		na.logger.Printf("Error collecting news: %v", err)
@@ -59,49 +182,326 @@ func (na *NewsAgent) Run(ctx context.Context) error {
 		articles = na.collector.GetMockNews()
 	}
 	na.logger.Printf("Collected %d articles", len(articles))
+	result := &RunResult{Articles: len(articles)}
 
-	// Step 2: Analyze with AI
-	na.logger.Println("Step 2/3: Analyzing articles with Gemini AI...")
+	// Step 2: Per-article enrichment (optional)
+	if na.enricher != nil {
+		na.logger.Println("Step 2/5: Enriching articles with TL;DRs and classification...")
+		enrichCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+		articles = na.enricher.Enrich(enrichCtx, articles)
+		cancel()
+	} else {
+		na.logger.Println("Step 2/5: Article enrichment disabled, skipping")
+	}
+
+	// Sentiment and company annotations for business stories (optional)
+	if na.market != nil {
+		na.logger.Println("Annotating business articles with market signals...")
+		marketCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+		articles = na.market.Annotate(marketCtx, articles)
+		cancel()
+	}
+
+	var opts AnalysisOptions
+
+	// Watched entities are matched against every collected article, before
+	// personas narrow them down
+	if na.watchlist != nil {
+		opts.Watchlist = na.watchlist.Match(articles)
+		na.logger.Printf("👀 Watchlist: %d article mentions across %d entities",
+			watchlistMentions(opts.Watchlist), len(opts.Watchlist))
+	}
+
+	// Step 3: Topic clustering (optional)
+	if na.topics != nil {
+		na.logger.Println("Step 3/5: Clustering articles into topics...")
+		clusterCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+		topics, err := na.topics.Cluster(clusterCtx, articles)
+		cancel()
+		if err != nil {
+			na.logger.Printf("⚠️ Topic clustering failed, using model topics: %v", err)
+		} else {
+			na.logger.Printf("Detected %d topics", len(topics))
+			opts.Topics = topics
+		}
+	} else {
+		na.logger.Println("Step 3/5: Topic clustering disabled, skipping")
+	}
+
+	// Compare topic coverage with the previous run
+	topicCounts := TopicCounts(articles)
+	topicSource := TopicSource(articles)
+	previous, err := na.history.Previous(time.Now())
+	if err != nil {
+		na.logger.Printf("⚠️ Could not load previous run, skipping trends: %v", err)
+	}
+	if previous != nil && previous.TopicSource != topicSource {
+		na.logger.Printf("Previous run counted topics from %q, this run from %q; skipping trends",
+			previous.TopicSource, topicSource)
+	}
+	opts.Trends = ComputeTrends(previous, topicCounts, topicSource)
+	if opts.Trends != nil {
+		na.logger.Printf("Trends vs %s: %d changed, %d emerging, %d fading topics",
+			opts.Trends.PreviousPeriodEnd.Format("2006-01-02"),
+			len(opts.Trends.Changes), len(opts.Trends.Emerging), len(opts.Trends.Fading))
+	}
+
+	// Step 4: Analyze with AI, once per persona
+	na.logger.Printf("Step 4/5: Analyzing articles with Gemini AI for %d persona(s)...", len(na.personas))
+
+	var historySummary *models.NewsSummary
+	for _, target := range na.personas {
+		summary, deliveries := na.runPersona(ctx, target, articles, opts)
+		result.Deliveries = append(result.Deliveries, deliveries...)
+		if summary != nil && historySummary == nil {
+			historySummary = summary
+		}
+	}
+
+	if historySummary != nil {
+		if err := na.history.Save(&models.HistoryEntry{
+			GeneratedAt: historySummary.GeneratedAt,
+			PeriodStart: historySummary.PeriodStart,
+			PeriodEnd:   historySummary.PeriodEnd,
+			Summary:     historySummary.Summary,
+			Topics:      opts.Topics,
+			TopicCounts: topicCounts,
+			TopicSource: topicSource,
+			Articles:    articles,
+			MarketPulse: BuildMarketPulse(articles),
+		}); err != nil {
+			na.logger.Printf("⚠️ Failed to store run history: %v", err)
+		}
+	}
+
+	if err := result.deliveryError(); err != nil {
+		return result, err
+	}
+	if failed := result.Failed(); len(failed) > 0 {
+		na.logger.Printf("⚠️ Weekly news summary delivered to %d of %d destinations", result.Delivered(), len(result.Deliveries))
+		for _, d := range failed {
+			na.logger.Printf("   %s (%s): %v", d.Destination, d.Persona, d.Err)
+		}
+		return result, nil
+	}
+
+	na.logger.Println("✅ Weekly news summary sent successfully!")
+	return result, nil
+}
+
+// runPersona analyzes the articles for one persona and delivers the digest
+// to its recipients. The English summary is returned even if sending fails.
+func (na *NewsAgent) runPersona(ctx context.Context, target *personaTarget, articles []models.Article, opts AnalysisOptions) (*models.NewsSummary, []DeliveryResult) {
+	persona := target.persona
+
+	articles = FilterByCategory(articles, persona.Categories)
+	if len(articles) == 0 {
+		na.logger.Printf("⚠️ No articles match the categories of persona %s, skipping", persona.Name)
+		return nil, nil
+	}
+	opts.Persona = persona
+	opts.Topics = filterTopics(opts.Topics, articles)
+	opts.MarketPulse = BuildMarketPulse(articles)
+	for _, notifier := range target.notifiers {
+		if budget := notifier.MaxChars(); budget > 0 && (opts.MaxChars == 0 || budget < opts.MaxChars) {
+			opts.MaxChars = budget
+		}
+	}
+
+	na.logger.Printf("Analyzing %d articles for persona %s...", len(articles), persona.Name)
 
 	// Create a context with timeout for AI analysis
 	aiCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
 	defer cancel()
 
-	summary, err := na.analyzer.AnalyzeNews(aiCtx, articles)
+	summary, err := na.analyzer.AnalyzeNews(aiCtx, articles, opts)
 	if err != nil {
 		errMsg := fmt.Sprintf("AI analysis failed: %v", err)
 		na.logger.Println(errMsg)
-		if notifyErr := na.notifier.SendError(errMsg); notifyErr != nil {
-			na.logger.Printf("Failed to send error notification: %v", notifyErr)
+		na.notifyError(ctx, target.notifiers, errMsg)
+		return nil, failAll(persona.Name, target.notifiers, fmt.Errorf("analyzing news: %w", err))
+	}
+	na.logger.Printf("Analysis complete for persona %s", persona.Name)
+
+	if na.verifier != nil {
+		na.verifier.Verify(ctx, summary, articles)
+		na.logger.Printf("Grounding score: %.0f%% (%d unsupported claims)",
+			*summary.GroundingScore*100, len(summary.UnsupportedClaims))
+	}
+
+	summaries := na.localize(ctx, summary, target.notifiers)
+
+	// Keep the full version of every language available for shortened messages
+	fullURLs := make(map[string]string)
+	for language, s := range summaries {
+		url, err := na.digests.Save(s)
+		if err != nil {
+			na.logger.Printf("⚠️ Failed to store full digest: %v", err)
+			continue
 		}
-		return fmt.Errorf("analyzing news: %w", err)
+		fullURLs[language] = url
 	}
-	na.logger.Println("Analysis complete")
 
-	// Step 3: Send via Telegram
-	na.logger.Println("Step 3/3: Sending summary via Telegram...")
-	if err := na.notifier.SendSummary(summary); err != nil {
-		errMsg := fmt.Sprintf("Failed to send Telegram notification: %v", err)
-		na.logger.Println(errMsg)
-		return fmt.Errorf("sending notification: %w", err)
+	if na.config.DigestCostFooter {
+		report := na.usage.Report()
+		for _, s := range summaries {
+			s.Usage = &report
+		}
 	}
 
-	na.logger.Println("✅ Weekly news summary sent successfully!")
-	return nil
+	// Shorten per destination first; compression results are shared
+	fitted := make(map[string]*models.NewsSummary)
+	messages := make([]*models.NewsSummary, len(target.notifiers))
+	for i, notifier := range target.notifiers {
+		messages[i] = na.fitToBudget(ctx, summaries[notifier.Language()], fullURLs[notifier.Language()], notifier, fitted)
+	}
+
+	// Step 5: Deliver to every destination at once
+	na.logger.Printf("Step 5/5: Sending %s summary to %d destination(s)...", persona.Name, len(target.notifiers))
+	deliveries := fanOut(ctx, persona.Name, target.notifiers, func(ctx context.Context, i int, notifier Notifier) error {
+		if err := notifier.SendSummary(ctx, messages[i]); err != nil {
+			na.logger.Printf("Failed to send summary to %s: %v", notifier.Destination(), err)
+			return fmt.Errorf("sending summary: %w", err)
+		}
+		return nil
+	})
+
+	return summary, deliveries
+}
+
+// fitToBudget returns summary shortened so the rendered message fits the
+// notifier's budget, linking to the full version at fullURL. Results are
+// shared between recipients with the same language whose budget and
+// format leave the same room for the summary.
+func (na *NewsAgent) fitToBudget(ctx context.Context, summary *models.NewsSummary, fullURL string, notifier Notifier, fitted map[string]*models.NewsSummary) *models.NewsSummary {
+	budget := notifier.MaxChars()
+	length := messageLen(notifier, notifier.FormatMessage(summary))
+	if budget == 0 || length <= budget {
+		return summary
+	}
+
+	out := *summary
+	out.FullURL = fullURL
+
+	// Everything but the generated summary stays as rendered, link included
+	overhead := messageLen(notifier, notifier.FormatMessage(&out)) - messageLen(notifier, summary.Summary)
+	target := max(budget-overhead, 200)
+
+	// The overhead depends on the format, so the target and not the budget
+	// decides whether another recipient's result fits
+	key := fmt.Sprintf("%s/%d", summary.Language, target)
+	if s, ok := fitted[key]; ok {
+		return s
+	}
+
+	na.logger.Printf("Digest is %d characters, compressing summary to %d for a %d character budget", length, target, budget)
+	compressCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	text, err := na.analyzer.CompressSummary(compressCtx, summary.Summary, summary.Language, target)
+	cancel()
+	if err != nil {
+		na.logger.Printf("⚠️ Summary compression failed: %v", err)
+	}
+
+	out.Summary = text
+	fitted[key] = &out
+	return &out
+}
+
+// messageLen measures a rendered message in the unit of the notifier's
+// budget: UTF-16 code units for Telegram, characters elsewhere
+func messageLen(notifier Notifier, message string) int {
+	if _, ok := notifier.(*TelegramNotifier); ok {
+		return renderer.UTF16Len(message)
+	}
+	return runeLen(message)
+}
+
+// localize returns the summary for every recipient language. The analysis
+// runs once in English and only the generated sections are translated;
+// if a translation fails the recipient gets the English digest.
+func (na *NewsAgent) localize(ctx context.Context, summary *models.NewsSummary, notifiers []Notifier) map[string]*models.NewsSummary {
+	summaries := map[string]*models.NewsSummary{summary.Language: summary}
+
+	for _, notifier := range notifiers {
+		language := notifier.Language()
+		if _, ok := summaries[language]; ok {
+			continue
+		}
+
+		na.logger.Printf("Translating summary into %s...", i18n.Name(language))
+		translateCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+		translated, err := na.analyzer.TranslateSummary(translateCtx, summary, language)
+		cancel()
+		if err != nil {
+			na.logger.Printf("⚠️ Translation into %s failed, sending English: %v", i18n.Name(language), err)
+			translated = summary
+		}
+		summaries[language] = translated
+	}
+
+	return summaries
+}
+
+// notifyError sends an error notification to the given recipients
+func (na *NewsAgent) notifyError(ctx context.Context, notifiers []Notifier, errMsg string) {
+	fanOut(ctx, "", notifiers, func(ctx context.Context, _ int, notifier Notifier) error {
+		if err := notifier.SendError(ctx, errMsg); err != nil {
+			na.logger.Printf("Failed to send error notification to %s: %v", notifier.Destination(), err)
+		}
+		return nil
+	})
+}
+
+// finishUsage logs the run's token usage and appends it to the ledger
+func (na *NewsAgent) finishUsage() {
+	report, err := na.usage.FinishRun()
+	if err != nil {
+		na.logger.Printf("Failed to record usage: %v", err)
+	}
+
+	na.logger.Printf("💰 LLM usage: %d calls (%d cached), %d prompt + %d completion tokens, $%.4f",
+		report.Calls, report.CachedCalls, report.PromptTokens, report.CompletionTokens, report.CostUSD)
+	for model, usage := range report.ByModel {
+		na.logger.Printf("   %s: %d calls, %d + %d tokens, $%.4f",
+			model, usage.Calls, usage.PromptTokens, usage.CompletionTokens, usage.CostUSD)
+	}
+}
+
+// Ask answers a question from the articles archived since the given time
+func (na *NewsAgent) Ask(ctx context.Context, question string, since time.Time) (*models.Answer, error) {
+	return na.qa.Ask(ctx, question, since)
+}
+
+// DigestDir returns the directory holding the full digest pages
+func (na *NewsAgent) DigestDir() string {
+	return na.digests.Dir()
 }
 
 // TestRun runs the agent immediately for testing
-func (na *NewsAgent) TestRun() error {
+func (na *NewsAgent) TestRun() (*RunResult, error) {
 	ctx := context.Background()
 	return na.Run(ctx)
 }
 
-// TestConnection tests all service connections
+// TestConnection tests all destinations at once and reports every failure
 func (na *NewsAgent) TestConnection() error {
-	na.logger.Println("Testing Telegram connection...")
-	if err := na.notifier.TestConnection(); err != nil {
-		return fmt.Errorf("Telegram connection test failed: %w", err)
+	na.logger.Printf("Testing %d destination(s)...", len(na.notifiers))
+	results := fanOut(context.Background(), "", na.notifiers, func(ctx context.Context, _ int, notifier Notifier) error {
+		return notifier.TestConnection(ctx)
+	})
+
+	var errs []error
+	for _, r := range results {
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("connection test failed for %s: %w", r.Destination, r.Err))
+			continue
+		}
+		na.logger.Printf("✅ %s", r.Destination)
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
 	}
+
 	na.logger.Println("✅ All connections successful")
 	return nil
 }
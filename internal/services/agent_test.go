@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"tech-news-agent/internal/config"
+	"tech-news-agent/internal/models"
+	"testing"
+)
+
+// shorteningLLM answers compression prompts with a text of the requested
+// length and counts the calls
+type shorteningLLM struct {
+	calls int
+}
+
+func (l *shorteningLLM) Name() string                                 { return "fake" }
+func (l *shorteningLLM) Model() string                                { return "fake" }
+func (l *shorteningLLM) ValidateParams(config.GenerationParams) error { return nil }
+func (l *shorteningLLM) Close() error                                 { return nil }
+
+func (l *shorteningLLM) Generate(ctx context.Context, prompt string, params config.GenerationParams) (*LLMResult, error) {
+	l.calls++
+	var n int
+	if _, err := fmt.Sscanf(prompt, "Shorten the following weekly tech news digest to at most %d", &n); err != nil {
+		return nil, err
+	}
+	return &LLMResult{Text: strings.Repeat("s", n)}, nil
+}
+
+// framedNotifier renders a digest as the summary inside a fixed frame,
+// standing in for formats with different markup overhead
+type framedNotifier struct {
+	Notifier
+	frame int
+}
+
+func (n framedNotifier) Language() string { return "en" }
+func (n framedNotifier) MaxChars() int    { return 1000 }
+
+func (n framedNotifier) FormatMessage(summary *models.NewsSummary) string {
+	return strings.Repeat("#", n.frame) + summary.Summary + summary.FullURL
+}
+
+func TestFitToBudgetPerFormat(t *testing.T) {
+	llm := &shorteningLLM{}
+	na := &NewsAgent{
+		analyzer: &AIAnalyzer{
+			providers: []LLMProvider{llm},
+			usage:     NewUsageTracker(&config.Config{DataDir: t.TempDir()}),
+			logger:    log.New(io.Discard, "", 0),
+		},
+		logger: log.New(io.Discard, "", 0),
+	}
+	summary := &models.NewsSummary{Language: "en", Summary: strings.Repeat("w", 2000)}
+	const fullURL = "https://x.io/d"
+
+	fitted := make(map[string]*models.NewsSummary)
+	notifiers := []framedNotifier{{frame: 100}, {frame: 400}, {frame: 100}}
+	for _, notifier := range notifiers {
+		out := na.fitToBudget(context.Background(), summary, fullURL, notifier, fitted)
+		if n := runeLen(notifier.FormatMessage(out)); n > notifier.MaxChars() {
+			t.Errorf("message with a %d character frame is %d characters, budget %d", notifier.frame, n, notifier.MaxChars())
+		}
+		if out.FullURL != fullURL {
+			t.Errorf("full URL = %q, want %q", out.FullURL, fullURL)
+		}
+	}
+	// The two notifiers with the same frame share one compression
+	if llm.calls != 2 {
+		t.Errorf("compressed %d times, want 2", llm.calls)
+	}
+}
+
+func TestRunDeliveryError(t *testing.T) {
+	sent := DeliveryResult{Persona: "default", Destination: "telegram:1"}
+	failed := DeliveryResult{Persona: "default", Destination: "slack:C1", Err: errors.New("channel_not_found")}
+
+	tests := []struct {
+		name       string
+		deliveries []DeliveryResult
+		wantErr    string
+	}{
+		// Every persona was skipped, e.g. for lack of matching articles
+		{"nothing to deliver", nil, "no digest delivered: no destination had a digest to send"},
+		{"all failed", []DeliveryResult{failed}, "no digest delivered: slack:C1 (default): channel_not_found"},
+		{"partly delivered", []DeliveryResult{sent, failed}, ""},
+		{"delivered", []DeliveryResult{sent}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&RunResult{Deliveries: tt.deliveries}).deliveryError()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("deliveryError = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("deliveryError = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
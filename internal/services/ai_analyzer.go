@@ -2,114 +2,502 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"strings"
+	"tech-news-agent/internal/config"
+	"tech-news-agent/internal/i18n"
 	"tech-news-agent/internal/models"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/generative-ai-go/genai"
 	"google.golang.org/api/option"
 )
 
-// AIAnalyzer handles AI-powered news analysis using Gemini
+// AIAnalyzer handles AI-powered news analysis using an ordered chain of LLM providers
 type AIAnalyzer struct {
-	client *genai.Client
-	model  string
+	providers      []LLMProvider
+	params         config.GenerationParams
+	providerParams map[string]config.ParamOverrides
+	maxRetries     int
+	topN           int
+	usage          *UsageTracker
+	budgetAction   string
+	logger         *log.Logger
 }
 
-// NewAIAnalyzer creates a new AI analyzer instance
-func NewAIAnalyzer(apiKey, modelName string) (*AIAnalyzer, error) {
-	ctx := context.Background()
-	client, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
-	if err != nil {
-		return nil, fmt.Errorf("creating Gemini client: %w", err)
+// NewAIAnalyzer creates a new AI analyzer instance. The configured Gemini model
+// is tried first, followed by the fallback models and a local Ollama model.
+func NewAIAnalyzer(cfg *config.Config, usage *UsageTracker, logger *log.Logger) (*AIAnalyzer, error) {
+	var providers []LLMProvider
+
+	modelNames := append([]string{cfg.GeminiModel}, cfg.GeminiFallbackModels...)
+	for _, modelName := range modelNames {
+		provider, err := NewGeminiProvider(cfg.GeminiAPIKey, modelName)
+		if err != nil {
+			closeProviders(providers)
+			return nil, err
+		}
+		providers = append(providers, provider)
+	}
+
+	if cfg.OllamaURL != "" {
+		providers = append(providers, NewOllamaProvider(cfg.OllamaURL, cfg.OllamaModel))
+	}
+
+	if cfg.LLMCacheDir != "" {
+		cache, err := NewLLMCache(cfg.LLMCacheDir, cfg.LLMCacheTTL)
+		if err != nil {
+			closeProviders(providers)
+			return nil, err
+		}
+		for i, provider := range providers {
+			providers[i] = withCache(provider, cache, cfg.LLMCacheMode)
+		}
+	}
+
+	// Reject parameters a provider would refuse before the first weekly run
+	for _, provider := range providers {
+		overrides := cfg.ProviderParams[provider.Name()]
+		for digest, params := range map[string]config.GenerationParams{
+			"summary":    cfg.SummaryParams,
+			"enrichment": cfg.EnrichmentParams,
+		} {
+			if err := provider.ValidateParams(params.With(overrides)); err != nil {
+				closeProviders(providers)
+				return nil, fmt.Errorf("invalid %s parameters for %s/%s: %w", digest, provider.Name(), provider.Model(), err)
+			}
+		}
 	}
 
 	return &AIAnalyzer{
-		client: client,
-		model:  modelName,
+		providers:      providers,
+		params:         cfg.SummaryParams,
+		providerParams: cfg.ProviderParams,
+		maxRetries:     cfg.LLMMaxRetries,
+		topN:           cfg.TopArticlesCount,
+		usage:          usage,
+		budgetAction:   cfg.BudgetAction,
+		logger:         logger,
 	}, nil
 }
 
-// Close closes the AI client connection
+// Close closes all provider connections
 func (a *AIAnalyzer) Close() error {
-	return a.client.Close()
+	return closeProviders(a.providers)
 }
 
+func closeProviders(providers []LLMProvider) error {
+	var errs []error
+	for _, p := range providers {
+		if err := p.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// AnalysisOptions carries optional inputs computed before the analysis
+type AnalysisOptions struct {
+	// Topics detected by embedding clustering. When set they replace the
+	// topics the model lists in its summary.
+	Topics []models.Topic
+	// Trends compares topic coverage with the previous run
+	Trends *models.Trends
+	// Persona shapes the prompt and the rendered sections; the zero value
+	// is the general-purpose digest
+	Persona config.Persona
+	// MarketPulse aggregates the sentiment of the business stories
+	MarketPulse *models.MarketPulse
+	// Watchlist holds the collected articles mentioning watched entities,
+	// shown regardless of the persona's categories and sections
+	Watchlist []models.WatchlistMatch
+	// MaxChars is the smallest message budget among the recipients (0 is
+	// unlimited). The model is asked to leave room for the other sections.
+	MaxChars int
+}
+
+// summaryShare is the part of a message budget given to the generated
+// summary; headers, topics and story lists use the rest
+const summaryShare = 0.6
+
+// maxCompressAttempts bounds the LLM passes used to shorten a summary
+// before it is cut at a sentence boundary
+const maxCompressAttempts = 2
+
 // AnalyzeNews generates a comprehensive summary of news articles
-func (a *AIAnalyzer) AnalyzeNews(ctx context.Context, articles []models.Article) (*models.NewsSummary, error) {
+func (a *AIAnalyzer) AnalyzeNews(ctx context.Context, articles []models.Article, opts AnalysisOptions) (*models.NewsSummary, error) {
 	if len(articles) == 0 {
 		return nil, fmt.Errorf("no articles to analyze")
 	}
 
-	prompt := a.buildPrompt(articles)
-
-	model := a.client.GenerativeModel(a.model)
+	// Enriched articles are ranked so the most important ones lead the prompt
+	articles = RankArticles(articles)
 
-	// Configure the model
-	model.SetTemperature(0.7)
-	model.SetTopP(0.9)
-	model.SetTopK(40)
-	model.SetMaxOutputTokens(2048)
+	prompt := a.buildPrompt(articles, opts)
 
-	resp, err := model.GenerateContent(ctx, genai.Text(prompt))
+	result, err := a.generate(ctx, prompt, a.params)
 	if err != nil {
-		return nil, fmt.Errorf("generating content: %w", err)
+		return nil, err
 	}
 
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
-		return nil, fmt.Errorf("no response generated")
-	}
-
-	summary := fmt.Sprintf("%v", resp.Candidates[0].Content.Parts[0])
+	summary := result.Text
 
 	// Extract key topics and trending stories from the summary
 	keyTopics, trendingStories := a.extractInsights(summary)
+	if len(opts.Topics) > 0 {
+		keyTopics = opts.Topics
+	}
 
-	weekRange := fmt.Sprintf("%s - %s",
-		time.Now().AddDate(0, 0, -7).Format("Jan 02"),
-		time.Now().Format("Jan 02, 2006"))
+	periodEnd := time.Now()
+	periodStart := periodEnd.AddDate(0, 0, -7)
 
 	return &models.NewsSummary{
-		WeekRange:       weekRange,
+		Persona:         opts.Persona.Name,
+		Sections:        opts.Persona.Sections,
+		Language:        i18n.DefaultLanguage,
+		PeriodStart:     periodStart,
+		PeriodEnd:       periodEnd,
+		WeekRange:       i18n.FormatWeekRange(i18n.DefaultLanguage, periodStart, periodEnd),
 		TotalArticles:   len(articles),
 		Summary:         summary,
 		KeyTopics:       keyTopics,
 		TrendingStories: trendingStories,
+		TopArticles:     a.topArticles(articles),
+		Trends:          opts.Trends,
+		MarketPulse:     opts.MarketPulse,
+		Watchlist:       opts.Watchlist,
 		GeneratedAt:     time.Now(),
 	}, nil
 }
 
-func (a *AIAnalyzer) buildPrompt(articles []models.Article) string {
+// summaryTranslation carries the translatable parts of a summary
+type summaryTranslation struct {
+	Summary         string   `json:"summary"`
+	KeyTopics       []string `json:"keyTopics"`
+	TrendingStories []string `json:"trendingStories"`
+	TLDRs           []string `json:"tldrs"`
+}
+
+// topicNames returns the names of topics, in order
+func topicNames(topics []models.Topic) []string {
+	names := make([]string, len(topics))
+	for i, topic := range topics {
+		names[i] = topic.Name
+	}
+	return names
+}
+
+// TranslateSummary returns a copy of summary with its generated sections
+// translated into language. The analysis itself is not repeated.
+func (a *AIAnalyzer) TranslateSummary(ctx context.Context, summary *models.NewsSummary, language string) (*models.NewsSummary, error) {
+	source := summaryTranslation{
+		Summary:         summary.Summary,
+		KeyTopics:       topicNames(summary.KeyTopics),
+		TrendingStories: summary.TrendingStories,
+	}
+	for _, article := range summary.TopArticles {
+		source.TLDRs = append(source.TLDRs, article.TLDR)
+	}
+
+	payload, err := json.MarshalIndent(source, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding summary: %w", err)
+	}
+
 	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Translate the values of the following JSON object into %s.\n", i18n.Name(language)))
+	sb.WriteString("Keep markdown formatting, numbers, URLs and company or product names unchanged.\n")
+	sb.WriteString("Reply with the JSON object only, using the same keys and the same number of list items.\n\n")
+	sb.Write(payload)
+
+	params := a.params
+	params.Temperature = 0.2
 
-	sb.WriteString("You are a professional tech news analyst. Analyze the following technology news articles from the past week and create a comprehensive weekly summary.\n\n")
+	result, err := a.generate(ctx, sb.String(), params)
+	if err != nil {
+		return nil, err
+	}
+
+	var translated summaryTranslation
+	if err := parseJSONResponse(result.Text, &translated); err != nil {
+		return nil, err
+	}
+	if len(translated.KeyTopics) != len(source.KeyTopics) ||
+		len(translated.TrendingStories) != len(source.TrendingStories) ||
+		len(translated.TLDRs) != len(source.TLDRs) {
+		return nil, fmt.Errorf("translation changed the number of items")
+	}
+
+	out := *summary
+	out.Language = language
+	out.WeekRange = i18n.FormatWeekRange(language, summary.PeriodStart, summary.PeriodEnd)
+	out.Summary = translated.Summary
+	out.KeyTopics = make([]models.Topic, len(summary.KeyTopics))
+	for i, topic := range summary.KeyTopics {
+		topic.Name = translated.KeyTopics[i]
+		out.KeyTopics[i] = topic
+	}
+	out.TrendingStories = translated.TrendingStories
+	out.TopArticles = make([]models.ArticleBrief, len(summary.TopArticles))
+	for i, article := range summary.TopArticles {
+		article.TLDR = translated.TLDRs[i]
+		out.TopArticles[i] = article
+	}
+
+	return &out, nil
+}
+
+// CompressSummary shortens a generated summary to at most maxChars
+// characters, keeping its language and markdown. If the model overshoots
+// repeatedly the text is cut at the last sentence that fits.
+func (a *AIAnalyzer) CompressSummary(ctx context.Context, text, language string, maxChars int) (string, error) {
+	params := a.params
+	params.Temperature = 0.3
+
+	var lastErr error
+	for attempt := 0; attempt < maxCompressAttempts && runeLen(text) > maxChars; attempt++ {
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("Shorten the following weekly tech news digest to at most %d characters (it is %d now).\n",
+			maxChars*9/10, runeLen(text)))
+		sb.WriteString(fmt.Sprintf("Keep it in %s, keep the markdown headers, and keep the most important developments and figures.\n", i18n.Name(language)))
+		sb.WriteString("Do not add new facts. Reply with the shortened digest only.\n\n")
+		sb.WriteString(text)
+
+		result, err := a.generate(ctx, sb.String(), params)
+		if err != nil {
+			lastErr = err
+			break
+		}
+		if shorter := strings.TrimSpace(result.Text); shorter != "" && runeLen(shorter) < runeLen(text) {
+			text = shorter
+		}
+	}
+
+	if runeLen(text) > maxChars {
+		a.logger.Printf("⚠️ Summary still %d characters after compression, truncating to %d", runeLen(text), maxChars)
+		text = truncateSentences(text, maxChars)
+	}
+	return text, lastErr
+}
+
+// truncateSentences cuts text to maxChars at the last sentence or line
+// boundary and appends an ellipsis
+func truncateSentences(text string, maxChars int) string {
+	runes := []rune(text)
+	if len(runes) <= maxChars {
+		return text
+	}
+
+	cut := string(runes[:max(maxChars-1, 0)])
+	if i := strings.LastIndexAny(cut, ".!?\n"); i > len(cut)/2 {
+		cut = cut[:i+1]
+	}
+	return strings.TrimSpace(cut) + "…"
+}
+
+func runeLen(s string) int {
+	return utf8.RuneCountInString(s)
+}
+
+// topArticles returns briefs of the highest ranked enriched articles
+func (a *AIAnalyzer) topArticles(ranked []models.Article) []models.ArticleBrief {
+	var briefs []models.ArticleBrief
+	for _, article := range ranked {
+		if article.Enrichment == nil || len(briefs) == a.topN {
+			continue
+		}
+		briefs = append(briefs, article.Brief())
+	}
+	return briefs
+}
+
+// generate walks the provider chain in order. Transient errors are retried
+// with backoff on the same provider, any other failure moves on to the next
+// one. Each provider gets an equal share of the time left before the
+// deadline, so a slow provider cannot starve the fallbacks after it. When the
+// monthly budget would be exceeded the run either stops or downgrades to the
+// next (cheaper) provider, depending on BUDGET_ACTION.
+func (a *AIAnalyzer) generate(ctx context.Context, prompt string, params config.GenerationParams) (*LLMResult, error) {
+	return a.generateRecorded(ctx, prompt, params, a.usage.Record)
+}
+
+// generateStandalone is generate for calls made outside a weekly run, whose
+// usage goes to the ledger right away instead of into the run
+func (a *AIAnalyzer) generateStandalone(ctx context.Context, prompt string, params config.GenerationParams) (*LLMResult, error) {
+	return a.generateRecorded(ctx, prompt, params, func(result *LLMResult) {
+		if err := a.usage.RecordStandalone(result); err != nil {
+			a.logger.Printf("Failed to record usage: %v", err)
+		}
+	})
+}
+
+func (a *AIAnalyzer) generateRecorded(ctx context.Context, prompt string, params config.GenerationParams, record func(*LLMResult)) (*LLMResult, error) {
+	var errs []error
+
+	for i, provider := range a.providers {
+		label := provider.Name() + "/" + provider.Model()
+		params := params.With(a.providerParams[provider.Name()])
+
+		// A cached response costs nothing, so the budget does not apply
+		if result, ok := cachedResult(provider, prompt, params); ok {
+			record(result)
+			a.logger.Printf("Using cached response from %s", label)
+			return result, nil
+		}
+
+		reservation, err := a.usage.Allow(provider.Model(), prompt, params)
+		if err != nil {
+			if a.budgetAction == "stop" {
+				return nil, err
+			}
+			a.logger.Printf("💸 Skipping %s: %v", label, err)
+			errs = append(errs, fmt.Errorf("%s: %w", label, err))
+			continue
+		}
+
+		providerCtx, cancel := providerContext(ctx, len(a.providers)-i)
+		result, err := a.generateWithRetry(providerCtx, provider, prompt, params)
+		cancel()
+		if err == nil {
+			record(result)
+		}
+		// The actual cost is recorded before the estimate is released, so
+		// the call is never left out of the budget in between
+		a.usage.Release(reservation)
+		if err == nil {
+			if result.Cached {
+				a.logger.Printf("Using cached response from %s", label)
+			}
+			return result, nil
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", label, err))
+		if ctx.Err() != nil {
+			break
+		}
+		a.logger.Printf("⚠️ %s failed: %v", label, err)
+	}
+
+	return nil, fmt.Errorf("all LLM providers failed: %w", errors.Join(errs...))
+}
+
+// providerContext returns a context ending after an equal share of the time
+// left before ctx's deadline, split between the remaining providers
+func providerContext(ctx context.Context, remaining int) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok || remaining <= 1 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, time.Until(deadline)/time.Duration(remaining))
+}
+
+func (a *AIAnalyzer) generateWithRetry(ctx context.Context, provider LLMProvider, prompt string, params config.GenerationParams) (*LLMResult, error) {
+	var lastErr error
+
+	// maxRetries counts the attempts after the first, so 0 tries once
+	for attempt := 0; attempt <= a.maxRetries; attempt++ {
+		if attempt > 0 {
+			delay := backoff(attempt)
+			a.logger.Printf("Retrying %s/%s in %s (attempt %d/%d): %v",
+				provider.Name(), provider.Model(), delay.Round(time.Millisecond), attempt+1, a.maxRetries+1, lastErr)
+			if err := sleepContext(ctx, delay); err != nil {
+				return nil, lastErr
+			}
+		}
+
+		result, err := provider.Generate(ctx, prompt, params)
+		if err == nil {
+			return result, nil
+		}
+		lastErr = err
+
+		if !isTransient(ctx, err) {
+			break
+		}
+	}
+
+	return nil, lastErr
+}
+
+func (a *AIAnalyzer) buildPrompt(articles []models.Article, opts AnalysisOptions) string {
+	var sb strings.Builder
+
+	if opts.Persona.Prompt != "" {
+		sb.WriteString(opts.Persona.Prompt)
+		sb.WriteString("\n\n")
+	} else {
+		sb.WriteString("You are a professional tech news analyst. Analyze the following technology news articles from the past week and create a comprehensive weekly summary.\n\n")
+	}
 	sb.WriteString("Articles:\n\n")
 
 	for i, article := range articles {
 		sb.WriteString(fmt.Sprintf("%d. Title: %s\n", i+1, article.Title))
 		sb.WriteString(fmt.Sprintf("   Source: %s\n", article.Source))
 		sb.WriteString(fmt.Sprintf("   Category: %s\n", article.Category))
+		if article.Language != "" && article.Language != i18n.DefaultLanguage {
+			sb.WriteString(fmt.Sprintf("   Language: %s\n", i18n.Name(article.Language)))
+		}
 		if article.Desc != "" {
 			sb.WriteString(fmt.Sprintf("   Description: %s\n", article.Desc))
 		}
+		if e := article.Enrichment; e != nil {
+			sb.WriteString(fmt.Sprintf("   TL;DR: %s\n", e.TLDR))
+			sb.WriteString(fmt.Sprintf("   Importance: %d/5\n", e.Importance))
+		}
+		if m := article.Market; m != nil {
+			sb.WriteString(fmt.Sprintf("   Market sentiment: %s\n", m.Sentiment))
+		}
 		sb.WriteString("\n")
 	}
 
+	if len(opts.Topics) > 0 {
+		sb.WriteString("Themes detected by clustering the articles (name, number of articles):\n")
+		for _, topic := range opts.Topics {
+			sb.WriteString(fmt.Sprintf("- %s (%d)\n", topic.Name, topic.Size))
+		}
+		sb.WriteString("Structure the summary around these themes, giving more weight to larger ones.\n")
+	}
+
+	if !opts.Trends.IsEmpty() {
+		sb.WriteString("\nChanges in coverage compared with the previous week (article counts, last week -> this week):\n")
+		for _, t := range opts.Trends.Changes {
+			sb.WriteString(fmt.Sprintf("- %s: %d -> %d\n", t.Topic, t.Previous, t.Current))
+		}
+		for _, t := range opts.Trends.Emerging {
+			sb.WriteString(fmt.Sprintf("- %s: new this week (%d)\n", t.Topic, t.Current))
+		}
+		for _, t := range opts.Trends.Fading {
+			sb.WriteString(fmt.Sprintf("- %s: no coverage this week (was %d)\n", t.Topic, t.Previous))
+		}
+		sb.WriteString("Mention the most notable shifts where they are relevant.\n")
+	}
+
 	sb.WriteString("\nPlease provide:\n")
 	sb.WriteString("1. A concise executive summary (2-3 paragraphs) of the week's most important tech developments\n")
 	sb.WriteString("2. Key topics and themes (list 3-5 main topics)\n")
 	sb.WriteString("3. Top 3 trending stories with brief explanations\n")
 	sb.WriteString("4. Notable insights or patterns across the news\n\n")
+	if opts.MaxChars > 0 {
+		sb.WriteString(fmt.Sprintf("The digest is delivered as a single chat message, so keep your whole response under %d characters.\n",
+			int(float64(opts.MaxChars)*summaryShare)))
+	}
+	if opts.Persona.MaxWords > 0 {
+		sb.WriteString(fmt.Sprintf("Keep the whole response under %d words.\n", opts.Persona.MaxWords))
+	}
+	sb.WriteString(fmt.Sprintf("Some articles may be written in other languages; summarize all of them in %s.\n", i18n.Name(i18n.DefaultLanguage)))
 	sb.WriteString("Format your response in a clear, professional manner suitable for a weekly newsletter.\n")
 	sb.WriteString("Use markdown formatting with headers (##) for sections.\n")
 
 	return sb.String()
 }
 
-func (a *AIAnalyzer) extractInsights(summary string) ([]string, []string) {
+func (a *AIAnalyzer) extractInsights(summary string) ([]models.Topic, []string) {
 	// Simple extraction logic - in production, you might use more sophisticated parsing
-	keyTopics := []string{}
+	keyTopics := []models.Topic{}
 	trendingStories := []string{}
 
 	lines := strings.Split(summary, "\n")
@@ -143,7 +531,7 @@ func (a *AIAnalyzer) extractInsights(summary string) ([]string, []string) {
 			topic = strings.TrimPrefix(topic, "•")
 			topic = strings.TrimSpace(topic)
 			if topic != "" && len(keyTopics) < 5 {
-				keyTopics = append(keyTopics, topic)
+				keyTopics = append(keyTopics, models.Topic{Name: topic})
 			}
 		}
 
@@ -168,7 +556,7 @@ func (a *AIAnalyzer) extractInsights(summary string) ([]string, []string) {
 
 	// Fallback to generic topics if extraction failed
 	if len(keyTopics) == 0 {
-		keyTopics = []string{"Artificial Intelligence", "Cloud Computing", "Cybersecurity"}
+		keyTopics = []models.Topic{{Name: "Artificial Intelligence"}, {Name: "Cloud Computing"}, {Name: "Cybersecurity"}}
 	}
 	if len(trendingStories) == 0 {
 		trendingStories = []string{"Major tech industry developments", "Innovation breakthroughs", "Market trends"}
@@ -177,6 +565,21 @@ func (a *AIAnalyzer) extractInsights(summary string) ([]string, []string) {
 	return keyTopics, trendingStories
 }
 
+// parseJSONResponse decodes a JSON value from model output, tolerating
+// markdown code fences and surrounding prose
+func parseJSONResponse(text string, v any) error {
+	start := strings.IndexAny(text, "{[")
+	end := strings.LastIndexAny(text, "}]")
+	if start < 0 || end < start {
+		return fmt.Errorf("no JSON found in response")
+	}
+
+	if err := json.Unmarshal([]byte(text[start:end+1]), v); err != nil {
+		return fmt.Errorf("decoding JSON response: %w", err)
+	}
+	return nil
+}
+
 func ListAvailableModels(apiKey string) error {
 	ctx := context.Background()
 
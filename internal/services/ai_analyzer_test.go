@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"tech-news-agent/internal/config"
+)
+
+func TestTranslateSummary(t *testing.T) {
+	const translated = `{"summary": "Çip üreticileri beklentilerini yükseltti.", "keyTopics": ["YZ ajanları", "Çipler"], "trendingStories": ["İhracat kuralları", "Rust 2.0"], "tldrs": ["Gelir %40 arttı.", ""]}`
+
+	tests := []struct {
+		name    string
+		reply   string
+		wantErr string
+	}{
+		{name: "translated", reply: "```json\n" + translated + "\n```"},
+		{name: "dropped item", reply: `{"summary": "x", "keyTopics": ["YZ"], "trendingStories": [], "tldrs": []}`, wantErr: "changed the number of items"},
+		{name: "no JSON", reply: "Üzgünüm", wantErr: "no JSON found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var prompt string
+			llm := &fakeLLM{name: "fake", reply: func(p string) string {
+				prompt = p
+				return tt.reply
+			}}
+			summary := goldenSummary()
+
+			out, err := newTestAnalyzer(t, llm).TranslateSummary(context.Background(), summary, "tr")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("TranslateSummary error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TranslateSummary: %v", err)
+			}
+			if !strings.Contains(prompt, "into Turkish") || !strings.Contains(prompt, `"AI agents"`) {
+				t.Errorf("prompt does not ask for Turkish or lacks the topics:\n%s", prompt)
+			}
+
+			if out.Language != "tr" || out.WeekRange != "05 Eki - 11 Eki 2026" {
+				t.Errorf("language %q, week range %q", out.Language, out.WeekRange)
+			}
+			if out.Summary != "Çip üreticileri beklentilerini yükseltti." {
+				t.Errorf("summary = %q", out.Summary)
+			}
+			if got := topicNames(out.KeyTopics); !reflect.DeepEqual(got, []string{"YZ ajanları", "Çipler"}) {
+				t.Errorf("topics = %q", got)
+			}
+			// Only names are translated; sizes and articles are kept
+			if out.KeyTopics[0].Size != 4 || len(out.KeyTopics[0].Articles) != 1 {
+				t.Errorf("topic lost its members: %+v", out.KeyTopics[0])
+			}
+			if out.TopArticles[0].TLDR != "Gelir %40 arttı." || out.TopArticles[0].URL != "https://example.com/tsmc" {
+				t.Errorf("top article = %+v", out.TopArticles[0])
+			}
+			// The English summary is left alone
+			if summary.Language != "en" || summary.KeyTopics[0].Name != "AI agents" || summary.TopArticles[0].TLDR != "Revenue up 40%." {
+				t.Errorf("TranslateSummary modified its input: %+v", summary)
+			}
+		})
+	}
+}
+
+func TestGenerateFallback(t *testing.T) {
+	tests := []struct {
+		name       string
+		errs       []error
+		wantCalls  int
+		wantServed bool // whether the fallback provider answered
+	}{
+		{name: "success", wantCalls: 1},
+		{name: "503 retried", errs: []error{&httpStatusError{StatusCode: 503}}, wantCalls: 2},
+		{name: "503 exhausted", errs: []error{&httpStatusError{StatusCode: 503}, &httpStatusError{StatusCode: 503}}, wantCalls: 2, wantServed: true},
+		{name: "empty response exhausted", errs: []error{ErrEmptyResponse, ErrEmptyResponse}, wantCalls: 2, wantServed: true},
+		{name: "400 not retried", errs: []error{&httpStatusError{StatusCode: 400}}, wantCalls: 1, wantServed: true},
+		{name: "safety block not retried", errs: []error{ErrSafetyBlocked}, wantCalls: 1, wantServed: true},
+		{name: "truncation not retried", errs: []error{ErrTruncated}, wantCalls: 1, wantServed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			primary := &fakeLLM{name: "primary", errs: tt.errs}
+			fallback := &fakeLLM{name: "fallback"}
+			analyzer := newTestAnalyzer(t, primary, fallback)
+			analyzer.maxRetries = 1
+
+			result, err := analyzer.generate(context.Background(), "prompt", config.GenerationParams{})
+			if err != nil {
+				t.Fatalf("generate: %v", err)
+			}
+			if got := primary.callCount(); got != tt.wantCalls {
+				t.Errorf("primary called %d times, want %d", got, tt.wantCalls)
+			}
+			wantProvider, wantFallbackCalls := "primary", 0
+			if tt.wantServed {
+				wantProvider, wantFallbackCalls = "fallback", 1
+			}
+			if result.Provider != wantProvider {
+				t.Errorf("answered by %q, want %q", result.Provider, wantProvider)
+			}
+			if got := fallback.callCount(); got != wantFallbackCalls {
+				t.Errorf("fallback called %d times, want %d", got, wantFallbackCalls)
+			}
+		})
+	}
+}
+
+func TestGenerateStopsWhenContextEnds(t *testing.T) {
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		primary := &fakeLLM{name: "primary", errs: []error{context.Canceled}}
+		fallback := &fakeLLM{name: "fallback"}
+		analyzer := newTestAnalyzer(t, primary, fallback)
+		analyzer.maxRetries = 3
+
+		if _, err := analyzer.generate(ctx, "prompt", config.GenerationParams{}); !errors.Is(err, context.Canceled) {
+			t.Fatalf("generate error = %v, want context.Canceled", err)
+		}
+		if primary.callCount() != 1 || fallback.callCount() != 0 {
+			t.Errorf("calls = %d/%d, want 1/0", primary.callCount(), fallback.callCount())
+		}
+	})
+
+	t.Run("deadline passed", func(t *testing.T) {
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+		primary := &fakeLLM{name: "primary", errs: []error{context.DeadlineExceeded}}
+		fallback := &fakeLLM{name: "fallback"}
+		analyzer := newTestAnalyzer(t, primary, fallback)
+		analyzer.maxRetries = 3
+
+		if _, err := analyzer.generate(ctx, "prompt", config.GenerationParams{}); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("generate error = %v, want context.DeadlineExceeded", err)
+		}
+		if primary.callCount() != 1 || fallback.callCount() != 0 {
+			t.Errorf("calls = %d/%d, want 1/0", primary.callCount(), fallback.callCount())
+		}
+	})
+}
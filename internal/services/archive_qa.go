@@ -0,0 +1,323 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"tech-news-agent/internal/config"
+	"tech-news-agent/internal/models"
+	"time"
+)
+
+// Archive search modes
+const (
+	SearchKeyword = "keyword"
+	SearchHybrid  = "hybrid"
+)
+
+// BM25 tuning constants
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// questionStopwords are common question words that carry no topic
+var questionStopwords = map[string]bool{
+	"did": true, "does": true, "was": true, "were": true, "see": true, "seen": true, "anything": true,
+	"any": true, "there": true, "which": true, "who": true, "when": true, "where": true, "month": true,
+	"week": true, "weeks": true, "last": true, "this": true, "news": true, "tell": true, "happened": true,
+}
+
+// ArchiveQA answers questions from the stored article archive with
+// retrieval-augmented generation
+type ArchiveQA struct {
+	history  *HistoryStore
+	analyzer *AIAnalyzer
+	params   config.GenerationParams
+	embedder EmbeddingProvider
+	topK     int
+	logger   *log.Logger
+
+	mu sync.Mutex
+	// documents caches the article embeddings of each history file, so a
+	// question only embeds itself
+	documents map[string]documentVectors
+}
+
+// documentVectors are the embeddings of the articles of one stored run, in
+// order, computed from the file version saved at modTime
+type documentVectors struct {
+	modTime time.Time
+	vectors [][]float32
+}
+
+// archivedArticle is an article and the stored run it was taken from
+type archivedArticle struct {
+	models.Article
+	// day names the history file, index is the article's position in it
+	day   string
+	index int
+}
+
+// NewArchiveQA creates a new archive Q&A instance. With a nil embedder
+// articles are retrieved by keyword only.
+func NewArchiveQA(history *HistoryStore, analyzer *AIAnalyzer, params config.GenerationParams, embedder EmbeddingProvider, topK int, logger *log.Logger) *ArchiveQA {
+	return &ArchiveQA{
+		history:   history,
+		analyzer:  analyzer,
+		params:    params,
+		embedder:  embedder,
+		topK:      topK,
+		logger:    logger,
+		documents: make(map[string]documentVectors),
+	}
+}
+
+// Ask answers question from the articles archived since the given time,
+// citing the articles it used
+func (qa *ArchiveQA) Ask(ctx context.Context, question string, since time.Time) (*models.Answer, error) {
+	question = strings.TrimSpace(question)
+	if question == "" {
+		return nil, fmt.Errorf("question is empty")
+	}
+
+	articles, runs, err := qa.archive(since)
+	if err != nil {
+		return nil, err
+	}
+
+	answer := &models.Answer{Question: question}
+	if len(articles) == 0 {
+		answer.Answer = "No articles have been archived for this period yet."
+		return answer, nil
+	}
+
+	matches, err := qa.search(ctx, question, articles, runs)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		answer.Answer = "No archived articles match this question."
+		return answer, nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("You answer questions about past tech news using only the numbered articles below.\n")
+	sb.WriteString("Cite the articles you use inline as [n]. If the articles do not answer the question, say so instead of guessing.\n")
+	sb.WriteString("Answer in the language of the question, in at most two short paragraphs.\n\n")
+	sb.WriteString("Articles:\n")
+	for i, article := range matches {
+		sb.WriteString(fmt.Sprintf("[%d] %s (%s, %s)\n", i+1, article.Title, article.Source, article.PublishedAt.Format("2006-01-02")))
+		if article.Desc != "" {
+			sb.WriteString(fmt.Sprintf("    %s\n", article.Desc))
+		}
+		if e := article.Enrichment; e != nil && e.TLDR != "" {
+			sb.WriteString(fmt.Sprintf("    TL;DR: %s\n", e.TLDR))
+		}
+	}
+	sb.WriteString(fmt.Sprintf("\nQuestion: %s\n", question))
+
+	result, err := qa.analyzer.generateStandalone(ctx, sb.String(), qa.params)
+	if err != nil {
+		return nil, fmt.Errorf("generating answer: %w", err)
+	}
+
+	answer.Answer = strings.TrimSpace(result.Text)
+	for i, article := range matches {
+		// Only list the articles the model actually cited
+		if !strings.Contains(answer.Answer, fmt.Sprintf("[%d]", i+1)) {
+			continue
+		}
+		answer.Citations = append(answer.Citations, models.Citation{
+			Index:       i + 1,
+			Title:       article.Title,
+			URL:         article.URL,
+			Source:      article.Source,
+			PublishedAt: article.PublishedAt,
+		})
+	}
+
+	return answer, nil
+}
+
+// archive returns the distinct articles stored since the given time and
+// the runs they were taken from
+func (qa *ArchiveQA) archive(since time.Time) ([]archivedArticle, []StoredRun, error) {
+	runs, err := qa.history.Since(since)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading archive: %w", err)
+	}
+
+	index := make(map[string]int)
+	var articles []archivedArticle
+	for _, run := range runs {
+		day := run.Entry.PeriodEnd.Format(historyDateFormat)
+		for i, article := range run.Entry.Articles {
+			id := article.ID
+			if id == "" {
+				id = models.ArticleID(article.URL)
+			}
+			archived := archivedArticle{Article: article, day: day, index: i}
+			// Later runs may carry a richer copy of the same article
+			if j, ok := index[id]; ok {
+				articles[j] = archived
+				continue
+			}
+			index[id] = len(articles)
+			articles = append(articles, archived)
+		}
+	}
+	return articles, runs, nil
+}
+
+// documentVectors returns the embedding of every article, embedding only
+// the runs that are not cached yet or were saved again since
+func (qa *ArchiveQA) documentVectors(ctx context.Context, articles []archivedArticle, runs []StoredRun) ([][]float32, error) {
+	qa.mu.Lock()
+	cached := make(map[string]documentVectors, len(runs))
+	var stale []StoredRun
+	for _, run := range runs {
+		day := run.Entry.PeriodEnd.Format(historyDateFormat)
+		if doc, ok := qa.documents[day]; ok && doc.modTime.Equal(run.ModTime) {
+			cached[day] = doc
+			continue
+		}
+		stale = append(stale, run)
+	}
+	qa.mu.Unlock()
+
+	if len(stale) > 0 {
+		var texts []string
+		for _, run := range stale {
+			for _, article := range run.Entry.Articles {
+				texts = append(texts, embeddingText(article))
+			}
+		}
+		vectors, err := qa.embedder.Embed(ctx, texts, TaskDocument)
+		if err != nil {
+			return nil, err
+		}
+		if len(vectors) != len(texts) {
+			return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(vectors))
+		}
+
+		qa.mu.Lock()
+		for _, run := range stale {
+			day := run.Entry.PeriodEnd.Format(historyDateFormat)
+			n := len(run.Entry.Articles)
+			doc := documentVectors{modTime: run.ModTime, vectors: vectors[:n:n]}
+			vectors = vectors[n:]
+			qa.documents[day] = doc
+			cached[day] = doc
+		}
+		qa.mu.Unlock()
+	}
+
+	documents := make([][]float32, len(articles))
+	for i, article := range articles {
+		documents[i] = cached[article.day].vectors[article.index]
+	}
+	return documents, nil
+}
+
+// search ranks articles against question and returns the top k matches
+func (qa *ArchiveQA) search(ctx context.Context, question string, articles []archivedArticle, runs []StoredRun) ([]archivedArticle, error) {
+	scores := keywordScores(question, articles)
+
+	if qa.embedder != nil {
+		query, err := qa.embedder.Embed(ctx, []string{question}, TaskQuery)
+		var documents [][]float32
+		if err == nil {
+			documents, err = qa.documentVectors(ctx, articles, runs)
+		}
+		if err != nil {
+			qa.logger.Printf("⚠️ Embedding search failed, using keywords only: %v", err)
+		} else {
+			// Blend normalized keyword scores with cosine similarity
+			var best float64
+			for _, s := range scores {
+				best = max(best, s)
+			}
+			for i := range scores {
+				if best > 0 {
+					scores[i] /= best
+				}
+				scores[i] = 0.5*scores[i] + 0.5*max(cosine(query[0], documents[i]), 0)
+			}
+		}
+	}
+
+	order := make([]int, len(articles))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return scores[order[i]] > scores[order[j]]
+	})
+
+	var matches []archivedArticle
+	for _, i := range order {
+		if scores[i] <= 0 || len(matches) == qa.topK {
+			break
+		}
+		matches = append(matches, articles[i])
+	}
+	return matches, nil
+}
+
+// keywordScores scores every article against the question with BM25
+func keywordScores(question string, articles []archivedArticle) []float64 {
+	var terms []string
+	for _, term := range searchTokens(question) {
+		if len([]rune(term)) >= 3 && !labelStopwords[term] && !questionStopwords[term] {
+			terms = append(terms, term)
+		}
+	}
+
+	docs := make([]map[string]int, len(articles))
+	lengths := make([]int, len(articles))
+	df := make(map[string]int)
+	var totalLength int
+
+	for i, article := range articles {
+		docs[i] = make(map[string]int)
+		// The title is counted twice so headline matches rank first
+		text := article.Title + " " + article.Title + " " + article.Desc
+		if e := article.Enrichment; e != nil {
+			text += " " + e.TLDR + " " + strings.Join(e.Tags, " ")
+		}
+		for _, token := range searchTokens(text) {
+			docs[i][token]++
+			lengths[i]++
+		}
+		for token := range docs[i] {
+			df[token]++
+		}
+		totalLength += lengths[i]
+	}
+
+	n := float64(len(articles))
+	avgLength := float64(totalLength) / max(n, 1)
+
+	scores := make([]float64, len(articles))
+	for i, doc := range docs {
+		for _, term := range terms {
+			tf := float64(doc[term])
+			if tf == 0 {
+				continue
+			}
+			idf := math.Log(1 + (n-float64(df[term])+0.5)/(float64(df[term])+0.5))
+			norm := tf + bm25K1*(1-bm25B+bm25B*float64(lengths[i])/avgLength)
+			scores[i] += idf * tf * (bm25K1 + 1) / norm
+		}
+	}
+	return scores
+}
+
+func searchTokens(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), isTokenSeparator)
+}
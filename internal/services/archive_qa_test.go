@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"tech-news-agent/internal/config"
+	"tech-news-agent/internal/models"
+	"testing"
+	"time"
+)
+
+// countingEmbedder returns a fixed vector and records every call's task and
+// number of texts
+type countingEmbedder struct {
+	calls []embedCall
+}
+
+type embedCall struct {
+	task  EmbeddingTask
+	texts int
+}
+
+func (e *countingEmbedder) Name() string { return "fake" }
+func (e *countingEmbedder) Close() error { return nil }
+
+func (e *countingEmbedder) Embed(ctx context.Context, texts []string, task EmbeddingTask) ([][]float32, error) {
+	e.calls = append(e.calls, embedCall{task: task, texts: len(texts)})
+	vectors := make([][]float32, len(texts))
+	for i := range vectors {
+		vectors[i] = []float32{1, 0}
+	}
+	return vectors, nil
+}
+
+func TestArchiveQACachesDocumentEmbeddings(t *testing.T) {
+	dataDir := t.TempDir()
+	history := NewHistoryStore(dataDir)
+	first := time.Date(2026, 10, 4, 0, 0, 0, 0, time.UTC)
+	second := first.AddDate(0, 0, 7)
+	for _, entry := range []*models.HistoryEntry{
+		{PeriodEnd: first, Articles: []models.Article{{Title: "Rust in the kernel", URL: "https://a.io/1"}, {Title: "Go 1.30", URL: "https://a.io/2"}}},
+		{PeriodEnd: second, Articles: []models.Article{{Title: "Rust 2.0", URL: "https://a.io/3"}}},
+	} {
+		if err := history.Save(entry); err != nil {
+			t.Fatalf("saving history: %v", err)
+		}
+	}
+
+	embedder := &countingEmbedder{}
+	qa := NewArchiveQA(history, nil, config.GenerationParams{}, embedder, 5, log.New(io.Discard, "", 0))
+	search := func() []embedCall {
+		t.Helper()
+		embedder.calls = nil
+		articles, runs, err := qa.archive(first.AddDate(0, 0, -1))
+		if err != nil {
+			t.Fatalf("loading archive: %v", err)
+		}
+		if _, err := qa.search(context.Background(), "rust", articles, runs); err != nil {
+			t.Fatalf("searching: %v", err)
+		}
+		return embedder.calls
+	}
+
+	if calls := search(); len(calls) != 2 || calls[0] != (embedCall{TaskQuery, 1}) || calls[1] != (embedCall{TaskDocument, 3}) {
+		t.Fatalf("first search embedded %+v, want the query and 3 documents", calls)
+	}
+	if calls := search(); len(calls) != 1 || calls[0] != (embedCall{TaskQuery, 1}) {
+		t.Fatalf("second search embedded %+v, want the query only", calls)
+	}
+
+	// A run saved again is embedded again, the other one stays cached
+	path := filepath.Join(dataDir, "history", second.Format(historyDateFormat)+".json")
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatalf("touching history file: %v", err)
+	}
+	if calls := search(); len(calls) != 2 || calls[1] != (embedCall{TaskDocument, 1}) {
+		t.Fatalf("search after a new save embedded %+v, want the query and 1 document", calls)
+	}
+}
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"tech-news-agent/internal/config"
+	"tech-news-agent/internal/i18n"
+	"tech-news-agent/internal/models"
+	"time"
+)
+
+// enrichmentRetention is how long a stored enrichment is reused. Articles
+// are collected for a week, so older ones do not come back.
+const enrichmentRetention = 30 * 24 * time.Hour
+
+// ArticleEnricher runs the optional per-article AI pass that produces a
+// TL;DR, a taxonomy category, an importance score and tags for each article.
+// Enrichments are kept in DATA_DIR/enrichments.json so articles seen in an
+// earlier run are not sent to the model again.
+type ArticleEnricher struct {
+	analyzer    *AIAnalyzer
+	params      config.GenerationParams
+	concurrency int
+	interval    time.Duration
+	path        string
+	logger      *log.Logger
+
+	mu    sync.Mutex
+	cache map[string]storedEnrichment
+	dirty bool
+}
+
+// storedEnrichment is an enrichment and the time it was produced
+type storedEnrichment struct {
+	Enrichment *models.ArticleEnrichment `json:"enrichment"`
+	StoredAt   time.Time                 `json:"storedAt"`
+}
+
+// NewArticleEnricher creates a new article enricher instance
+func NewArticleEnricher(analyzer *AIAnalyzer, params config.GenerationParams, concurrency, ratePerMinute int, dataDir string, logger *log.Logger) *ArticleEnricher {
+	e := &ArticleEnricher{
+		analyzer:    analyzer,
+		params:      params,
+		concurrency: concurrency,
+		interval:    time.Minute / time.Duration(ratePerMinute),
+		path:        filepath.Join(dataDir, "enrichments.json"),
+		logger:      logger,
+		cache:       make(map[string]storedEnrichment),
+	}
+	if err := e.load(); err != nil {
+		logger.Printf("⚠️ Could not load stored enrichments, starting empty: %v", err)
+	}
+	return e
+}
+
+// Enrich annotates the articles concurrently, respecting the configured rate
+// limit. Articles that fail enrichment are returned unchanged.
+func (e *ArticleEnricher) Enrich(ctx context.Context, articles []models.Article) []models.Article {
+	enriched := make([]models.Article, len(articles))
+	copy(enriched, articles)
+
+	limiter := time.NewTicker(e.interval)
+	defer limiter.Stop()
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	var failed int
+	var failedMu sync.Mutex
+
+	for w := 0; w < e.concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if cached := e.cached(enriched[i].ID); cached != nil {
+					enriched[i].Enrichment = cached
+					continue
+				}
+
+				select {
+				case <-ctx.Done():
+					continue
+				case <-limiter.C:
+				}
+
+				enrichment, err := e.enrichArticle(ctx, enriched[i])
+				if err != nil {
+					failedMu.Lock()
+					failed++
+					failedMu.Unlock()
+					e.logger.Printf("Enrichment failed for %q: %v", enriched[i].Title, err)
+					continue
+				}
+				e.store(enriched[i].ID, enrichment)
+				enriched[i].Enrichment = enrichment
+			}
+		}()
+	}
+
+	for i := range enriched {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	if failed > 0 {
+		e.logger.Printf("⚠️ %d/%d articles could not be enriched", failed, len(articles))
+	}
+	if err := e.save(); err != nil {
+		e.logger.Printf("⚠️ Failed to store enrichments: %v", err)
+	}
+	return enriched
+}
+
+func (e *ArticleEnricher) cached(id string) *models.ArticleEnrichment {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.cache[id].Enrichment
+}
+
+func (e *ArticleEnricher) store(id string, enrichment *models.ArticleEnrichment) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.cache[id] = storedEnrichment{Enrichment: enrichment, StoredAt: time.Now()}
+	e.dirty = true
+}
+
+// load reads the stored enrichments, dropping those past the retention
+func (e *ArticleEnricher) load() error {
+	data, err := os.ReadFile(e.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading enrichments: %w", err)
+	}
+
+	var stored map[string]storedEnrichment
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return fmt.Errorf("decoding enrichments: %w", err)
+	}
+
+	cutoff := time.Now().Add(-enrichmentRetention)
+	for id, entry := range stored {
+		if entry.Enrichment == nil || entry.StoredAt.Before(cutoff) {
+			e.dirty = true
+			continue
+		}
+		e.cache[id] = entry
+	}
+	return nil
+}
+
+// save writes the enrichments back when they changed
+func (e *ArticleEnricher) save() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(e.cache)
+	if err != nil {
+		return fmt.Errorf("encoding enrichments: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(e.path), 0o755); err != nil {
+		return fmt.Errorf("creating data directory: %w", err)
+	}
+	tmp := e.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("writing enrichments: %w", err)
+	}
+	if err := os.Rename(tmp, e.path); err != nil {
+		return fmt.Errorf("writing enrichments: %w", err)
+	}
+	e.dirty = false
+	return nil
+}
+
+func (e *ArticleEnricher) enrichArticle(ctx context.Context, article models.Article) (*models.ArticleEnrichment, error) {
+	result, err := e.analyzer.generate(ctx, buildEnrichmentPrompt(article), e.params)
+	if err != nil {
+		return nil, err
+	}
+	return parseEnrichment(result.Text)
+}
+
+// parseEnrichment decodes the model's answer and clamps it to the taxonomy,
+// the importance scale and five tags
+func parseEnrichment(text string) (*models.ArticleEnrichment, error) {
+	var enrichment models.ArticleEnrichment
+	if err := parseJSONResponse(text, &enrichment); err != nil {
+		return nil, err
+	}
+
+	enrichment.TLDR = strings.TrimSpace(enrichment.TLDR)
+	enrichment.Category = strings.ToLower(strings.TrimSpace(enrichment.Category))
+	if !slices.Contains(models.Taxonomy, enrichment.Category) {
+		enrichment.Category = "other"
+	}
+	enrichment.Importance = min(max(enrichment.Importance, 1), 5)
+	if len(enrichment.Tags) > 5 {
+		enrichment.Tags = enrichment.Tags[:5]
+	}
+
+	return &enrichment, nil
+}
+
+func buildEnrichmentPrompt(article models.Article) string {
+	var sb strings.Builder
+
+	sb.WriteString("You are a tech news editor. Classify the following article and reply with a single JSON object only.\n\n")
+	sb.WriteString(fmt.Sprintf("Title: %s\n", article.Title))
+	sb.WriteString(fmt.Sprintf("Source: %s\n", article.Source))
+	if article.Desc != "" {
+		sb.WriteString(fmt.Sprintf("Description: %s\n", article.Desc))
+	}
+
+	sb.WriteString("\nJSON fields:\n")
+	sb.WriteString(fmt.Sprintf("- \"tldr\": one sentence in %s, at most 25 words\n", i18n.Name(i18n.DefaultLanguage)))
+	sb.WriteString(fmt.Sprintf("- \"category\": exactly one of %s\n", strings.Join(models.Taxonomy, ", ")))
+	sb.WriteString("- \"importance\": integer 1-5, where 5 means industry-wide impact\n")
+	sb.WriteString("- \"tags\": up to 5 short lowercase keywords\n")
+
+	return sb.String()
+}
+
+// RankArticles orders articles by importance, newest first within the same score
+func RankArticles(articles []models.Article) []models.Article {
+	ranked := make([]models.Article, len(articles))
+	copy(ranked, articles)
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Importance() != ranked[j].Importance() {
+			return ranked[i].Importance() > ranked[j].Importance()
+		}
+		return ranked[i].PublishedAt.After(ranked[j].PublishedAt)
+	})
+
+	return ranked
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"tech-news-agent/internal/config"
+	"tech-news-agent/internal/models"
+	"testing"
+	"time"
+)
+
+// newTestAnalyzer returns an analyzer over providers that tries each once
+func newTestAnalyzer(t *testing.T, providers ...LLMProvider) *AIAnalyzer {
+	t.Helper()
+	return &AIAnalyzer{
+		providers: providers,
+		usage:     NewUsageTracker(&config.Config{DataDir: t.TempDir()}),
+		logger:    log.New(io.Discard, "", 0),
+	}
+}
+
+func TestParseEnrichment(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    *models.ArticleEnrichment
+		wantErr bool
+	}{
+		{
+			name: "normalized",
+			text: "```json\n{\"tldr\": \" Chips got faster. \", \"category\": \" Hardware\", \"importance\": 4, \"tags\": [\"chips\"]}\n```",
+			want: &models.ArticleEnrichment{TLDR: "Chips got faster.", Category: "hardware", Importance: 4, Tags: []string{"chips"}},
+		},
+		{
+			name: "unknown category and importance out of range",
+			text: `{"tldr": "x", "category": "gaming", "importance": 9}`,
+			want: &models.ArticleEnrichment{TLDR: "x", Category: "other", Importance: 5},
+		},
+		{
+			name: "missing importance and too many tags",
+			text: `{"tldr": "x", "category": "ai", "tags": ["a", "b", "c", "d", "e", "f"]}`,
+			want: &models.ArticleEnrichment{TLDR: "x", Category: "ai", Importance: 1, Tags: []string{"a", "b", "c", "d", "e"}},
+		},
+		{name: "no JSON", text: "I cannot classify this.", wantErr: true},
+		{name: "invalid JSON", text: `{"importance": "high"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseEnrichment(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseEnrichment error = %v, want error %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseEnrichment = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// enrichingLLM classifies articles by title and tracks how many calls run
+// at once. Titles containing "broken" get an answer without JSON.
+type enrichingLLM struct {
+	fakeLLM
+	inFlight, maxInFlight atomic.Int32
+}
+
+func (l *enrichingLLM) Generate(ctx context.Context, prompt string, params config.GenerationParams) (*LLMResult, error) {
+	n := l.inFlight.Add(1)
+	defer l.inFlight.Add(-1)
+	for {
+		peak := l.maxInFlight.Load()
+		if n <= peak || l.maxInFlight.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	return l.fakeLLM.Generate(ctx, prompt, params)
+}
+
+func newEnrichingLLM() *enrichingLLM {
+	return &enrichingLLM{fakeLLM: fakeLLM{name: "fake", reply: func(prompt string) string {
+		if strings.Contains(prompt, "broken") {
+			return "sorry"
+		}
+		return `{"tldr": "t", "category": "ai", "importance": 3}`
+	}}}
+}
+
+func TestArticleEnricherEnrich(t *testing.T) {
+	var articles []models.Article
+	for i := 0; i < 6; i++ {
+		articles = append(articles, models.Article{ID: fmt.Sprint(i), Title: fmt.Sprintf("Story %d", i)})
+	}
+	articles[5].Title = "broken story"
+
+	dataDir := t.TempDir()
+	llm := newEnrichingLLM()
+	logger := log.New(io.Discard, "", 0)
+	// 6000 calls a minute leave 10ms between calls
+	enricher := NewArticleEnricher(newTestAnalyzer(t, llm), config.GenerationParams{}, 2, 6000, dataDir, logger)
+
+	start := time.Now()
+	enriched := enricher.Enrich(context.Background(), articles)
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("6 calls took %v, the rate limit allows no less than 50ms", elapsed)
+	}
+	if peak := llm.maxInFlight.Load(); peak > 2 {
+		t.Errorf("%d calls ran at once, concurrency is 2", peak)
+	}
+	for i, article := range enriched[:5] {
+		if article.Enrichment == nil || article.Enrichment.Category != "ai" {
+			t.Errorf("article %d enrichment = %+v", i, article.Enrichment)
+		}
+	}
+	// A failed article is returned unchanged
+	if enriched[5].Enrichment != nil {
+		t.Errorf("broken article was enriched: %+v", enriched[5].Enrichment)
+	}
+	if articles[0].Enrichment != nil {
+		t.Error("Enrich modified its input")
+	}
+
+	// A later run reuses the stored enrichments and only retries the failure
+	again := newEnrichingLLM()
+	NewArticleEnricher(newTestAnalyzer(t, again), config.GenerationParams{}, 2, 6000, dataDir, logger).
+		Enrich(context.Background(), articles)
+	if again.callCount() != 1 {
+		t.Errorf("second run made %d calls, want 1", again.callCount())
+	}
+}
+
+func TestArticleEnricherDropsOldEnrichments(t *testing.T) {
+	dataDir := t.TempDir()
+	logger := log.New(io.Discard, "", 0)
+	enricher := NewArticleEnricher(nil, config.GenerationParams{}, 1, 60, dataDir, logger)
+	enricher.cache["old"] = storedEnrichment{Enrichment: &models.ArticleEnrichment{}, StoredAt: time.Now().Add(-enrichmentRetention - time.Hour)}
+	enricher.cache["new"] = storedEnrichment{Enrichment: &models.ArticleEnrichment{}, StoredAt: time.Now()}
+	enricher.dirty = true
+	if err := enricher.save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	reloaded := NewArticleEnricher(nil, config.GenerationParams{}, 1, 60, dataDir, logger)
+	if reloaded.cached("old") != nil || reloaded.cached("new") == nil {
+		t.Errorf("reloaded enrichments = %v, want only the new one", reloaded.cache)
+	}
+}
+
+func TestRankArticles(t *testing.T) {
+	day := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+	article := func(title string, importance int, age int) models.Article {
+		a := models.Article{Title: title, PublishedAt: day.AddDate(0, 0, -age)}
+		if importance > 0 {
+			a.Enrichment = &models.ArticleEnrichment{Importance: importance}
+		}
+		return a
+	}
+	articles := []models.Article{
+		article("plain old", 0, 3),
+		article("minor", 2, 0),
+		article("major old", 5, 4),
+		article("plain new", 0, 1),
+		article("major new", 5, 1),
+	}
+
+	var titles []string
+	for _, a := range RankArticles(articles) {
+		titles = append(titles, a.Title)
+	}
+	want := []string{"major new", "major old", "minor", "plain new", "plain old"}
+	if !reflect.DeepEqual(titles, want) {
+		t.Errorf("RankArticles = %q, want %q", titles, want)
+	}
+	if articles[0].Title != "plain old" {
+		t.Error("RankArticles reordered its input")
+	}
+}
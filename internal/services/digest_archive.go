@@ -0,0 +1,110 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"tech-news-agent/internal/config"
+	"tech-news-agent/internal/i18n"
+	"tech-news-agent/internal/models"
+	"tech-news-agent/internal/renderer"
+)
+
+// DigestArchive stores the full HTML version of every digest under
+// DATA_DIR/digests so shortened messages can link to it
+type DigestArchive struct {
+	dir     string
+	baseURL string
+}
+
+// NewDigestArchive creates a new digest archive. Without a base URL pages
+// are still stored but no link is produced.
+func NewDigestArchive(dataDir, baseURL string) *DigestArchive {
+	return &DigestArchive{
+		dir:     filepath.Join(dataDir, "digests"),
+		baseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// Dir returns the directory the pages are stored in
+func (d *DigestArchive) Dir() string {
+	return d.dir
+}
+
+// Save writes summary as an HTML page and returns its public URL, which is
+// empty when no base URL is configured
+func (d *DigestArchive) Save(summary *models.NewsSummary) (string, error) {
+	if err := os.MkdirAll(d.dir, 0o755); err != nil {
+		return "", fmt.Errorf("creating digest directory: %w", err)
+	}
+
+	name := digestPageName(summary)
+
+	// The page shows every section the messages show, in full
+	htmlRenderer := renderer.New(renderer.HTML)
+	var sections []string
+	for _, section := range renderer.Build(summary).Sections {
+		sections = append(sections, htmlRenderer.Section(section))
+	}
+
+	var buf bytes.Buffer
+	if err := digestPage.Execute(&buf, digestPageData{
+		Lang:    summary.Language,
+		Title:   i18n.T(summary.Language, i18n.DigestTitle),
+		Summary: summary,
+		Body:    template.HTML(strings.Join(sections, "\n")),
+	}); err != nil {
+		return "", fmt.Errorf("rendering digest page: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(d.dir, name), buf.Bytes(), 0o644); err != nil {
+		return "", fmt.Errorf("writing digest page: %w", err)
+	}
+
+	if d.baseURL == "" {
+		return "", nil
+	}
+	return d.baseURL + "/digests/" + url.PathEscape(name), nil
+}
+
+// digestPageName returns the file name of the page of summary, built only
+// from characters that are safe in paths and URLs
+func digestPageName(summary *models.NewsSummary) string {
+	persona := summary.Persona
+	if persona == "" {
+		persona = config.DefaultPersona
+	}
+	return fmt.Sprintf("%s-%s-%s.html", summary.PeriodEnd.Format(historyDateFormat),
+		config.Slug(persona), config.Slug(summary.Language))
+}
+
+type digestPageData struct {
+	Lang    string
+	Title   string
+	Summary *models.NewsSummary
+	Body    template.HTML
+}
+
+var digestPage = template.Must(template.New("digest").Funcs(template.FuncMap{"t": i18n.T}).Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}} · {{.Summary.WeekRange}}</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 46rem; margin: 2rem auto; padding: 0 1rem; line-height: 1.55; color: #222; }
+h1 { font-size: 1.6rem; } h2 { font-size: 1.25rem; margin-top: 2rem; }
+.meta { color: #666; } li { margin: .3rem 0; } a { color: #0b5cad; }
+</style>
+</head>
+<body>
+<h1>📰 {{.Title}}</h1>
+<p class="meta">📅 {{.Summary.WeekRange}} · {{t .Lang "articles_analyzed" .Summary.TotalArticles}}</p>
+{{.Body}}
+</body>
+</html>
+`))
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"tech-news-agent/internal/models"
+	"testing"
+	"time"
+)
+
+func TestDigestArchiveNames(t *testing.T) {
+	tests := []struct {
+		persona  string
+		language string
+		wantName string
+	}{
+		{"", "en", "2026-10-11-default-en.html"},
+		{"Exec Team", "de", "2026-10-11-exec-team-de.html"},
+		{"../../etc/passwd", "en", "2026-10-11-etc-passwd-en.html"},
+		{"r&d / EU?", "pt-BR", "2026-10-11-r-d-eu-pt-br.html"},
+		{"経営陣", "ja", "2026-10-11-14072ea9-ja.html"},
+	}
+
+	dir := t.TempDir()
+	archive := NewDigestArchive(dir, "https://news.example.com/")
+	for _, tt := range tests {
+		t.Run(tt.persona, func(t *testing.T) {
+			url, err := archive.Save(&models.NewsSummary{
+				Persona:   tt.persona,
+				Language:  tt.language,
+				PeriodEnd: time.Date(2026, 10, 11, 0, 0, 0, 0, time.UTC),
+			})
+			if err != nil {
+				t.Fatalf("saving digest: %v", err)
+			}
+			if want := "https://news.example.com/digests/" + tt.wantName; url != want {
+				t.Errorf("url = %q, want %q", url, want)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "digests", tt.wantName)); err != nil {
+				t.Errorf("page not stored under its name: %v", err)
+			}
+		})
+	}
+}
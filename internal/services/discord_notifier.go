@@ -0,0 +1,408 @@
+package services
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"tech-news-agent/internal/config"
+	"tech-news-agent/internal/i18n"
+	"tech-news-agent/internal/models"
+	"tech-news-agent/internal/renderer"
+	"time"
+)
+
+// Discord webhook limits
+const (
+	discordMaxContent     = 2000
+	discordMaxDescription = 4096
+	discordMaxFields      = 25
+	discordMaxFieldName   = 256
+	discordMaxFieldValue  = 1024
+	discordMaxFooter      = 2048
+	discordMaxEmbeds      = 10
+	discordMaxEmbedTotal  = 6000
+	discordMaxRetries     = 3
+)
+
+// discordRenderer renders the markdown Discord understands; footers do not
+// support markdown
+var (
+	discordRenderer       = renderer.New(renderer.CommonMark)
+	discordFooterRenderer = renderer.New(renderer.PlainText)
+)
+
+// discordDefaultColor is Discord's blurple, used for sections without a
+// category
+const discordDefaultColor = 0x5865F2
+
+// discordColors gives every article category its own embed color
+var discordColors = map[string]int{
+	"ai":         0x8E44AD,
+	"cloud":      0x3498DB,
+	"security":   0xE74C3C,
+	"hardware":   0x95A5A6,
+	"software":   0x2ECC71,
+	"mobile":     0x1ABC9C,
+	"science":    0xF1C40F,
+	"business":   0xE67E22,
+	"policy":     0x34495E,
+	"technology": discordDefaultColor,
+}
+
+// DiscordNotifier posts digests to a Discord channel webhook as embeds
+type DiscordNotifier struct {
+	webhookURL string
+	language   string
+	maxChars   int
+	httpClient *http.Client
+}
+
+var _ Notifier = (*DiscordNotifier)(nil)
+
+// NewDiscordNotifier creates a new Discord notifier for dest
+func NewDiscordNotifier(dest config.Destination) *DiscordNotifier {
+	return &DiscordNotifier{
+		webhookURL: dest.Target,
+		language:   i18n.Normalize(dest.Language),
+		maxChars:   dest.MaxChars,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Destination identifies the webhook by a hash of its URL, which is a secret
+func (dn *DiscordNotifier) Destination() string {
+	return webhookDestination("discord", dn.webhookURL)
+}
+
+// Language returns the digest language of the channel
+func (dn *DiscordNotifier) Language() string {
+	return dn.language
+}
+
+// MaxChars returns the length budget of a digest (0 is unlimited)
+func (dn *DiscordNotifier) MaxChars() int {
+	return dn.maxChars
+}
+
+type discordEmbed struct {
+	Title       string         `json:"title,omitempty"`
+	URL         string         `json:"url,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Color       int            `json:"color,omitempty"`
+	Timestamp   string         `json:"timestamp,omitempty"`
+	Fields      []discordField `json:"fields,omitempty"`
+	Footer      *discordFooter `json:"footer,omitempty"`
+}
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+type discordFooter struct {
+	Text string `json:"text"`
+}
+
+type discordMessage struct {
+	Content string         `json:"content,omitempty"`
+	Embeds  []discordEmbed `json:"embeds,omitempty"`
+}
+
+// size is the embed's share of Discord's per-message character limit
+func (e discordEmbed) size() int {
+	n := runeLen(e.Title) + runeLen(e.Description)
+	if e.Footer != nil {
+		n += runeLen(e.Footer.Text)
+	}
+	for _, f := range e.Fields {
+		n += runeLen(f.Name) + runeLen(f.Value)
+	}
+	return n
+}
+
+// SendSummary posts the digest, spread over as many messages as Discord's
+// embed limits require
+func (dn *DiscordNotifier) SendSummary(ctx context.Context, summary *models.NewsSummary) error {
+	for _, embeds := range packEmbeds(dn.buildEmbeds(summary)) {
+		if err := dn.post(ctx, discordMessage{Embeds: embeds}); err != nil {
+			return fmt.Errorf("sending message: %w", err)
+		}
+	}
+	return nil
+}
+
+// SendError posts an error notification
+func (dn *DiscordNotifier) SendError(ctx context.Context, errMsg string) error {
+	title := fmt.Sprintf("⚠️ **%s**\n", i18n.T(dn.language, i18n.ErrorTitle))
+	content := title + "```\n" + truncateRunes(errMsg, discordMaxContent-runeLen(title)-10) + "\n```"
+	if err := dn.post(ctx, discordMessage{Content: content}); err != nil {
+		return fmt.Errorf("sending error message: %w", err)
+	}
+	return nil
+}
+
+// TestConnection posts a test message to verify the webhook
+func (dn *DiscordNotifier) TestConnection(ctx context.Context) error {
+	if err := dn.post(ctx, discordMessage{Content: "✅ " + i18n.T(dn.language, i18n.Connected)}); err != nil {
+		return fmt.Errorf("test message failed: %w", err)
+	}
+	return nil
+}
+
+// FormatMessage renders the text of all embeds, which is what the length
+// budget applies to
+func (dn *DiscordNotifier) FormatMessage(summary *models.NewsSummary) string {
+	var parts []string
+	for _, embed := range dn.buildEmbeds(summary) {
+		parts = append(parts, embed.Title, embed.Description)
+		for _, f := range embed.Fields {
+			parts = append(parts, f.Name, f.Value)
+		}
+		if embed.Footer != nil {
+			parts = append(parts, embed.Footer.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// buildEmbeds renders the sections of renderer.Build as embeds. Topics,
+// top stories, watched entities and trending stories become fields; other
+// sections become the description.
+func (dn *DiscordNotifier) buildEmbeds(summary *models.NewsSummary) []discordEmbed {
+	doc := renderer.Build(summary)
+
+	// Summary, split over several embeds when it exceeds one description
+	var meta []string
+	for _, item := range doc.Meta {
+		meta = append(meta, discordRenderer.Item(item))
+	}
+	description := strings.Join(meta, " · ")
+	sections := doc.Sections
+	if len(sections) > 0 && sections[0].Name == models.SectionSummary {
+		description += "\n\n" + discordRenderer.Content(sections[0])
+		sections = sections[1:]
+	}
+	var embeds []discordEmbed
+	for i, chunk := range splitText(description, discordMaxDescription) {
+		embed := discordEmbed{Description: chunk, Color: discordDefaultColor}
+		if i == 0 {
+			embed.Title = doc.Icon + " " + doc.Title
+			embed.URL = summary.FullURL
+		}
+		embeds = append(embeds, embed)
+	}
+
+	for _, section := range sections {
+		title := section.Heading()
+		switch {
+		case len(section.Items) == 0:
+			embeds = append(embeds, discordEmbed{Title: title, Description: discordRenderer.Content(section), Color: discordDefaultColor})
+
+		case section.Name == models.SectionKeyTopics:
+			// Topics are listed without their articles, which fit in fields
+			var fields []discordField
+			for i, item := range section.Items {
+				topic := summary.KeyTopics[i]
+				var lines []string
+				for _, article := range topic.Articles[:min(len(topic.Articles), 3)] {
+					lines = append(lines, "• "+discordLink(article.URL, article.Title))
+				}
+				fields = append(fields, discordFieldOf(item.Label(), strings.Join(lines, "\n"), len(lines) == 0))
+			}
+			embeds = append(embeds, fieldEmbeds(title, discordDefaultColor, fields)...)
+
+		case section.Name == models.SectionTopStories:
+			// One embed per category so each gets its color
+			var categories []string
+			byCategory := make(map[string][]discordField)
+			for i, item := range section.Items {
+				article := summary.TopArticles[i]
+				if _, ok := byCategory[article.Category]; !ok {
+					categories = append(categories, article.Category)
+				}
+				value := discordLink(item.URL, cmp.Or(article.Source, item.URL))
+				if item.Detail != "" {
+					value = item.Detail + "\n" + value
+				}
+				byCategory[article.Category] = append(byCategory[article.Category],
+					discordFieldOf(fmt.Sprintf("%d. %s", i+1, item.Text), value, false))
+			}
+			for _, category := range categories {
+				embedTitle := title
+				if category != "" {
+					embedTitle = fmt.Sprintf("%s · %s", title, category)
+				}
+				embeds = append(embeds, fieldEmbeds(embedTitle, discordColor(category), byCategory[category])...)
+			}
+
+		case section.Name == renderer.SectionWatchlist:
+			var fields []discordField
+			for _, group := range section.Items {
+				var lines []string
+				for _, child := range group.Children {
+					lines = append(lines, "• "+discordLink(child.URL, child.Text))
+				}
+				fields = append(fields, discordFieldOf(group.Label(), strings.Join(lines, "\n"), false))
+			}
+			embeds = append(embeds, fieldEmbeds(title, discordDefaultColor, fields)...)
+
+		case section.Name == models.SectionTrendingStories:
+			var fields []discordField
+			for i, item := range section.Items {
+				fields = append(fields, discordFieldOf(fmt.Sprintf("#%d", i+1), discordRenderer.Item(item), false))
+			}
+			embeds = append(embeds, fieldEmbeds(title, discordDefaultColor, fields)...)
+
+		default:
+			color := discordDefaultColor
+			if section.Name == models.SectionMarketPulse {
+				color = discordColor("business")
+			}
+			embeds = append(embeds, discordEmbed{
+				Title:       title,
+				Description: truncateRunes(discordRenderer.Content(section), discordMaxDescription),
+				Color:       color,
+			})
+		}
+	}
+
+	// The last embed carries the footer and the generation time. Footers
+	// are plain text, and the full version is linked from the title.
+	var footer []string
+	for _, item := range doc.Footer {
+		if item.URL == "" {
+			footer = append(footer, discordFooterRenderer.Item(item))
+		}
+	}
+	last := &embeds[len(embeds)-1]
+	last.Footer = &discordFooter{Text: truncateRunes(strings.Join(footer, " · "), discordMaxFooter)}
+	last.Timestamp = summary.GeneratedAt.Format(time.RFC3339)
+
+	return embeds
+}
+
+// post sends message to the webhook, waiting out rate limits
+func (dn *DiscordNotifier) post(ctx context.Context, message discordMessage) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("encoding message: %w", err)
+	}
+
+	// wait=true makes Discord confirm the message, so failures are reported
+	url := dn.webhookURL
+	if strings.Contains(url, "?") {
+		url += "&wait=true"
+	} else {
+		url += "?wait=true"
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("creating request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := dn.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("making request: %w", err)
+		}
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+		resp.Body.Close()
+
+		switch {
+		case resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusNoContent:
+			return nil
+		case resp.StatusCode == http.StatusTooManyRequests && attempt < discordMaxRetries:
+			wait := discordRetryAfter(resp.Header.Get("Retry-After"), respBody)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+		default:
+			return fmt.Errorf("Discord returned status %d: %s", resp.StatusCode, string(respBody))
+		}
+	}
+}
+
+// discordRetryAfter reads the rate limit delay from the header or the JSON
+// body, in seconds
+func discordRetryAfter(header string, body []byte) time.Duration {
+	seconds, err := strconv.ParseFloat(header, 64)
+	if err != nil {
+		var limit struct {
+			RetryAfter float64 `json:"retry_after"`
+		}
+		if json.Unmarshal(body, &limit) == nil {
+			seconds = limit.RetryAfter
+		}
+	}
+	return time.Duration(max(seconds, 1) * float64(time.Second))
+}
+
+// packEmbeds groups embeds into messages within Discord's per-message
+// embed count and total size limits
+func packEmbeds(embeds []discordEmbed) [][]discordEmbed {
+	var messages [][]discordEmbed
+	var current []discordEmbed
+	size := 0
+	for _, embed := range embeds {
+		if len(current) == discordMaxEmbeds || (len(current) > 0 && size+embed.size() > discordMaxEmbedTotal) {
+			messages = append(messages, current)
+			current, size = nil, 0
+		}
+		current = append(current, embed)
+		size += embed.size()
+	}
+	if len(current) > 0 {
+		messages = append(messages, current)
+	}
+	return messages
+}
+
+// fieldEmbeds spreads fields over embeds of at most 25 fields each, and
+// within the per-message size limit
+func fieldEmbeds(title string, color int, fields []discordField) []discordEmbed {
+	var embeds []discordEmbed
+	current := discordEmbed{Title: title, Color: color}
+	for _, field := range fields {
+		if len(current.Fields) == discordMaxFields || (len(current.Fields) > 0 && current.size()+runeLen(field.Name)+runeLen(field.Value) > discordMaxEmbedTotal) {
+			embeds = append(embeds, current)
+			current = discordEmbed{Title: title, Color: color}
+		}
+		current.Fields = append(current.Fields, field)
+	}
+	return append(embeds, current)
+}
+
+// discordFieldOf builds a field within Discord's name and value limits.
+// Discord rejects empty values, so they are replaced by a zero-width space.
+func discordFieldOf(name, value string, inline bool) discordField {
+	if value == "" {
+		value = "\u200b"
+	}
+	return discordField{
+		Name:   truncateRunes(name, discordMaxFieldName),
+		Value:  truncateRunes(value, discordMaxFieldValue),
+		Inline: inline,
+	}
+}
+
+func discordLink(url, title string) string {
+	return fmt.Sprintf("[%s](%s)", strings.NewReplacer("[", "(", "]", ")").Replace(title), url)
+}
+
+func discordColor(category string) int {
+	if color, ok := discordColors[category]; ok {
+		return color
+	}
+	return discordDefaultColor
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"encoding/json"
+	"strings"
+	"tech-news-agent/internal/config"
+	"testing"
+)
+
+func TestDiscordEmbedsGolden(t *testing.T) {
+	notifier := NewDiscordNotifier(config.Destination{Kind: config.DestinationDiscord, Target: "https://discord.invalid/api/webhooks/1/x", Language: "en"})
+	embeds := notifier.buildEmbeds(goldenSummary())
+
+	data, err := json.MarshalIndent(embeds, "", "  ")
+	if err != nil {
+		t.Fatalf("encoding embeds: %v", err)
+	}
+	checkGolden(t, "discord.json.golden", string(data)+"\n")
+
+	last := embeds[len(embeds)-1]
+	if last.Footer == nil || !strings.Contains(last.Footer.Text, "💰 12800 tokens · $0.0123") {
+		t.Errorf("last embed footer %+v lacks the usage", last.Footer)
+	}
+	if last.Timestamp != "2026-10-12T08:00:00Z" {
+		t.Errorf("timestamp = %q", last.Timestamp)
+	}
+	if text := notifier.FormatMessage(goldenSummary()); strings.Contains(text, "**Chip**") {
+		t.Errorf("trending story markdown is not stripped:\n%s", text)
+	}
+}
+
+func TestDiscordEmbedsOnlySelectedSections(t *testing.T) {
+	summary := goldenSummary()
+	summary.Sections = []string{"top_stories"}
+	var titles []string
+	for _, embed := range NewDiscordNotifier(config.Destination{Language: "en"}).buildEmbeds(summary) {
+		titles = append(titles, embed.Title)
+	}
+	// The watchlist cannot be switched off
+	want := "📰 Weekly Tech News Summary|⭐ Top 2 Stories · business|⭐ Top 2 Stories · software|👀 Your Watchlist"
+	if got := strings.Join(titles, "|"); got != want {
+		t.Errorf("embed titles = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,369 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"tech-news-agent/internal/config"
+	"tech-news-agent/internal/i18n"
+	"tech-news-agent/internal/models"
+	"tech-news-agent/internal/renderer"
+	"time"
+)
+
+// EmailNotifier sends digests as multipart HTML and plain-text emails to
+// one recipient over SMTP
+type EmailNotifier struct {
+	host           string
+	port           int
+	username       string
+	password       string
+	from           string
+	tlsMode        string
+	unsubscribeURL string
+	recipient      string
+	language       string
+	maxChars       int
+}
+
+var _ Notifier = (*EmailNotifier)(nil)
+
+// NewEmailNotifier creates a new email notifier for the recipient in dest
+func NewEmailNotifier(cfg *config.Config, dest config.Destination) *EmailNotifier {
+	return &EmailNotifier{
+		host:           cfg.SMTPHost,
+		port:           cfg.SMTPPort,
+		username:       cfg.SMTPUsername,
+		password:       cfg.SMTPPassword,
+		from:           cfg.SMTPFrom,
+		tlsMode:        cfg.SMTPTLS,
+		unsubscribeURL: cfg.EmailUnsubscribeURL,
+		recipient:      dest.Target,
+		language:       i18n.Normalize(dest.Language),
+		maxChars:       dest.MaxChars,
+	}
+}
+
+// Destination identifies the recipient
+func (en *EmailNotifier) Destination() string {
+	return "email:" + en.recipient
+}
+
+// Language returns the digest language of the recipient
+func (en *EmailNotifier) Language() string {
+	return en.language
+}
+
+// MaxChars returns the length budget of a digest (0 is unlimited)
+func (en *EmailNotifier) MaxChars() int {
+	return en.maxChars
+}
+
+// SendSummary emails the digest with an HTML and a plain-text version
+func (en *EmailNotifier) SendSummary(ctx context.Context, summary *models.NewsSummary) error {
+	html, err := en.formatHTML(summary)
+	if err != nil {
+		return err
+	}
+
+	subject := fmt.Sprintf("📰 %s · %s", i18n.T(summary.Language, i18n.DigestTitle), summary.WeekRange)
+	message, err := en.buildMessage(subject, en.FormatMessage(summary), html)
+	if err != nil {
+		return err
+	}
+	if err := en.send(ctx, message); err != nil {
+		return fmt.Errorf("sending email: %w", err)
+	}
+	return nil
+}
+
+// SendError emails a plain-text error notification
+func (en *EmailNotifier) SendError(ctx context.Context, errMsg string) error {
+	subject := "⚠️ " + i18n.T(en.language, i18n.ErrorTitle)
+	message, err := en.buildMessage(subject, errMsg, "")
+	if err != nil {
+		return err
+	}
+	if err := en.send(ctx, message); err != nil {
+		return fmt.Errorf("sending error email: %w", err)
+	}
+	return nil
+}
+
+// TestConnection connects and authenticates to the SMTP server without
+// sending mail, so recipients are not spammed with test messages
+func (en *EmailNotifier) TestConnection(ctx context.Context) error {
+	client, err := en.connect(ctx)
+	if err != nil {
+		return fmt.Errorf("test connection failed: %w", err)
+	}
+	defer client.Close()
+	return client.Quit()
+}
+
+// FormatMessage renders the plain-text version of the digest
+func (en *EmailNotifier) FormatMessage(summary *models.NewsSummary) string {
+	text := renderer.New(renderer.PlainText).Document(renderer.Build(summary)) + "\n"
+	if link := en.unsubscribeLink(); link != "" {
+		text += fmt.Sprintf("%s: %s\n", i18n.T(summary.Language, i18n.Unsubscribe), link)
+	}
+	return text
+}
+
+// formatHTML renders the HTML version of the digest, with the same
+// sections and footer as the plain-text version
+func (en *EmailNotifier) formatHTML(summary *models.NewsSummary) (string, error) {
+	doc := renderer.Build(summary)
+	data := emailData{
+		Lang:        summary.Language,
+		Title:       doc.Icon + " " + doc.Title,
+		Meta:        template.HTML(emailRenderer.Lines(doc.Meta)),
+		Footer:      template.HTML(emailRenderer.Lines(doc.Footer)),
+		Unsubscribe: en.unsubscribeLink(),
+	}
+	for _, section := range doc.Sections {
+		data.Sections = append(data.Sections, emailSection{
+			Heading: section.Heading(),
+			Content: template.HTML(emailRenderer.Content(section)),
+		})
+	}
+
+	var html bytes.Buffer
+	if err := emailTemplate.Execute(&html, data); err != nil {
+		return "", fmt.Errorf("rendering email: %w", err)
+	}
+	return html.String(), nil
+}
+
+// unsubscribeLink returns the recipient's unsubscribe URL, if configured
+func (en *EmailNotifier) unsubscribeLink() string {
+	if en.unsubscribeURL == "" {
+		return ""
+	}
+	return strings.ReplaceAll(en.unsubscribeURL, "{email}", url.QueryEscape(en.recipient))
+}
+
+// buildMessage assembles the MIME message. Without an HTML body the mail
+// is plain text only.
+func (en *EmailNotifier) buildMessage(subject, text, html string) ([]byte, error) {
+	from, err := mail.ParseAddress(en.from)
+	if err != nil {
+		return nil, fmt.Errorf("parsing SMTP_FROM: %w", err)
+	}
+	messageID, err := newMessageID(from.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	header := textproto.MIMEHeader{}
+	header.Set("From", from.String())
+	header.Set("To", en.recipient)
+	header.Set("Subject", mime.QEncoding.Encode("utf-8", subject))
+	header.Set("Date", time.Now().Format(time.RFC1123Z))
+	header.Set("Message-ID", messageID)
+	header.Set("MIME-Version", "1.0")
+
+	// Per-recipient unsubscribe headers; one-click needs an HTTPS link
+	unsubscribe := []string{fmt.Sprintf("<mailto:%s?subject=unsubscribe>", from.Address)}
+	if link := en.unsubscribeLink(); link != "" {
+		unsubscribe = append([]string{"<" + link + ">"}, unsubscribe...)
+		if strings.HasPrefix(link, "https://") {
+			header.Set("List-Unsubscribe-Post", "List-Unsubscribe=One-Click")
+		}
+	}
+	header.Set("List-Unsubscribe", strings.Join(unsubscribe, ", "))
+
+	if html == "" {
+		header.Set("Content-Type", "text/plain; charset=utf-8")
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		writeHeader(&buf, header)
+		if err := writeQuotedPrintable(&buf, text); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	mw := multipart.NewWriter(&buf)
+	header.Set("Content-Type", "multipart/alternative; boundary="+mw.Boundary())
+	writeHeader(&buf, header)
+
+	// Clients show the last part they support, so HTML goes last
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", html},
+	} {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("creating MIME part: %w", err)
+		}
+		if err := writeQuotedPrintable(w, part.body); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, fmt.Errorf("closing MIME message: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// send delivers message to the recipient
+func (en *EmailNotifier) send(ctx context.Context, message []byte) error {
+	from, err := mail.ParseAddress(en.from)
+	if err != nil {
+		return fmt.Errorf("parsing SMTP_FROM: %w", err)
+	}
+
+	client, err := en.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("MAIL FROM: %w", err)
+	}
+	if err := client.Rcpt(en.recipient); err != nil {
+		return fmt.Errorf("RCPT TO: %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("DATA: %w", err)
+	}
+	if _, err := w.Write(message); err != nil {
+		return fmt.Errorf("writing message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("finishing message: %w", err)
+	}
+	return client.Quit()
+}
+
+// connect opens an SMTP session using the configured TLS mode and
+// authenticates when credentials are set
+func (en *EmailNotifier) connect(ctx context.Context) (*smtp.Client, error) {
+	addr := net.JoinHostPort(en.host, strconv.Itoa(en.port))
+	tlsConfig := &tls.Config{ServerName: en.host}
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+
+	var conn net.Conn
+	var err error
+	if en.tlsMode == "implicit" {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("connecting to %s: %w", addr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, en.host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("starting SMTP session: %w", err)
+	}
+
+	if en.tlsMode == "starttls" {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, fmt.Errorf("%s does not support STARTTLS", addr)
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("STARTTLS: %w", err)
+		}
+	}
+
+	if en.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", en.username, en.password, en.host)); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("authenticating: %w", err)
+		}
+	}
+
+	return client, nil
+}
+
+func writeHeader(buf *bytes.Buffer, header textproto.MIMEHeader) {
+	for _, key := range []string{"From", "To", "Subject", "Date", "Message-ID", "MIME-Version",
+		"List-Unsubscribe", "List-Unsubscribe-Post", "Content-Type", "Content-Transfer-Encoding"} {
+		if value := header.Get(key); value != "" {
+			buf.WriteString(key + ": " + value + "\r\n")
+		}
+	}
+	buf.WriteString("\r\n")
+}
+
+func writeQuotedPrintable(w interface{ Write([]byte) (int, error) }, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(strings.ReplaceAll(body, "\n", "\r\n"))); err != nil {
+		return fmt.Errorf("encoding body: %w", err)
+	}
+	return qp.Close()
+}
+
+func newMessageID(fromAddress string) (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating message ID: %w", err)
+	}
+	_, domain, _ := strings.Cut(fromAddress, "@")
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(b), domain), nil
+}
+
+var emailRenderer = renderer.New(renderer.HTML)
+
+type emailData struct {
+	Lang        string
+	Title       string
+	Meta        template.HTML
+	Sections    []emailSection
+	Footer      template.HTML
+	Unsubscribe string
+}
+
+// emailSection is a rendered section; the summary has no heading
+type emailSection struct {
+	Heading string
+	Content template.HTML
+}
+
+// emailTemplate uses inline styles only, as many mail clients drop <style>
+var emailTemplate = template.Must(template.New("email").Funcs(template.FuncMap{"t": i18n.T}).Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head><meta charset="utf-8"><title>{{.Title}}</title></head>
+<body style="margin:0;padding:0;background:#f4f5f7;">
+<div style="max-width:640px;margin:0 auto;padding:24px;background:#ffffff;font-family:Arial,Helvetica,sans-serif;font-size:15px;line-height:1.55;color:#222222;">
+<h1 style="font-size:22px;margin:0 0 4px;">{{.Title}}</h1>
+<div style="color:#666666;margin:0 0 20px;">{{.Meta}}</div>
+{{range .Sections}}{{if .Heading}}<h2 style="font-size:18px;margin:24px 0 8px;border-top:1px solid #e5e5e5;padding-top:16px;">{{.Heading}}</h2>
+{{end}}<div>{{.Content}}</div>
+{{end}}<div style="margin-top:24px;border-top:1px solid #e5e5e5;padding-top:12px;font-size:12px;color:#888888;">
+{{.Footer}}
+{{if .Unsubscribe}}<p><a href="{{.Unsubscribe}}" style="color:#888888;">{{t .Lang "unsubscribe"}}</a></p>{{end}}
+</div>
+</div>
+</body>
+</html>
+`))
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"tech-news-agent/internal/config"
+	"tech-news-agent/internal/models"
+	"testing"
+	"time"
+)
+
+// smtpEnvelope is a message received by fakeSMTPServer
+type smtpEnvelope struct {
+	from       string
+	recipients []string
+	data       []byte
+}
+
+// fakeSMTPServer is a minimal plain-text SMTP server that records every
+// message it receives
+type fakeSMTPServer struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	messages []smtpEnvelope
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+	s := &fakeSMTPServer{listener: listener}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeSMTPServer) addr() (string, int) {
+	addr := s.listener.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port
+}
+
+func (s *fakeSMTPServer) received() []smtpEnvelope {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]smtpEnvelope(nil), s.messages...)
+}
+
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 localhost fake ESMTP")
+
+	var envelope smtpEnvelope
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			tp.PrintfLine("250-localhost")
+			tp.PrintfLine("250 8BITMIME")
+		case "MAIL":
+			envelope = smtpEnvelope{from: smtpPath(arg)}
+			tp.PrintfLine("250 OK")
+		case "RCPT":
+			envelope.recipients = append(envelope.recipients, smtpPath(arg))
+			tp.PrintfLine("250 OK")
+		case "DATA":
+			tp.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			envelope.data = data
+			s.mu.Lock()
+			s.messages = append(s.messages, envelope)
+			s.mu.Unlock()
+			tp.PrintfLine("250 OK")
+		case "QUIT":
+			tp.PrintfLine("221 Bye")
+			return
+		default:
+			tp.PrintfLine("250 OK")
+		}
+	}
+}
+
+// smtpPath returns the address of a "FROM:<a@b>" or "TO:<a@b>" argument
+func smtpPath(arg string) string {
+	_, path, _ := strings.Cut(arg, ":")
+	path, _, _ = strings.Cut(strings.TrimSpace(path), " ")
+	return strings.Trim(path, "<>")
+}
+
+func TestEmailSendsMultipartMessagePerRecipient(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	host, port := server.addr()
+	cfg := &config.Config{
+		SMTPHost:            host,
+		SMTPPort:            port,
+		SMTPFrom:            "Tech News <news@example.com>",
+		SMTPTLS:             "none",
+		EmailUnsubscribeURL: "https://news.example.com/unsubscribe?email={email}",
+	}
+	summary := &models.NewsSummary{
+		Language:      "en",
+		WeekRange:     "Oct 5 - Oct 11",
+		TotalArticles: 12,
+		Summary:       "## Chips\n\nChipmakers raised **guidance** again.",
+		GeneratedAt:   time.Date(2026, 10, 12, 8, 0, 0, 0, time.UTC),
+	}
+
+	recipients := []string{"alice@example.com", "bob@example.org"}
+	for _, recipient := range recipients {
+		notifier := NewEmailNotifier(cfg, config.Destination{Kind: config.DestinationEmail, Target: recipient, Language: "en"})
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err := notifier.SendSummary(ctx, summary)
+		cancel()
+		if err != nil {
+			t.Fatalf("SendSummary to %s: %v", recipient, err)
+		}
+	}
+
+	received := server.received()
+	if len(received) != len(recipients) {
+		t.Fatalf("server received %d messages, want %d", len(received), len(recipients))
+	}
+	for i, envelope := range received {
+		recipient := recipients[i]
+		if envelope.from != "news@example.com" {
+			t.Errorf("MAIL FROM is %q", envelope.from)
+		}
+		if len(envelope.recipients) != 1 || envelope.recipients[0] != recipient {
+			t.Errorf("RCPT TO is %v, want only %s", envelope.recipients, recipient)
+		}
+
+		msg, err := mail.ReadMessage(strings.NewReader(string(envelope.data)))
+		if err != nil {
+			t.Fatalf("parsing message to %s: %v", recipient, err)
+		}
+		if to := msg.Header.Get("To"); to != recipient {
+			t.Errorf("To is %q, want %s", to, recipient)
+		}
+
+		wantUnsubscribe := "<https://news.example.com/unsubscribe?email=" + strings.ReplaceAll(recipient, "@", "%40") +
+			">, <mailto:news@example.com?subject=unsubscribe>"
+		if got := msg.Header.Get("List-Unsubscribe"); got != wantUnsubscribe {
+			t.Errorf("List-Unsubscribe is %q, want %q", got, wantUnsubscribe)
+		}
+		if got := msg.Header.Get("List-Unsubscribe-Post"); got != "List-Unsubscribe=One-Click" {
+			t.Errorf("List-Unsubscribe-Post is %q", got)
+		}
+
+		mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+		if err != nil || mediaType != "multipart/alternative" {
+			t.Fatalf("Content-Type is %q (%v), want multipart/alternative", msg.Header.Get("Content-Type"), err)
+		}
+		var types []string
+		reader := multipart.NewReader(bufio.NewReader(msg.Body), params["boundary"])
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("reading MIME part: %v", err)
+			}
+			body, err := io.ReadAll(part)
+			if err != nil {
+				t.Fatalf("decoding MIME part: %v", err)
+			}
+			if !strings.Contains(string(body), "guidance") {
+				t.Errorf("%s part does not contain the summary", part.Header.Get("Content-Type"))
+			}
+			types = append(types, part.Header.Get("Content-Type"))
+		}
+		if want := []string{"text/plain; charset=utf-8", "text/html; charset=utf-8"}; strings.Join(types, ",") != strings.Join(want, ",") {
+			t.Errorf("parts are %v, want %v", types, want)
+		}
+	}
+}
+
+func TestEmailListUnsubscribeWithoutLink(t *testing.T) {
+	notifier := NewEmailNotifier(&config.Config{SMTPFrom: "news@example.com"},
+		config.Destination{Kind: config.DestinationEmail, Target: "alice@example.com"})
+
+	message, err := notifier.buildMessage("Subject", "text", "")
+	if err != nil {
+		t.Fatalf("buildMessage: %v", err)
+	}
+	msg, err := mail.ReadMessage(strings.NewReader(string(message)))
+	if err != nil {
+		t.Fatalf("parsing message: %v", err)
+	}
+	if got := msg.Header.Get("List-Unsubscribe"); got != "<mailto:news@example.com?subject=unsubscribe>" {
+		t.Errorf("List-Unsubscribe is %q", got)
+	}
+	// One-click unsubscribe needs an HTTPS link
+	if got := msg.Header.Get("List-Unsubscribe-Post"); got != "" {
+		t.Errorf("List-Unsubscribe-Post is %q without a link", got)
+	}
+}
+
+func TestEmailHTMLGolden(t *testing.T) {
+	cfg := &config.Config{EmailUnsubscribeURL: "https://news.example.com/unsubscribe?email={email}"}
+	notifier := NewEmailNotifier(cfg, config.Destination{Kind: config.DestinationEmail, Target: "alice@example.com", Language: "en"})
+
+	html, err := notifier.formatHTML(goldenSummary())
+	if err != nil {
+		t.Fatalf("formatHTML: %v", err)
+	}
+	checkGolden(t, "email.html.golden", html)
+
+	// Both versions carry the same footer and plain trending stories
+	for _, part := range []string{html, notifier.FormatMessage(goldenSummary())} {
+		for _, want := range []string{"💰 12800 tokens · $0.0123", "Chip export rules tighten"} {
+			if !strings.Contains(part, want) {
+				t.Errorf("email part lacks %q:\n%s", want, part)
+			}
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"tech-news-agent/internal/config"
+	"time"
+	"unicode"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/option"
+)
+
+// EmbeddingTask is what vectors are computed for. Providers without
+// task-specific embeddings ignore it.
+type EmbeddingTask int
+
+const (
+	// TaskClustering embeds texts that are grouped by similarity
+	TaskClustering EmbeddingTask = iota
+	// TaskQuery embeds a search query
+	TaskQuery
+	// TaskDocument embeds a document that queries are matched against
+	TaskDocument
+)
+
+// EmbeddingProvider turns texts into vectors for clustering and search
+type EmbeddingProvider interface {
+	// Name identifies the provider and model, e.g. "gemini/text-embedding-004"
+	Name() string
+	// Embed returns one vector per text, in the same order
+	Embed(ctx context.Context, texts []string, task EmbeddingTask) ([][]float32, error)
+	// Close releases the provider's resources
+	Close() error
+}
+
+// NewEmbeddingProvider creates the embedding provider selected in config
+func NewEmbeddingProvider(cfg *config.Config) (EmbeddingProvider, error) {
+	switch cfg.EmbeddingProvider {
+	case "gemini":
+		return NewGeminiEmbedder(cfg.GeminiAPIKey, cfg.EmbeddingModel)
+	case "openai":
+		return NewOpenAIEmbedder(cfg.OpenAIBaseURL, cfg.OpenAIAPIKey, cfg.EmbeddingModel), nil
+	case "local":
+		return NewLocalEmbedder(512), nil
+	default:
+		return nil, fmt.Errorf("unknown embedding provider %q", cfg.EmbeddingProvider)
+	}
+}
+
+// GeminiEmbedder computes embeddings with a Gemini embedding model
+type GeminiEmbedder struct {
+	client *genai.Client
+	model  string
+}
+
+// geminiEmbedBatchSize is the maximum number of texts per batch request
+const geminiEmbedBatchSize = 100
+
+// NewGeminiEmbedder creates a new Gemini embedder instance
+func NewGeminiEmbedder(apiKey, modelName string) (*GeminiEmbedder, error) {
+	client, err := genai.NewClient(context.Background(), option.WithAPIKey(apiKey))
+	if err != nil {
+		return nil, fmt.Errorf("creating Gemini client: %w", err)
+	}
+	return &GeminiEmbedder{client: client, model: modelName}, nil
+}
+
+// Name returns the provider and model name
+func (g *GeminiEmbedder) Name() string {
+	return "gemini/" + g.model
+}
+
+// Close closes the Gemini client connection
+func (g *GeminiEmbedder) Close() error {
+	return g.client.Close()
+}
+
+// geminiTaskTypes maps embedding tasks to Gemini task types
+var geminiTaskTypes = map[EmbeddingTask]genai.TaskType{
+	TaskClustering: genai.TaskTypeClustering,
+	TaskQuery:      genai.TaskTypeRetrievalQuery,
+	TaskDocument:   genai.TaskTypeRetrievalDocument,
+}
+
+// Embed computes embeddings in batches
+func (g *GeminiEmbedder) Embed(ctx context.Context, texts []string, task EmbeddingTask) ([][]float32, error) {
+	model := g.client.EmbeddingModel(g.model)
+	model.TaskType = geminiTaskTypes[task]
+
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += geminiEmbedBatchSize {
+		end := min(start+geminiEmbedBatchSize, len(texts))
+
+		batch := model.NewBatch()
+		for _, text := range texts[start:end] {
+			batch.AddContent(genai.Text(text))
+		}
+
+		resp, err := model.BatchEmbedContents(ctx, batch)
+		if err != nil {
+			return nil, fmt.Errorf("embedding batch: %w", err)
+		}
+		if len(resp.Embeddings) != end-start {
+			return nil, fmt.Errorf("expected %d embeddings, got %d", end-start, len(resp.Embeddings))
+		}
+		for _, e := range resp.Embeddings {
+			vectors = append(vectors, e.Values)
+		}
+	}
+
+	if err := checkDimensions(vectors); err != nil {
+		return nil, err
+	}
+	return vectors, nil
+}
+
+// OpenAIEmbedder calls an OpenAI-compatible /embeddings endpoint, which
+// also covers most self-hosted embedding servers
+type OpenAIEmbedder struct {
+	baseURL    string
+	apiKey     string
+	model      string
+	httpClient *http.Client
+}
+
+// NewOpenAIEmbedder creates a new OpenAI-compatible embedder instance
+func NewOpenAIEmbedder(baseURL, apiKey, modelName string) *OpenAIEmbedder {
+	return &OpenAIEmbedder{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		model:   modelName,
+		httpClient: &http.Client{
+			Timeout: time.Minute,
+		},
+	}
+}
+
+// Name returns the provider and model name
+func (o *OpenAIEmbedder) Name() string {
+	return "openai/" + o.model
+}
+
+// Close is a no-op, the HTTP client holds no resources
+func (o *OpenAIEmbedder) Close() error {
+	return nil
+}
+
+// Embed computes embeddings for all texts in a single request
+func (o *OpenAIEmbedder) Embed(ctx context.Context, texts []string, _ EmbeddingTask) ([][]float32, error) {
+	body, err := json.Marshal(map[string]any{
+		"model": o.model,
+		"input": texts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encoding request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.baseURL+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if o.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.apiKey)
+	}
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, &httpStatusError{StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+
+	var embedResp struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&embedResp); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+	if len(embedResp.Data) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(embedResp.Data))
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, d := range embedResp.Data {
+		if d.Index < 0 || d.Index >= len(texts) {
+			return nil, fmt.Errorf("embedding index %d out of range", d.Index)
+		}
+		if vectors[d.Index] != nil {
+			return nil, fmt.Errorf("duplicate embedding index %d", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	if err := checkDimensions(vectors); err != nil {
+		return nil, err
+	}
+	return vectors, nil
+}
+
+// checkDimensions rejects empty vectors and vectors of differing length,
+// which would break every similarity computed from them
+func checkDimensions(vectors [][]float32) error {
+	for i, vec := range vectors {
+		if len(vec) == 0 {
+			return fmt.Errorf("embedding %d is missing", i)
+		}
+		if len(vec) != len(vectors[0]) {
+			return fmt.Errorf("embedding %d has %d dimensions, expected %d", i, len(vec), len(vectors[0]))
+		}
+	}
+	return nil
+}
+
+// LocalEmbedder is an offline stand-in that hashes words and word pairs
+// into a fixed-size bag-of-words vector. It needs no API key and is good
+// enough to group articles that share vocabulary.
+type LocalEmbedder struct {
+	dims int
+}
+
+// NewLocalEmbedder creates a new local embedder with the given dimensions
+func NewLocalEmbedder(dims int) *LocalEmbedder {
+	return &LocalEmbedder{dims: dims}
+}
+
+// Name returns the provider name
+func (l *LocalEmbedder) Name() string {
+	return fmt.Sprintf("local/hash-%d", l.dims)
+}
+
+// Close is a no-op
+func (l *LocalEmbedder) Close() error {
+	return nil
+}
+
+// Embed hashes every text into a normalized vector
+func (l *LocalEmbedder) Embed(_ context.Context, texts []string, _ EmbeddingTask) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vec := make([]float32, l.dims)
+
+		words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		var prev string
+		for _, word := range words {
+			if len(word) < 3 {
+				prev = ""
+				continue
+			}
+			vec[l.bucket(word)]++
+			if prev != "" {
+				vec[l.bucket(prev+" "+word)] += 0.5
+			}
+			prev = word
+		}
+
+		normalize(vec)
+		vectors[i] = vec
+	}
+	return vectors, nil
+}
+
+func (l *LocalEmbedder) bucket(token string) int {
+	h := fnv.New32a()
+	h.Write([]byte(token))
+	return int(h.Sum32() % uint32(l.dims))
+}
+
+// normalize scales vec to unit length in place
+func normalize(vec []float32) {
+	var sum float64
+	for _, v := range vec {
+		sum += float64(v) * float64(v)
+	}
+	if sum == 0 {
+		return
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range vec {
+		vec[i] /= norm
+	}
+}
+
+// cosine returns the cosine similarity of two vectors, or 0 when their
+// dimensions differ
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOpenAIEmbedderRejectsIncompleteResponses(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{"repeated index", `{"data": [{"index": 0, "embedding": [1, 0]}, {"index": 0, "embedding": [0, 1]}]}`, "duplicate embedding index 0"},
+		{"missing index", `{"data": [{"embedding": [1, 0]}, {"embedding": [0, 1]}]}`, "duplicate embedding index 0"},
+		{"empty vector", `{"data": [{"index": 0, "embedding": [1, 0]}, {"index": 1, "embedding": []}]}`, "embedding 1 is missing"},
+		{"differing dimensions", `{"data": [{"index": 0, "embedding": [1, 0]}, {"index": 1, "embedding": [0, 1, 0]}]}`, "embedding 1 has 3 dimensions, expected 2"},
+		{"complete", `{"data": [{"index": 1, "embedding": [0, 1]}, {"index": 0, "embedding": [1, 0]}]}`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			vectors, err := NewOpenAIEmbedder(server.URL, "", "test").Embed(context.Background(), []string{"a", "b"}, TaskClustering)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Embed: %v", err)
+				}
+				if vectors[0][0] != 1 || vectors[1][1] != 1 {
+					t.Errorf("vectors = %v, not ordered by index", vectors)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Embed error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCosineDifferingDimensions(t *testing.T) {
+	if got := cosine([]float32{1, 0, 0}, []float32{1, 0}); got != 0 {
+		t.Errorf("cosine of differing dimensions = %v, want 0", got)
+	}
+	if got := cosine([]float32{1, 0}, []float32{1, 0}); got != 1 {
+		t.Errorf("cosine of equal vectors = %v, want 1", got)
+	}
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"tech-news-agent/internal/config"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/option"
+)
+
+// GeminiProvider generates text with a Google Gemini model
+type GeminiProvider struct {
+	client *genai.Client
+	model  string
+}
+
+// NewGeminiProvider creates a new Gemini provider for the given model
+func NewGeminiProvider(apiKey, modelName string) (*GeminiProvider, error) {
+	ctx := context.Background()
+	client, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
+	if err != nil {
+		return nil, fmt.Errorf("creating Gemini client: %w", err)
+	}
+
+	return &GeminiProvider{
+		client: client,
+		model:  modelName,
+	}, nil
+}
+
+var geminiHarmCategories = map[string]genai.HarmCategory{
+	"harassment":        genai.HarmCategoryHarassment,
+	"hate_speech":       genai.HarmCategoryHateSpeech,
+	"sexually_explicit": genai.HarmCategorySexuallyExplicit,
+	"dangerous_content": genai.HarmCategoryDangerousContent,
+}
+
+var geminiBlockThresholds = map[string]genai.HarmBlockThreshold{
+	"block_low_and_above":    genai.HarmBlockLowAndAbove,
+	"block_medium_and_above": genai.HarmBlockMediumAndAbove,
+	"block_only_high":        genai.HarmBlockOnlyHigh,
+	"block_none":             genai.HarmBlockNone,
+}
+
+// Name returns the provider name
+func (g *GeminiProvider) Name() string {
+	return "gemini"
+}
+
+// Model returns the Gemini model name
+func (g *GeminiProvider) Model() string {
+	return g.model
+}
+
+// Close closes the Gemini client connection
+func (g *GeminiProvider) Close() error {
+	return g.client.Close()
+}
+
+// ValidateParams checks params against the Gemini API limits
+func (g *GeminiProvider) ValidateParams(params config.GenerationParams) error {
+	if err := checkRange("temperature", params.Temperature, 0, 2); err != nil {
+		return err
+	}
+	if err := checkRange("top_p", params.TopP, 0, 1); err != nil {
+		return err
+	}
+	if err := checkRange("top_k", params.TopK, 1, 1000); err != nil {
+		return err
+	}
+	if err := checkRange("max_tokens", params.MaxOutputTokens, 1, 65536); err != nil {
+		return err
+	}
+	if len(params.StopSequences) > 5 {
+		return fmt.Errorf("at most 5 stop sequences are allowed, got %d", len(params.StopSequences))
+	}
+	for category, threshold := range params.SafetySettings {
+		if _, ok := geminiHarmCategories[category]; !ok {
+			return fmt.Errorf("unknown safety category %q", category)
+		}
+		if _, ok := geminiBlockThresholds[threshold]; !ok {
+			return fmt.Errorf("unknown safety threshold %q for %s", threshold, category)
+		}
+	}
+	return nil
+}
+
+// Generate sends the prompt to Gemini and returns the first candidate
+func (g *GeminiProvider) Generate(ctx context.Context, prompt string, params config.GenerationParams) (*LLMResult, error) {
+	model := g.client.GenerativeModel(g.model)
+
+	// Configure the model
+	model.SetTemperature(params.Temperature)
+	model.SetTopP(params.TopP)
+	model.SetTopK(params.TopK)
+	model.SetMaxOutputTokens(params.MaxOutputTokens)
+	model.StopSequences = params.StopSequences
+	for category, threshold := range params.SafetySettings {
+		model.SafetySettings = append(model.SafetySettings, &genai.SafetySetting{
+			Category:  geminiHarmCategories[category],
+			Threshold: geminiBlockThresholds[threshold],
+		})
+	}
+
+	resp, err := model.GenerateContent(ctx, genai.Text(prompt))
+	if err != nil {
+		var blocked *genai.BlockedError
+		if errors.As(err, &blocked) {
+			return nil, fmt.Errorf("%w: %v", ErrSafetyBlocked, blocked)
+		}
+		return nil, fmt.Errorf("generating content: %w", err)
+	}
+
+	if len(resp.Candidates) == 0 {
+		if resp.PromptFeedback != nil && resp.PromptFeedback.BlockReason != genai.BlockReasonUnspecified {
+			return nil, fmt.Errorf("%w: prompt: %s", ErrSafetyBlocked, resp.PromptFeedback.BlockReason)
+		}
+		return nil, ErrEmptyResponse
+	}
+
+	candidate := resp.Candidates[0]
+	switch candidate.FinishReason {
+	case genai.FinishReasonSafety, genai.FinishReasonRecitation:
+		return nil, fmt.Errorf("%w: candidate: %s", ErrSafetyBlocked, candidate.FinishReason)
+	case genai.FinishReasonMaxTokens:
+		return nil, fmt.Errorf("%w (limit %d)", ErrTruncated, params.MaxOutputTokens)
+	}
+
+	text := candidateText(candidate)
+	if strings.TrimSpace(text) == "" {
+		return nil, ErrEmptyResponse
+	}
+
+	result := &LLMResult{
+		Text:             text,
+		Provider:         g.Name(),
+		Model:            g.model,
+		FinishReason:     candidate.FinishReason.String(),
+		CompletionTokens: int(candidate.TokenCount),
+	}
+	if usage := resp.UsageMetadata; usage != nil {
+		result.PromptTokens = int(usage.PromptTokenCount)
+		result.CompletionTokens = int(usage.CandidatesTokenCount)
+	}
+
+	return result, nil
+}
+
+// candidateText joins all text parts of a candidate
+func candidateText(candidate *genai.Candidate) string {
+	if candidate.Content == nil {
+		return ""
+	}
+
+	var sb strings.Builder
+	for _, part := range candidate.Content.Parts {
+		if text, ok := part.(genai.Text); ok {
+			sb.WriteString(string(text))
+		}
+	}
+	return sb.String()
+}
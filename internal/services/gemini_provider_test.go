@@ -0,0 +1,48 @@
+package services
+
+import (
+	"strings"
+	"tech-news-agent/internal/config"
+	"testing"
+)
+
+func TestGeminiValidateParams(t *testing.T) {
+	valid := config.DefaultGenerationParams()
+	with := func(change func(*config.GenerationParams)) config.GenerationParams {
+		params := valid
+		change(&params)
+		return params
+	}
+
+	tests := []struct {
+		name    string
+		params  config.GenerationParams
+		wantErr string
+	}{
+		{"defaults", valid, ""},
+		{"bounds", with(func(p *config.GenerationParams) { p.Temperature, p.TopP, p.TopK, p.MaxOutputTokens = 2, 0, 1000, 65536 }), ""},
+		{"temperature", with(func(p *config.GenerationParams) { p.Temperature = 2.5 }), "temperature must be between 0 and 2, got 2.5"},
+		{"top_p", with(func(p *config.GenerationParams) { p.TopP = -0.1 }), "top_p must be between 0 and 1"},
+		{"top_k", with(func(p *config.GenerationParams) { p.TopK = 0 }), "top_k must be between 1 and 1000, got 0"},
+		{"max_tokens", with(func(p *config.GenerationParams) { p.MaxOutputTokens = 70000 }), "max_tokens must be between 1 and 65536"},
+		{"stop sequences", with(func(p *config.GenerationParams) { p.StopSequences = []string{"a", "b", "c", "d", "e", "f"} }), "at most 5 stop sequences"},
+		{"safety", with(func(p *config.GenerationParams) { p.SafetySettings = map[string]string{"harassment": "block_none"} }), ""},
+		{"safety category", with(func(p *config.GenerationParams) { p.SafetySettings = map[string]string{"spam": "block_none"} }), `unknown safety category "spam"`},
+		{"safety threshold", with(func(p *config.GenerationParams) { p.SafetySettings = map[string]string{"harassment": "block_all"} }), `unknown safety threshold "block_all"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&GeminiProvider{}).ValidateParams(tt.params)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateParams: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateParams error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
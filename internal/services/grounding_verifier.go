@@ -0,0 +1,424 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"tech-news-agent/internal/config"
+	"tech-news-agent/internal/models"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Grounding modes
+const (
+	GroundingOff    = "off"
+	GroundingMark   = "mark"
+	GroundingRemove = "remove"
+)
+
+// unverifiedMarker is appended to sentences that could not be grounded
+const unverifiedMarker = " ⚠️(unverified)"
+
+var (
+	numberPattern = regexp.MustCompile(`\$?\d[\d,]*(?:\.\d+)?`)
+	entityPattern = regexp.MustCompile(`[A-Z][\p{L}0-9&+\-]*(?:[ \t]+[A-Z][\p{L}0-9&+\-]*)*`)
+	// linePrefix matches list and quote markers, which stay in place when
+	// the sentences after them are marked or removed
+	linePrefix = regexp.MustCompile(`^\s*(?:[-*+•]|\d{1,3}[.)]|>)\s+`)
+)
+
+// commonCapitalized are words that are capitalized for grammatical reasons
+// (sentence starts, headers) and should not be treated as named entities
+var commonCapitalized = map[string]bool{
+	"The": true, "This": true, "These": true, "That": true, "Those": true, "A": true, "An": true,
+	"In": true, "On": true, "At": true, "As": true, "By": true, "For": true, "From": true, "With": true,
+	"Meanwhile": true, "However": true, "Overall": true, "Also": true, "And": true, "But": true, "Or": true,
+	"It": true, "Its": true, "They": true, "We": true, "Our": true, "Their": true, "Other": true,
+	"Key": true, "Top": true, "Notable": true, "Executive": true, "Summary": true, "Topics": true,
+	"Trending": true, "Stories": true, "Insights": true, "Patterns": true, "Themes": true,
+	"Week": true, "Weekly": true, "Monday": true, "Tuesday": true, "Wednesday": true, "Thursday": true,
+	"Friday": true, "Saturday": true, "Sunday": true, "AI": true, "Tech": true, "Technology": true,
+	"Several": true, "Many": true, "Some": true, "Both": true, "While": true,
+	"Additionally": true, "Furthermore": true, "Finally": true, "First": true, "Second": true, "Third": true,
+	"Analysts": true, "Investors": true, "Experts": true, "Critics": true, "Researchers": true, "Developers": true,
+	"Users": true, "Customers": true, "Buyers": true, "Companies": true, "Regulators": true,
+	"Demand": true, "Sales": true, "Shares": true, "Prices": true,
+	"Since": true, "After": true, "Before": true, "When": true, "If": true, "Despite": true, "Amid": true,
+	"Following": true, "Under": true, "Over": true, "To": true, "Of": true, "There": true, "Here": true,
+	"He": true, "She": true, "His": true, "Her": true, "Why": true, "What": true, "How": true, "Then": true,
+	"Now": true, "Today": true, "Still": true, "Even": true, "More": true, "Most": true, "Only": true,
+	"Each": true, "Every": true, "All": true, "Such": true, "No": true, "Not": true, "Nobody": true,
+}
+
+// GroundingVerifier checks named entities and figures in a generated
+// summary against the articles it was generated from
+type GroundingVerifier struct {
+	mode      string
+	llmVerify bool
+	analyzer  *AIAnalyzer
+	params    config.GenerationParams
+	logger    *log.Logger
+}
+
+// NewGroundingVerifier creates a new grounding verifier instance
+func NewGroundingVerifier(mode string, llmVerify bool, analyzer *AIAnalyzer, params config.GenerationParams, logger *log.Logger) *GroundingVerifier {
+	return &GroundingVerifier{
+		mode:      mode,
+		llmVerify: llmVerify,
+		analyzer:  analyzer,
+		params:    params,
+		logger:    logger,
+	}
+}
+
+// sentenceRef locates a sentence inside the summary lines
+type sentenceRef struct {
+	line, index int
+	text        string
+	missing     []string
+}
+
+// Verify marks or removes unsupported sentences in summary and records
+// the grounding score
+func (v *GroundingVerifier) Verify(ctx context.Context, summary *models.NewsSummary, articles []models.Article) {
+	corpus := newGroundingCorpus(articles)
+
+	lines := strings.Split(summary.Summary, "\n")
+	prefixes := make([]string, len(lines))
+	sentences := make([][]string, len(lines))
+	var refs []*sentenceRef
+	var totalClaims, supportedClaims int
+
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		prefixes[i] = linePrefix.FindString(line)
+		sentences[i] = splitSentences(line[len(prefixes[i]):])
+		for j, sentence := range sentences[i] {
+			claims, missing := corpus.check(sentence)
+			totalClaims += claims
+			supportedClaims += claims - len(missing)
+			if len(missing) > 0 {
+				refs = append(refs, &sentenceRef{line: i, index: j, text: sentence, missing: missing})
+			}
+		}
+	}
+
+	if v.llmVerify && len(refs) > 0 {
+		refs = v.confirmWithLLM(ctx, refs, articles, &supportedClaims)
+	}
+
+	score := 1.0
+	if totalClaims > 0 {
+		score = float64(supportedClaims) / float64(totalClaims)
+	}
+	summary.GroundingScore = &score
+
+	for _, ref := range refs {
+		summary.UnsupportedClaims = append(summary.UnsupportedClaims, ref.missing...)
+		switch v.mode {
+		case GroundingMark:
+			sentences[ref.line][ref.index] = strings.TrimRightFunc(ref.text, unicode.IsSpace) + unverifiedMarker
+		case GroundingRemove:
+			sentences[ref.line][ref.index] = ""
+		}
+		v.logger.Printf("Unsupported sentence (%s): %s", strings.Join(ref.missing, ", "), ref.text)
+	}
+
+	var kept []string
+	for i, line := range lines {
+		if sentences[i] != nil {
+			body := joinSentences(sentences[i])
+			if body == "" {
+				// Every sentence of the line was removed
+				continue
+			}
+			line = prefixes[i] + body
+		}
+		kept = append(kept, line)
+	}
+	summary.Summary = strings.Join(kept, "\n")
+
+	if v.mode == GroundingRemove {
+		summary.TrendingStories = corpus.filterSupported(summary.TrendingStories)
+	}
+}
+
+// confirmWithLLM asks the model to re-check the flagged sentences against
+// the articles. Sentences the model considers supported are cleared.
+func (v *GroundingVerifier) confirmWithLLM(ctx context.Context, refs []*sentenceRef, articles []models.Article, supportedClaims *int) []*sentenceRef {
+	var sb strings.Builder
+	sb.WriteString("You are a fact checker. For each numbered claim below, decide whether it is supported by the source articles.\n")
+	sb.WriteString("Paraphrases and well-known aliases (e.g. Alphabet for Google) count as supported; invented names or figures do not.\n")
+	sb.WriteString("Reply with a JSON object only: {\"unsupported\": [numbers of unsupported claims]}\n\n")
+	sb.WriteString("Source articles:\n")
+	for _, article := range articles {
+		sb.WriteString(fmt.Sprintf("- %s. %s\n", article.Title, article.Desc))
+	}
+	sb.WriteString("\nClaims:\n")
+	for i, ref := range refs {
+		sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, ref.text))
+	}
+
+	params := v.params
+	params.Temperature = 0
+
+	verifyCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	result, err := v.analyzer.generate(verifyCtx, sb.String(), params)
+	if err != nil {
+		v.logger.Printf("LLM verification failed, keeping heuristic result: %v", err)
+		return refs
+	}
+
+	var verdict struct {
+		Unsupported []int `json:"unsupported"`
+	}
+	if err := parseJSONResponse(result.Text, &verdict); err != nil {
+		v.logger.Printf("LLM verification returned invalid JSON, keeping heuristic result: %v", err)
+		return refs
+	}
+
+	unsupported := make(map[int]bool, len(verdict.Unsupported))
+	for _, n := range verdict.Unsupported {
+		unsupported[n-1] = true
+	}
+
+	var confirmed []*sentenceRef
+	for i, ref := range refs {
+		if unsupported[i] {
+			confirmed = append(confirmed, ref)
+			continue
+		}
+		*supportedClaims += len(ref.missing)
+	}
+	return confirmed
+}
+
+// groundingCorpus is the normalized text of all input articles
+type groundingCorpus struct {
+	text   string
+	tokens map[string]bool
+	// lowercase holds the tokens that also occur in lowercase, i.e. as
+	// ordinary words rather than names
+	lowercase map[string]bool
+}
+
+func newGroundingCorpus(articles []models.Article) *groundingCorpus {
+	var sb strings.Builder
+	for _, article := range articles {
+		sb.WriteString(article.Title)
+		sb.WriteString(" ")
+		sb.WriteString(article.Desc)
+		sb.WriteString(" ")
+		sb.WriteString(article.Source)
+		sb.WriteString("\n")
+	}
+
+	text := strings.ToLower(sb.String())
+	tokens := make(map[string]bool)
+	for _, token := range strings.FieldsFunc(text, isTokenSeparator) {
+		tokens[token] = true
+	}
+
+	lowercase := make(map[string]bool)
+	for _, token := range strings.FieldsFunc(sb.String(), isTokenSeparator) {
+		if r, _ := utf8.DecodeRuneInString(token); unicode.IsLower(r) {
+			lowercase[token] = true
+		}
+	}
+
+	return &groundingCorpus{
+		text:      normalizeNumbers(text),
+		tokens:    tokens,
+		lowercase: lowercase,
+	}
+}
+
+// check returns the number of checkable claims in sentence and the ones
+// not found in the corpus
+func (c *groundingCorpus) check(sentence string) (int, []string) {
+	var claims int
+	var missing []string
+
+	clean := strings.NewReplacer("*", "", "_", "", "`", "").Replace(sentence)
+
+	for _, number := range numberPattern.FindAllString(clean, -1) {
+		if trivialNumber(number) {
+			continue
+		}
+		claims++
+		if !c.containsNumber(normalizeNumbers(strings.TrimPrefix(number, "$"))) {
+			missing = append(missing, number)
+		}
+	}
+
+	// The first word of a sentence is capitalized regardless of meaning,
+	// so it is skipped when the sources use it as an ordinary word
+	first := strings.IndexFunc(clean, unicode.IsLetter)
+
+	for _, loc := range entityPattern.FindAllStringIndex(clean, -1) {
+		fields := strings.Fields(clean[loc[0]:loc[1]])
+		if loc[0] == first && c.lowercase[strings.ToLower(fields[0])] {
+			fields = fields[1:]
+		}
+		words := significantWords(fields)
+		if len(words) == 0 {
+			continue
+		}
+		claims++
+		if !c.containsWords(words) {
+			missing = append(missing, strings.Join(words, " "))
+		}
+	}
+
+	return claims, missing
+}
+
+// containsNumber reports whether number occurs in the corpus as a whole
+// number, so "2" is not found inside "262" or "2.5"
+func (c *groundingCorpus) containsNumber(number string) bool {
+	for offset := 0; ; {
+		i := strings.Index(c.text[offset:], number)
+		if i < 0 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(number)
+		before := start > 0 && (isDigit(c.text[start-1]) || c.text[start-1] == '.')
+		after := end < len(c.text) && (isDigit(c.text[end]) ||
+			(c.text[end] == '.' && end+1 < len(c.text) && isDigit(c.text[end+1])))
+		if !before && !after {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+// containsWords reports whether every token of words occurs in the corpus
+func (c *groundingCorpus) containsWords(words []string) bool {
+	for _, word := range words {
+		for _, token := range strings.FieldsFunc(strings.ToLower(word), isTokenSeparator) {
+			if !c.tokens[token] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// filterSupported drops list items that contain unsupported claims
+func (c *groundingCorpus) filterSupported(items []string) []string {
+	var kept []string
+	for _, item := range items {
+		if _, missing := c.check(item); len(missing) == 0 {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// significantWords strips grammatical capitalized words from the words of
+// an entity
+func significantWords(fields []string) []string {
+	var words []string
+	for _, word := range fields {
+		word = strings.Trim(word, "-&+")
+		if len(word) < 2 || commonCapitalized[word] {
+			continue
+		}
+		words = append(words, word)
+	}
+	return words
+}
+
+// trivialNumber skips small counts and recent years, which the model
+// legitimately uses without them appearing in the sources
+func trivialNumber(number string) bool {
+	n, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimPrefix(number, "$"), ",", ""), 64)
+	if err != nil {
+		return true
+	}
+	year := float64(time.Now().Year())
+	if n == year || n == year-1 {
+		return true
+	}
+	return n <= 10 && !strings.HasPrefix(number, "$")
+}
+
+func normalizeNumbers(text string) string {
+	return strings.ReplaceAll(text, ",", "")
+}
+
+func isTokenSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// splitSentences splits a line at sentence boundaries, keeping the
+// trailing whitespace with each sentence so the line can be rebuilt
+func splitSentences(line string) []string {
+	var sentences []string
+	start := 0
+	runes := []rune(line)
+
+	for i := 0; i < len(runes)-1; i++ {
+		if !strings.ContainsRune(".!?", runes[i]) || !unicode.IsSpace(runes[i+1]) {
+			continue
+		}
+		// Skip abbreviations such as "U.S." or "Inc."
+		word := lastWord(string(runes[start : i+1]))
+		if strings.Count(word, ".") > 1 || abbreviations[word] {
+			continue
+		}
+		end := i + 1
+		for end < len(runes) && unicode.IsSpace(runes[end]) {
+			end++
+		}
+		sentences = append(sentences, string(runes[start:end]))
+		start = end
+		i = end - 1
+	}
+
+	if start < len(runes) {
+		sentences = append(sentences, string(runes[start:]))
+	}
+	return sentences
+}
+
+var abbreviations = map[string]bool{
+	"Inc.": true, "Corp.": true, "Ltd.": true, "Co.": true, "vs.": true, "e.g.": true, "i.e.": true,
+	"Mr.": true, "Ms.": true, "Dr.": true, "St.": true, "No.": true,
+}
+
+func lastWord(s string) string {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[len(fields)-1]
+}
+
+// joinSentences rebuilds a line, dropping removed sentences
+func joinSentences(sentences []string) string {
+	var sb strings.Builder
+	for _, sentence := range sentences {
+		if sentence == "" {
+			continue
+		}
+		if sb.Len() > 0 && !unicode.IsSpace(rune(sb.String()[sb.Len()-1])) {
+			sb.WriteString(" ")
+		}
+		sb.WriteString(sentence)
+	}
+	return sb.String()
+}
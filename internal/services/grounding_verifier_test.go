@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"reflect"
+	"strconv"
+	"strings"
+	"tech-news-agent/internal/config"
+	"tech-news-agent/internal/models"
+	"testing"
+	"time"
+)
+
+var groundingArticles = []models.Article{
+	{
+		Title:  "Nvidia reports $26,000 million revenue",
+		Desc:   "Revenue grew 262% as Jensen Huang touted Blackwell",
+		Source: "Reuters",
+	},
+	{
+		Title: "OpenAI ships GPT-5",
+		Desc:  "The model costs $1.25 per million tokens",
+	},
+}
+
+func TestSplitSentences(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"", nil},
+		{"No terminator", []string{"No terminator"}},
+		{"Sales fell 5%! Why? Nobody knows", []string{"Sales fell 5%! ", "Why? ", "Nobody knows"}},
+		{"Apple Inc. raised prices. Sales fell.", []string{"Apple Inc. raised prices. ", "Sales fell."}},
+		{"The U.S. market grew. Prices rose.", []string{"The U.S. market grew. ", "Prices rose."}},
+		{"Dr. Smith spoke e.g. about chips. Then left.", []string{"Dr. Smith spoke e.g. about chips. ", "Then left."}},
+		{"Version 1.5 shipped.Next up", []string{"Version 1.5 shipped.Next up"}},
+		{"Trailing space.  ", []string{"Trailing space.  "}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got := splitSentences(tt.line)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitSentences(%q) = %q, want %q", tt.line, got, tt.want)
+			}
+			if strings.Join(got, "") != tt.line {
+				t.Errorf("sentences %q do not rebuild the line", got)
+			}
+		})
+	}
+}
+
+func TestGroundingCorpusCheck(t *testing.T) {
+	year := time.Now().Year()
+	tests := []struct {
+		sentence    string
+		wantClaims  int
+		wantMissing []string
+	}{
+		{"Nvidia reported $26,000 million in revenue.", 2, nil},
+		{"Nvidia reported $26000 million in revenue.", 2, nil},
+		{"Revenue grew 262% at Nvidia.", 2, nil},
+		{"Revenue grew 300% at Nvidia.", 2, []string{"300"}},
+		// A number is not found inside a longer one
+		{"Google priced it at $2.", 2, []string{"$2", "Google"}},
+		{"OpenAI priced GPT-5 at $1.25 per million tokens.", 3, nil},
+		// Small counts and this or last year are not claims
+		{"In " + strconv.Itoa(year) + ", 3 companies shipped chips.", 0, nil},
+		{"Since " + strconv.Itoa(year-1) + " chips got faster.", 0, nil},
+		// A name that opens the sentence is still checked
+		{"Meanwhile Intel cut 15,000 jobs.", 2, []string{"15,000", "Intel"}},
+		{"Intel cut 15,000 jobs.", 2, []string{"15,000", "Intel"}},
+		{"Zorblax Labs released a chip.", 1, []string{"Zorblax Labs"}},
+		{"**Jensen Huang** touted Blackwell Ultra.", 2, []string{"Blackwell Ultra"}},
+		{"Reuters says so.", 1, nil},
+		{"OpenAI released GPT-5.", 2, nil},
+		// Ordinary words that open the sentence are not
+		{"Revenue grew at Nvidia.", 1, nil},
+		{"Analysts expect more.", 0, nil},
+	}
+
+	corpus := newGroundingCorpus(groundingArticles)
+	for _, tt := range tests {
+		t.Run(tt.sentence, func(t *testing.T) {
+			claims, missing := corpus.check(tt.sentence)
+			if claims != tt.wantClaims || !reflect.DeepEqual(missing, tt.wantMissing) {
+				t.Errorf("check(%q) = %d, %q; want %d, %q", tt.sentence, claims, missing, tt.wantClaims, tt.wantMissing)
+			}
+		})
+	}
+}
+
+func TestGroundingVerify(t *testing.T) {
+	const summary = "## Chips at Nvidia\n" +
+		"Revenue grew 262% at Nvidia. Intel cut 15,000 jobs. Demand stayed strong.\n\n" +
+		"- Jensen Huang touted Blackwell.\n" +
+		"- Analysts praised Zorblax Labs. Buyers waited.\n" +
+		"- Zorblax Labs shipped."
+	trending := []string{"Nvidia revenue grew 262%", "Zorblax Labs raised $40 million"}
+
+	tests := []struct {
+		mode         string
+		wantSummary  string
+		wantTrending []string
+	}{
+		{
+			mode: GroundingMark,
+			wantSummary: "## Chips at Nvidia\n" +
+				"Revenue grew 262% at Nvidia. Intel cut 15,000 jobs." + unverifiedMarker + " Demand stayed strong.\n\n" +
+				"- Jensen Huang touted Blackwell.\n" +
+				"- Analysts praised Zorblax Labs." + unverifiedMarker + " Buyers waited.\n" +
+				"- Zorblax Labs shipped." + unverifiedMarker,
+			wantTrending: trending,
+		},
+		{
+			mode: GroundingRemove,
+			wantSummary: "## Chips at Nvidia\n" +
+				"Revenue grew 262% at Nvidia. Demand stayed strong.\n\n" +
+				"- Jensen Huang touted Blackwell.\n" +
+				"- Buyers waited.",
+			wantTrending: trending[:1],
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			s := &models.NewsSummary{Summary: summary, TrendingStories: trending}
+			verifier := NewGroundingVerifier(tt.mode, false, nil, config.GenerationParams{}, log.New(io.Discard, "", 0))
+			verifier.Verify(context.Background(), s, groundingArticles)
+
+			if s.Summary != tt.wantSummary {
+				t.Errorf("summary =\n%s\nwant\n%s", s.Summary, tt.wantSummary)
+			}
+			if !reflect.DeepEqual(s.TrendingStories, tt.wantTrending) {
+				t.Errorf("trending stories = %q, want %q", s.TrendingStories, tt.wantTrending)
+			}
+			if want := []string{"15,000", "Intel", "Zorblax Labs", "Zorblax Labs"}; !reflect.DeepEqual(s.UnsupportedClaims, want) {
+				t.Errorf("unsupported claims = %q, want %q", s.UnsupportedClaims, want)
+			}
+			// 262%, Nvidia, Jensen Huang and Blackwell of 8 claims
+			if s.GroundingScore == nil {
+				t.Fatal("grounding score not set")
+			}
+			if *s.GroundingScore != 4.0/8 {
+				t.Errorf("grounding score = %v, want 4/8", *s.GroundingScore)
+			}
+		})
+	}
+}
+
+func TestGroundingScoreZeroIsReported(t *testing.T) {
+	s := &models.NewsSummary{Summary: "Zorblax Labs raised $40 million."}
+	NewGroundingVerifier(GroundingMark, false, nil, config.GenerationParams{}, log.New(io.Discard, "", 0)).
+		Verify(context.Background(), s, groundingArticles)
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("encoding summary: %v", err)
+	}
+	if !strings.Contains(string(data), `"groundingScore":0,`) {
+		t.Errorf("a score of 0 is missing from %s", data)
+	}
+}
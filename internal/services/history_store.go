@@ -0,0 +1,141 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"tech-news-agent/internal/models"
+	"time"
+)
+
+// historyDateFormat names one history file per digest day
+const historyDateFormat = "2006-01-02"
+
+// HistoryStore keeps past digest runs as JSON files under DATA_DIR/history
+type HistoryStore struct {
+	dir string
+}
+
+// StoredRun is a stored run and the modification time of its file, which
+// changes whenever the run is saved again
+type StoredRun struct {
+	Entry   *models.HistoryEntry
+	ModTime time.Time
+}
+
+// NewHistoryStore creates a new history store instance
+func NewHistoryStore(dataDir string) *HistoryStore {
+	return &HistoryStore{dir: filepath.Join(dataDir, "history")}
+}
+
+// Save stores entry, replacing an earlier run from the same day
+func (h *HistoryStore) Save(entry *models.HistoryEntry) error {
+	if err := os.MkdirAll(h.dir, 0o755); err != nil {
+		return fmt.Errorf("creating history directory: %w", err)
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encoding history entry: %w", err)
+	}
+
+	path := filepath.Join(h.dir, entry.PeriodEnd.Format(historyDateFormat)+".json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("writing history entry: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("writing history entry: %w", err)
+	}
+	return nil
+}
+
+// Previous returns the latest run from a day before the given time, or
+// nil when there is none
+func (h *HistoryStore) Previous(before time.Time) (*models.HistoryEntry, error) {
+	days, err := h.days()
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := before.Format(historyDateFormat)
+	for i := len(days) - 1; i >= 0; i-- {
+		if days[i] < cutoff {
+			run, err := h.load(days[i])
+			return run.Entry, err
+		}
+	}
+	return nil, nil
+}
+
+// Since returns all runs from the given time onwards, oldest first
+func (h *HistoryStore) Since(since time.Time) ([]StoredRun, error) {
+	days, err := h.days()
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := since.Format(historyDateFormat)
+	var runs []StoredRun
+	for _, day := range days {
+		if day < cutoff {
+			continue
+		}
+		run, err := h.load(day)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// days lists the stored run dates in ascending order
+func (h *HistoryStore) days() ([]string, error) {
+	files, err := os.ReadDir(h.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading history directory: %w", err)
+	}
+
+	var days []string
+	for _, f := range files {
+		day, ok := strings.CutSuffix(f.Name(), ".json")
+		if !ok || f.IsDir() {
+			continue
+		}
+		if _, err := time.Parse(historyDateFormat, day); err != nil {
+			continue
+		}
+		days = append(days, day)
+	}
+	sort.Strings(days)
+	return days, nil
+}
+
+func (h *HistoryStore) load(day string) (StoredRun, error) {
+	// Save replaces the file by renaming, so the open file and its
+	// modification time always belong together
+	f, err := os.Open(filepath.Join(h.dir, day+".json"))
+	if err != nil {
+		return StoredRun{}, fmt.Errorf("reading history entry %s: %w", day, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return StoredRun{}, fmt.Errorf("reading history entry %s: %w", day, err)
+	}
+
+	var entry models.HistoryEntry
+	if err := json.NewDecoder(f).Decode(&entry); err != nil {
+		return StoredRun{}, fmt.Errorf("decoding history entry %s: %w", day, err)
+	}
+	return StoredRun{Entry: &entry, ModTime: info.ModTime()}, nil
+}
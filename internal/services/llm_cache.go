@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"tech-news-agent/internal/config"
+	"time"
+)
+
+// Cache modes selected from the command line
+const (
+	CacheModeDefault = ""        // read and write the cache
+	CacheModeBypass  = "bypass"  // neither read nor write
+	CacheModeRefresh = "refresh" // skip reads, overwrite entries
+)
+
+// LLMCache stores LLM responses on disk, keyed by provider, model,
+// generation parameters and prompt hash
+type LLMCache struct {
+	dir string
+	ttl time.Duration
+}
+
+type llmCacheEntry struct {
+	CreatedAt time.Time  `json:"createdAt"`
+	Provider  string     `json:"provider"`
+	Model     string     `json:"model"`
+	Result    *LLMResult `json:"result"`
+}
+
+// NewLLMCache creates a new on-disk LLM cache instance
+func NewLLMCache(dir string, ttl time.Duration) (*LLMCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating cache directory: %w", err)
+	}
+	return &LLMCache{dir: dir, ttl: ttl}, nil
+}
+
+// Key builds the cache key for a single LLM call
+func (c *LLMCache) Key(provider, model string, params config.GenerationParams, prompt string) string {
+	promptHash := sha256.Sum256([]byte(prompt))
+	keyData, _ := json.Marshal(struct {
+		Provider   string
+		Model      string
+		Params     config.GenerationParams
+		PromptHash string
+	}{provider, model, params, hex.EncodeToString(promptHash[:])})
+
+	sum := sha256.Sum256(keyData)
+	return hex.EncodeToString(sum[:])
+}
+
+// Get returns the cached result for key if present and not expired
+func (c *LLMCache) Get(key string) (*LLMResult, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry llmCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Result == nil {
+		return nil, false
+	}
+	if c.ttl > 0 && time.Since(entry.CreatedAt) > c.ttl {
+		_ = os.Remove(c.path(key))
+		return nil, false
+	}
+
+	return entry.Result, true
+}
+
+// Put stores result under key
+func (c *LLMCache) Put(key string, result *LLMResult) error {
+	data, err := json.Marshal(llmCacheEntry{
+		CreatedAt: time.Now(),
+		Provider:  result.Provider,
+		Model:     result.Model,
+		Result:    result,
+	})
+	if err != nil {
+		return fmt.Errorf("encoding cache entry: %w", err)
+	}
+
+	// Write to a temp file first so concurrent readers never see partial entries
+	tmp, err := os.CreateTemp(c.dir, "entry-*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating cache entry: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+	return os.Rename(tmp.Name(), c.path(key))
+}
+
+func (c *LLMCache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+// cachingProvider wraps an LLMProvider with the on-disk cache
+type cachingProvider struct {
+	LLMProvider
+	cache *LLMCache
+	mode  string
+}
+
+// withCache wraps provider unless caching is bypassed
+func withCache(provider LLMProvider, cache *LLMCache, mode string) LLMProvider {
+	if cache == nil || mode == CacheModeBypass {
+		return provider
+	}
+	return &cachingProvider{LLMProvider: provider, cache: cache, mode: mode}
+}
+
+// Lookup returns the cached result for prompt, if any, without calling the
+// provider
+func (p *cachingProvider) Lookup(prompt string, params config.GenerationParams) (*LLMResult, bool) {
+	if p.mode == CacheModeRefresh {
+		return nil, false
+	}
+	result, ok := p.cache.Get(p.cache.Key(p.Name(), p.Model(), params, prompt))
+	if !ok {
+		return nil, false
+	}
+	cached := *result
+	cached.Cached = true
+	return &cached, true
+}
+
+// Generate serves the result from the cache when possible
+func (p *cachingProvider) Generate(ctx context.Context, prompt string, params config.GenerationParams) (*LLMResult, error) {
+	if result, ok := p.Lookup(prompt, params); ok {
+		return result, nil
+	}
+
+	result, err := p.LLMProvider.Generate(ctx, prompt, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// A failed cache write should never fail the run
+	_ = p.cache.Put(p.cache.Key(p.Name(), p.Model(), params, prompt), result)
+	return result, nil
+}
+
+// cachedResult returns the cached result of provider for prompt when the
+// provider is cached
+func cachedResult(provider LLMProvider, prompt string, params config.GenerationParams) (*LLMResult, bool) {
+	cached, ok := provider.(*cachingProvider)
+	if !ok {
+		return nil, false
+	}
+	return cached.Lookup(prompt, params)
+}
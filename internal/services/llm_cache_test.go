@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"tech-news-agent/internal/config"
+	"testing"
+	"time"
+)
+
+// fakeLLM fails its first calls with errs in order, then answers with
+// reply (or "ok" when reply is nil). It counts every call.
+type fakeLLM struct {
+	name  string
+	reply func(prompt string) string
+	errs  []error
+
+	mu    sync.Mutex
+	calls int
+}
+
+func (l *fakeLLM) Name() string                                 { return l.name }
+func (l *fakeLLM) Model() string                                { return l.name + "-model" }
+func (l *fakeLLM) ValidateParams(config.GenerationParams) error { return nil }
+func (l *fakeLLM) Close() error                                 { return nil }
+
+func (l *fakeLLM) Generate(ctx context.Context, prompt string, params config.GenerationParams) (*LLMResult, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.calls++
+	if len(l.errs) > 0 {
+		err := l.errs[0]
+		l.errs = l.errs[1:]
+		return nil, err
+	}
+	text := "ok"
+	if l.reply != nil {
+		text = l.reply(prompt)
+	}
+	return &LLMResult{Text: text, Provider: l.Name(), Model: l.Model()}, nil
+}
+
+func (l *fakeLLM) callCount() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.calls
+}
+
+func TestLLMCacheKey(t *testing.T) {
+	cache := &LLMCache{}
+	params := config.DefaultGenerationParams()
+	base := cache.Key("gemini", "flash", params, "prompt")
+
+	changed := params
+	changed.Temperature = 0.2
+	stop := params
+	stop.StopSequences = []string{"END"}
+
+	tests := []struct {
+		name string
+		key  string
+		same bool
+	}{
+		{"same call", cache.Key("gemini", "flash", params, "prompt"), true},
+		{"prompt", cache.Key("gemini", "flash", params, "prompt "), false},
+		{"model", cache.Key("gemini", "pro", params, "prompt"), false},
+		{"provider", cache.Key("ollama", "flash", params, "prompt"), false},
+		{"temperature", cache.Key("gemini", "flash", changed, "prompt"), false},
+		{"stop sequences", cache.Key("gemini", "flash", stop, "prompt"), false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.key == base; got != tt.same {
+			t.Errorf("%s: key equal to the base key = %v, want %v", tt.name, got, tt.same)
+		}
+	}
+}
+
+func TestLLMCacheGet(t *testing.T) {
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		entry   string
+		age     time.Duration
+		wantHit bool
+	}{
+		{name: "fresh", ttl: time.Hour, age: time.Minute, wantHit: true},
+		{name: "expired", ttl: time.Hour, age: 2 * time.Hour},
+		{name: "no expiry", ttl: 0, age: 1000 * time.Hour, wantHit: true},
+		{name: "corrupt", ttl: time.Hour, entry: `{"createdAt": `},
+		{name: "without result", ttl: time.Hour, entry: `{"createdAt": "2026-10-12T08:00:00Z"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache, err := NewLLMCache(t.TempDir(), tt.ttl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			key := cache.Key("gemini", "flash", config.GenerationParams{}, tt.name)
+
+			data := []byte(tt.entry)
+			if tt.entry == "" {
+				data, _ = json.Marshal(llmCacheEntry{
+					CreatedAt: time.Now().Add(-tt.age),
+					Result:    &LLMResult{Text: "cached"},
+				})
+			}
+			if err := os.WriteFile(cache.path(key), data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			result, ok := cache.Get(key)
+			if ok != tt.wantHit {
+				t.Fatalf("Get hit = %v, want %v", ok, tt.wantHit)
+			}
+			if ok && result.Text != "cached" {
+				t.Errorf("Get = %q, want the cached text", result.Text)
+			}
+			// Expired entries are removed
+			if _, err := os.Stat(cache.path(key)); tt.entry == "" && !tt.wantHit && !os.IsNotExist(err) {
+				t.Errorf("expired entry was not removed: %v", err)
+			}
+		})
+	}
+}
+
+func TestCachingProviderModes(t *testing.T) {
+	tests := []struct {
+		mode string
+		// calls made by two identical Generate calls
+		wantCalls int
+		// whether the second result is served from the cache
+		wantCached bool
+		// whether an entry is written
+		wantEntry bool
+	}{
+		{CacheModeDefault, 1, true, true},
+		{CacheModeBypass, 2, false, false},
+		{CacheModeRefresh, 2, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run("mode "+tt.mode, func(t *testing.T) {
+			dir := t.TempDir()
+			cache, err := NewLLMCache(dir, time.Hour)
+			if err != nil {
+				t.Fatal(err)
+			}
+			llm := &fakeLLM{name: "fake"}
+			provider := withCache(llm, cache, tt.mode)
+
+			var result *LLMResult
+			for i := 0; i < 2; i++ {
+				if result, err = provider.Generate(context.Background(), "prompt", config.GenerationParams{}); err != nil {
+					t.Fatalf("Generate: %v", err)
+				}
+			}
+			if llm.callCount() != tt.wantCalls {
+				t.Errorf("provider called %d times, want %d", llm.callCount(), tt.wantCalls)
+			}
+			if result.Cached != tt.wantCached {
+				t.Errorf("second result cached = %v, want %v", result.Cached, tt.wantCached)
+			}
+			entries, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+			if (len(entries) > 0) != tt.wantEntry {
+				t.Errorf("cache holds %d entries, want an entry: %v", len(entries), tt.wantEntry)
+			}
+		})
+	}
+}
+
+func TestCachingProviderReplacesCorruptEntry(t *testing.T) {
+	cache, err := NewLLMCache(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	llm := &fakeLLM{name: "fake"}
+	provider := withCache(llm, cache, CacheModeDefault)
+
+	key := cache.Key(llm.Name(), llm.Model(), config.GenerationParams{}, "prompt")
+	if err := os.WriteFile(cache.path(key), []byte("not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := provider.Generate(context.Background(), "prompt", config.GenerationParams{}); err != nil {
+			t.Fatalf("Generate: %v", err)
+		}
+	}
+	// The corrupt entry is a miss and is overwritten by the fresh answer
+	if llm.callCount() != 1 {
+		t.Errorf("provider called %d times, want 1", llm.callCount())
+	}
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"tech-news-agent/internal/config"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+var (
+	// ErrEmptyResponse is returned when a provider answers without any content
+	ErrEmptyResponse = errors.New("no response generated")
+	// ErrSafetyBlocked is returned when the prompt or the response was blocked by safety filters
+	ErrSafetyBlocked = errors.New("response blocked by safety filters")
+	// ErrTruncated is returned when generation stopped at the output token limit
+	ErrTruncated = errors.New("response truncated at max output tokens")
+)
+
+// LLMProvider is a single model that can generate text from a prompt
+type LLMProvider interface {
+	// Name identifies the provider, e.g. "gemini" or "ollama"
+	Name() string
+	// Model returns the model used by the provider
+	Model() string
+	// Generate produces a completion for the prompt
+	Generate(ctx context.Context, prompt string, params config.GenerationParams) (*LLMResult, error)
+	// ValidateParams checks params against the provider's allowed ranges
+	ValidateParams(params config.GenerationParams) error
+	// Close releases the provider's resources
+	Close() error
+}
+
+// LLMResult is the text produced by a provider along with its metadata
+type LLMResult struct {
+	Text             string
+	Provider         string
+	Model            string
+	FinishReason     string
+	PromptTokens     int
+	CompletionTokens int
+	// Cached is set when the result was served from the LLM cache
+	Cached bool `json:"-"`
+}
+
+// httpStatusError reports a non-200 answer from an HTTP based provider
+type httpStatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("API returned status %d: %s", e.StatusCode, e.Body)
+}
+
+// checkRange reports an error when value is outside [lo, hi]
+func checkRange[T int32 | float32](name string, value, lo, hi T) error {
+	if value < lo || value > hi {
+		return fmt.Errorf("%s must be between %v and %v, got %v", name, lo, hi, value)
+	}
+	return nil
+}
+
+// isTransient reports whether err is worth retrying on the same provider.
+// Nothing is once ctx is done, since a deadline that has passed will not
+// come back.
+func isTransient(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, ErrSafetyBlocked) || errors.Is(err, ErrTruncated) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		// A single request timed out while there is still time left
+		return true
+	}
+
+	var gErr *googleapi.Error
+	if errors.As(err, &gErr) {
+		return isRetryableStatus(gErr.Code)
+	}
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		return isRetryableStatus(statusErr.StatusCode)
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return errors.Is(err, ErrEmptyResponse)
+}
+
+func isRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// backoff returns the delay before the given retry attempt (1-based),
+// doubling from one second up to 30s with up to 50% jitter
+func backoff(attempt int) time.Duration {
+	// The cap is reached after six attempts; larger shifts would overflow
+	attempt = max(1, min(attempt, 6))
+	base := time.Second << (attempt - 1)
+	if base > 30*time.Second {
+		base = 30 * time.Second
+	}
+	return base + time.Duration(rand.Int63n(int64(base/2)+1))
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
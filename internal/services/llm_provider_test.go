@@ -0,0 +1,26 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{1, time.Second, 1500 * time.Millisecond},
+		{3, 4 * time.Second, 6 * time.Second},
+		{6, 30 * time.Second, 45 * time.Second},
+		// Large retry counts must not overflow the shift
+		{35, 30 * time.Second, 45 * time.Second},
+		{100, 30 * time.Second, 45 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := backoff(tt.attempt); got < tt.min || got > tt.max {
+			t.Errorf("backoff(%d) = %v, want between %v and %v", tt.attempt, got, tt.min, tt.max)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"tech-news-agent/internal/config"
+	"tech-news-agent/internal/models"
+)
+
+// marketBatchSize is the number of business articles annotated per LLM call
+const marketBatchSize = 20
+
+// sentimentThreshold is the net share of positive over negative stories
+// needed before an aggregate is called positive or negative
+const sentimentThreshold = 0.2
+
+// MarketAnalyzer tags business stories with sentiment and the companies
+// they mention, and aggregates them into a market pulse
+type MarketAnalyzer struct {
+	analyzer  *AIAnalyzer
+	params    config.GenerationParams
+	companies *CompanyIndex
+	logger    *log.Logger
+}
+
+// NewMarketAnalyzer creates a new market analyzer instance
+func NewMarketAnalyzer(analyzer *AIAnalyzer, params config.GenerationParams, companies []config.Company, logger *log.Logger) *MarketAnalyzer {
+	return &MarketAnalyzer{
+		analyzer:  analyzer,
+		params:    params,
+		companies: NewCompanyIndex(companies),
+		logger:    logger,
+	}
+}
+
+// Annotate adds market annotations to the business articles. Articles of
+// other categories, and batches the model fails on, are returned unchanged.
+func (m *MarketAnalyzer) Annotate(ctx context.Context, articles []models.Article) []models.Article {
+	annotated := make([]models.Article, len(articles))
+	copy(annotated, articles)
+
+	var business []int
+	for i, article := range annotated {
+		if isBusiness(article) {
+			business = append(business, i)
+		}
+	}
+
+	for start := 0; start < len(business); start += marketBatchSize {
+		batch := business[start:min(start+marketBatchSize, len(business))]
+		if err := m.annotateBatch(ctx, annotated, batch); err != nil {
+			m.logger.Printf("⚠️ Market annotation failed for %d articles: %v", len(batch), err)
+		}
+	}
+
+	return annotated
+}
+
+func (m *MarketAnalyzer) annotateBatch(ctx context.Context, articles []models.Article, batch []int) error {
+	var sb strings.Builder
+	sb.WriteString("You are a markets analyst. For each numbered business news article below, judge its sentiment for the companies involved and list the companies it names.\n")
+	sb.WriteString("Reply with a JSON object only: {\"articles\": [{\"n\": number, \"sentiment\": \"positive\"|\"neutral\"|\"negative\", \"companies\": [company names]}]}\n\n")
+	for n, i := range batch {
+		sb.WriteString(fmt.Sprintf("%d. %s\n", n+1, articles[i].Title))
+		if articles[i].Desc != "" {
+			sb.WriteString(fmt.Sprintf("   %s\n", articles[i].Desc))
+		}
+	}
+
+	result, err := m.analyzer.generate(ctx, sb.String(), m.params)
+	if err != nil {
+		return err
+	}
+
+	var response struct {
+		Articles []struct {
+			N         int      `json:"n"`
+			Sentiment string   `json:"sentiment"`
+			Companies []string `json:"companies"`
+		} `json:"articles"`
+	}
+	if err := parseJSONResponse(result.Text, &response); err != nil {
+		return err
+	}
+
+	for _, a := range response.Articles {
+		if a.N < 1 || a.N > len(batch) {
+			continue
+		}
+		article := &articles[batch[a.N-1]]
+
+		// Names the model missed but the company list recognizes still count
+		names := append(a.Companies, m.companies.Find(article.Title+" "+article.Desc)...)
+
+		article.Market = &models.MarketAnnotation{
+			Sentiment: normalizeSentiment(a.Sentiment),
+			Companies: m.companies.Normalize(names),
+		}
+	}
+	return nil
+}
+
+// BuildMarketPulse aggregates the market annotations of articles. It
+// returns nil when no article is annotated.
+func BuildMarketPulse(articles []models.Article) *models.MarketPulse {
+	pulse := &models.MarketPulse{}
+	signals := make(map[string]*models.CompanySignal)
+	scores := make(map[string][3]int)
+
+	for _, article := range articles {
+		market := article.Market
+		if market == nil {
+			continue
+		}
+		switch market.Sentiment {
+		case models.SentimentPositive:
+			pulse.Positive++
+		case models.SentimentNegative:
+			pulse.Negative++
+		default:
+			pulse.Neutral++
+		}
+
+		for _, company := range market.Companies {
+			signal, ok := signals[company.Name]
+			if !ok {
+				signal = &models.CompanySignal{CompanyRef: company}
+				signals[company.Name] = signal
+			}
+			signal.Mentions++
+			counts := scores[company.Name]
+			counts[sentimentIndex(market.Sentiment)]++
+			scores[company.Name] = counts
+		}
+	}
+
+	if pulse.Positive+pulse.Neutral+pulse.Negative == 0 {
+		return nil
+	}
+	pulse.Sentiment = aggregateSentiment(pulse.Positive, pulse.Neutral, pulse.Negative)
+
+	for name, signal := range signals {
+		counts := scores[name]
+		signal.Sentiment = aggregateSentiment(counts[0], counts[1], counts[2])
+		pulse.Companies = append(pulse.Companies, *signal)
+	}
+	sort.Slice(pulse.Companies, func(i, j int) bool {
+		if pulse.Companies[i].Mentions != pulse.Companies[j].Mentions {
+			return pulse.Companies[i].Mentions > pulse.Companies[j].Mentions
+		}
+		return pulse.Companies[i].Name < pulse.Companies[j].Name
+	})
+	if len(pulse.Companies) > 8 {
+		pulse.Companies = pulse.Companies[:8]
+	}
+
+	return pulse
+}
+
+func isBusiness(article models.Article) bool {
+	if article.Enrichment != nil {
+		return article.Enrichment.Category == "business"
+	}
+	return article.Category == "business"
+}
+
+func normalizeSentiment(sentiment string) string {
+	switch strings.ToLower(strings.TrimSpace(sentiment)) {
+	case models.SentimentPositive:
+		return models.SentimentPositive
+	case models.SentimentNegative:
+		return models.SentimentNegative
+	default:
+		return models.SentimentNeutral
+	}
+}
+
+func sentimentIndex(sentiment string) int {
+	switch sentiment {
+	case models.SentimentPositive:
+		return 0
+	case models.SentimentNegative:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// aggregateSentiment labels a set of stories by their net sentiment
+func aggregateSentiment(positive, neutral, negative int) string {
+	total := positive + neutral + negative
+	if total == 0 {
+		return models.SentimentNeutral
+	}
+	net := float64(positive-negative) / float64(total)
+	switch {
+	case net > sentimentThreshold:
+		return models.SentimentPositive
+	case net < -sentimentThreshold:
+		return models.SentimentNegative
+	default:
+		return models.SentimentNeutral
+	}
+}
+
+// CompanyIndex normalizes company names and aliases to the configured list
+type CompanyIndex struct {
+	byAlias map[string]config.Company
+	pattern *regexp.Regexp
+}
+
+// NewCompanyIndex creates a new company index
+func NewCompanyIndex(companies []config.Company) *CompanyIndex {
+	index := &CompanyIndex{byAlias: make(map[string]config.Company)}
+
+	var names []string
+	for _, company := range companies {
+		for _, name := range append([]string{company.Name}, company.Aliases...) {
+			if name = strings.TrimSpace(name); name == "" {
+				continue
+			}
+			index.byAlias[strings.ToLower(name)] = company
+			names = append(names, name)
+		}
+		// A bare ticker like NET or ARM is too common a word to search for,
+		// so text only matches the cashtag while names still normalize
+		if ticker := strings.TrimSpace(company.Ticker); ticker != "" {
+			index.byAlias[strings.ToLower(ticker)] = company
+			index.byAlias["$"+strings.ToLower(ticker)] = company
+			names = append(names, "$"+ticker)
+		}
+	}
+
+	// Longer names first so "Amazon Web Services" wins over "Amazon"
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+
+	if len(names) > 0 {
+		alternatives := make([]string, len(names))
+		for i, name := range names {
+			alternatives[i] = edgeBoundary(name[0]) + regexp.QuoteMeta(name) + edgeBoundary(name[len(name)-1])
+		}
+		index.pattern = regexp.MustCompile(strings.Join(alternatives, "|"))
+	}
+	return index
+}
+
+// edgeBoundary returns the assertion for an edge of a name whose character
+// there is c: a word boundary for a word character, so "Apple" does not
+// match in "Applebee's", and no boundary otherwise, so "C++" matches
+// before a space but not in "C++x"
+func edgeBoundary(c byte) string {
+	if c == '_' || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') {
+		return `\b`
+	}
+	return `\B`
+}
+
+// Find returns the listed company names and aliases, and the tickers
+// written as cashtags ("$NVDA"), mentioned in text. Matching is
+// case-sensitive to avoid hits on words like "apple" or "arm".
+func (c *CompanyIndex) Find(text string) []string {
+	if c.pattern == nil {
+		return nil
+	}
+	return c.pattern.FindAllString(text, -1)
+}
+
+// Normalize maps names to listed companies, keeping unlisted names as they
+// are, and removes duplicates
+func (c *CompanyIndex) Normalize(names []string) []models.CompanyRef {
+	seen := make(map[string]bool)
+	var refs []models.CompanyRef
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		ref := models.CompanyRef{Name: name}
+		if company, ok := c.byAlias[strings.ToLower(name)]; ok {
+			ref = models.CompanyRef{Name: company.Name, Ticker: company.Ticker}
+		}
+		if seen[ref.Name] {
+			continue
+		}
+		seen[ref.Name] = true
+		refs = append(refs, ref)
+	}
+	return refs
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"reflect"
+	"tech-news-agent/internal/config"
+	"tech-news-agent/internal/models"
+	"testing"
+)
+
+var testCompanies = []config.Company{
+	{Name: "Apple", Ticker: "AAPL"},
+	{Name: "Amazon", Ticker: "AMZN", Aliases: []string{"Amazon Web Services", "AWS"}},
+	{Name: "Cloudflare", Ticker: "NET"},
+	{Name: "AT&T", Ticker: "T"},
+	{Name: "Microsoft", Ticker: "MSFT", Aliases: []string{".NET", "C++ Builder"}},
+	{Name: "Standard C++ Foundation", Aliases: []string{"C++"}},
+}
+
+func TestCompanyIndexFind(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Apple and Amazon Web Services sign a deal", []string{"Apple", "Amazon Web Services"}},
+		{"Applebee's opens in Pineapple", nil},
+		{"apple pie", nil},
+		{"Apple,AWS;Amazon.", []string{"Apple", "AWS", "Amazon"}},
+		// Tickers only match as cashtags
+		{"NET gains as $NET and $AAPL rise, $AAPLX does not", []string{"$NET", "$AAPL"}},
+		// Names ending or starting in punctuation
+		{"C++ 26 lands", []string{"C++"}},
+		{"Written in C++.", []string{"C++"}},
+		{"C++x is not C++", []string{"C++"}},
+		{"C++ Builder ships", []string{"C++ Builder"}},
+		{"AT&T and AT&Tx", []string{"AT&T"}},
+		{".NET 10 and (.NET) but not ASP.NET", []string{".NET", ".NET"}},
+	}
+
+	index := NewCompanyIndex(testCompanies)
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := index.Find(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Find(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompanyIndexNormalize(t *testing.T) {
+	index := NewCompanyIndex(testCompanies)
+	got := index.Normalize([]string{"aws", " Amazon ", "$amzn", "NET", "C++", "Acme", ""})
+	want := []models.CompanyRef{
+		{Name: "Amazon", Ticker: "AMZN"},
+		{Name: "Cloudflare", Ticker: "NET"},
+		{Name: "Standard C++ Foundation"},
+		{Name: "Acme"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Normalize = %+v, want %+v", got, want)
+	}
+}
+
+func TestCompanyIndexEmpty(t *testing.T) {
+	if got := NewCompanyIndex(nil).Find("Apple"); got != nil {
+		t.Errorf("Find with no companies = %q, want nil", got)
+	}
+}
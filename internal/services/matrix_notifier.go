@@ -0,0 +1,262 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"tech-news-agent/internal/config"
+	"tech-news-agent/internal/i18n"
+	"tech-news-agent/internal/models"
+	"tech-news-agent/internal/renderer"
+	"time"
+)
+
+// matrixMaxEvent keeps messages below the 64 KiB Matrix allows per event
+const matrixMaxEvent = 60000
+
+// matrixMaxRetries bounds the retries of a message on rate limits and
+// server errors
+const matrixMaxRetries = 3
+
+// Matrix events carry a markdown body for clients without HTML support
+// and the same content as formatted HTML
+var (
+	matrixText = renderer.New(renderer.CommonMark)
+	matrixHTML = renderer.New(renderer.HTML)
+)
+
+// MatrixNotifier posts digests to a Matrix room through the client-server
+// API, as the user owning the access token
+type MatrixNotifier struct {
+	homeserverURL string
+	accessToken   string
+	roomID        string
+	language      string
+	maxChars      int
+	httpClient    *http.Client
+}
+
+var _ Notifier = (*MatrixNotifier)(nil)
+
+// NewMatrixNotifier creates a new Matrix notifier for the room in dest
+func NewMatrixNotifier(cfg *config.Config, dest config.Destination) *MatrixNotifier {
+	return &MatrixNotifier{
+		homeserverURL: cfg.MatrixHomeserverURL,
+		accessToken:   cfg.MatrixAccessToken,
+		roomID:        dest.Target,
+		language:      i18n.Normalize(dest.Language),
+		maxChars:      dest.MaxChars,
+		httpClient:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Destination identifies the room
+func (mn *MatrixNotifier) Destination() string {
+	return "matrix:" + mn.roomID
+}
+
+// Language returns the digest language of the room
+func (mn *MatrixNotifier) Language() string {
+	return mn.language
+}
+
+// MaxChars returns the length budget of a digest (0 is unlimited)
+func (mn *MatrixNotifier) MaxChars() int {
+	return mn.maxChars
+}
+
+// matrixMessage is the content of an m.room.message event
+type matrixMessage struct {
+	MsgType       string `json:"msgtype"`
+	Body          string `json:"body"`
+	Format        string `json:"format,omitempty"`
+	FormattedBody string `json:"formatted_body,omitempty"`
+}
+
+func newMatrixMessage(msgType, body, formattedBody string) matrixMessage {
+	return matrixMessage{
+		MsgType:       msgType,
+		Body:          body,
+		Format:        "org.matrix.custom.html",
+		FormattedBody: formattedBody,
+	}
+}
+
+// SendSummary posts the digest, split over several messages when it
+// exceeds the event size limit
+func (mn *MatrixNotifier) SendSummary(ctx context.Context, summary *models.NewsSummary) error {
+	// Seeding transaction IDs with the generation time makes a resent
+	// digest reuse them, so the homeserver drops the duplicates. The index
+	// keeps two parts with the same content apart.
+	generated := strconv.FormatInt(summary.GeneratedAt.UnixNano(), 10)
+	for i, message := range mn.buildMessages(summary) {
+		if err := mn.send(ctx, generated+"/"+strconv.Itoa(i), message); err != nil {
+			return fmt.Errorf("sending message: %w", err)
+		}
+	}
+	return nil
+}
+
+// SendError posts an error notice
+func (mn *MatrixNotifier) SendError(ctx context.Context, errMsg string) error {
+	title := "⚠️ " + i18n.T(mn.language, i18n.ErrorTitle)
+	message := newMatrixMessage("m.notice", title+"\n\n"+errMsg, matrixHTML.Bold(title)+matrixHTML.CodeBlock(errMsg))
+	if err := mn.send(ctx, strconv.FormatInt(time.Now().UnixNano(), 10), message); err != nil {
+		return fmt.Errorf("sending error message: %w", err)
+	}
+	return nil
+}
+
+// TestConnection checks the access token and that its user has joined the
+// room, without posting anything
+func (mn *MatrixNotifier) TestConnection(ctx context.Context) error {
+	if err := mn.do(ctx, http.MethodGet, "/account/whoami", nil); err != nil {
+		return fmt.Errorf("checking access token: %w", err)
+	}
+	if err := mn.do(ctx, http.MethodGet, "/rooms/"+url.PathEscape(mn.roomID)+"/joined_members", nil); err != nil {
+		return fmt.Errorf("checking room membership: %w", err)
+	}
+	return nil
+}
+
+// FormatMessage renders the plain body of the digest
+func (mn *MatrixNotifier) FormatMessage(summary *models.NewsSummary) string {
+	var bodies []string
+	for _, message := range mn.buildMessages(summary) {
+		bodies = append(bodies, message.Body)
+	}
+	return strings.Join(bodies, "\n\n")
+}
+
+// matrixPart is one block of a digest in both representations
+type matrixPart struct {
+	body string
+	html string
+}
+
+func (mn *MatrixNotifier) buildMessages(summary *models.NewsSummary) []matrixMessage {
+	doc := renderer.Build(summary)
+
+	parts := []matrixPart{{body: matrixText.Header(doc), html: matrixHTML.Header(doc)}}
+	for _, section := range doc.Sections {
+		if len(section.Body) > 0 {
+			// Long summaries are split by paragraph, each rendered on its own
+			for _, chunk := range splitTextBytes(summary.Summary, matrixMaxEvent/3) {
+				parts = append(parts, matrixPart{body: matrixText.Markdown(chunk), html: matrixHTML.Markdown(chunk)})
+			}
+			continue
+		}
+		parts = append(parts, matrixPart{body: matrixText.Section(section), html: matrixHTML.Section(section)})
+	}
+	parts = append(parts, matrixPart{body: matrixText.Footer(doc), html: matrixHTML.Footer(doc)})
+
+	// Pack parts into messages whose encoded content stays within the event
+	// size limit
+	var messages []matrixMessage
+	var body, formatted []string
+	for _, part := range parts {
+		next := newMatrixMessage("m.text", strings.Join(append(body, part.body), "\n\n"), strings.Join(append(formatted, part.html), "\n"))
+		if len(body) > 0 && len(encodeMatrixContent(next)) > matrixMaxEvent {
+			messages = append(messages, newMatrixMessage("m.text", strings.Join(body, "\n\n"), strings.Join(formatted, "\n")))
+			body, formatted = nil, nil
+		}
+		body = append(body, part.body)
+		formatted = append(formatted, part.html)
+	}
+	return append(messages, newMatrixMessage("m.text", strings.Join(body, "\n\n"), strings.Join(formatted, "\n")))
+}
+
+// encodeMatrixContent encodes event content as JSON. HTML is not escaped,
+// which would grow every <, > and & of formatted bodies to six bytes.
+func encodeMatrixContent(message matrixMessage) []byte {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	// Encoding a struct of strings cannot fail
+	_ = enc.Encode(message)
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+}
+
+// send puts message into the room. The transaction ID is derived from the
+// room, seed and content, so retries of the same message are idempotent.
+func (mn *MatrixNotifier) send(ctx context.Context, seed string, message matrixMessage) error {
+	content := encodeMatrixContent(message)
+	sum := sha256.Sum256([]byte(mn.roomID + "\n" + seed + "\n" + string(content)))
+	txnID := "tna-" + hex.EncodeToString(sum[:16])
+	path := "/rooms/" + url.PathEscape(mn.roomID) + "/send/m.room.message/" + txnID
+
+	return mn.do(ctx, http.MethodPut, path, content)
+}
+
+// matrixError is the error body of the client-server API
+type matrixError struct {
+	ErrCode      string `json:"errcode"`
+	Error        string `json:"error"`
+	RetryAfterMS int64  `json:"retry_after_ms"`
+}
+
+// do calls the client-server API, retrying rate limits, server errors and
+// network failures with the same request
+func (mn *MatrixNotifier) do(ctx context.Context, method, path string, body []byte) error {
+	endpoint := mn.homeserverURL + "/_matrix/client/v3" + path
+
+	var lastErr error
+	var wait time.Duration
+	for attempt := 0; attempt <= matrixMaxRetries; attempt++ {
+		if attempt > 0 {
+			if err := sleepContext(ctx, wait); err != nil {
+				return err
+			}
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("creating request: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+mn.accessToken)
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		resp, err := mn.httpClient.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("making request: %w", err)
+			}
+			lastErr = fmt.Errorf("making request: %w", err)
+			wait = backoff(attempt + 1)
+			continue
+		}
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+		resp.Body.Close()
+
+		if resp.StatusCode == http.StatusOK {
+			return nil
+		}
+
+		var apiErr matrixError
+		_ = json.Unmarshal(respBody, &apiErr)
+		if apiErr.ErrCode != "" {
+			lastErr = fmt.Errorf("Matrix returned status %d: %s: %s", resp.StatusCode, apiErr.ErrCode, apiErr.Error)
+		} else {
+			lastErr = fmt.Errorf("Matrix returned status %d: %s", resp.StatusCode, string(respBody))
+		}
+		if !isRetryableStatus(resp.StatusCode) {
+			return lastErr
+		}
+
+		wait = backoff(attempt + 1)
+		if apiErr.RetryAfterMS > 0 {
+			wait = time.Duration(apiErr.RetryAfterMS) * time.Millisecond
+		}
+	}
+	return lastErr
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"tech-news-agent/internal/config"
+	"testing"
+	"time"
+)
+
+func newTestMatrixNotifier(homeserverURL, room string) *MatrixNotifier {
+	cfg := &config.Config{MatrixHomeserverURL: homeserverURL, MatrixAccessToken: "token"}
+	return NewMatrixNotifier(cfg, config.Destination{Kind: config.DestinationMatrix, Target: room, Language: "en"})
+}
+
+func TestMatrixTransactionIDs(t *testing.T) {
+	server, requests := recordingServer(t, replyStatuses(`{"errcode": "M_LIMIT_EXCEEDED", "retry_after_ms": 1}`, `{"event_id": "$1"}`, http.StatusTooManyRequests))
+	summary := longSummary(80)
+	// send delivers the summary and returns the path of every request
+	send := func(notifier *MatrixNotifier) []string {
+		t.Helper()
+		*requests = nil
+		if err := notifier.SendSummary(context.Background(), summary); err != nil {
+			t.Fatalf("SendSummary: %v", err)
+		}
+		var paths []string
+		for _, req := range *requests {
+			if auth := req.header.Get("Authorization"); auth != "Bearer token" {
+				t.Errorf("Authorization = %q", auth)
+			}
+			if req.method != http.MethodPut {
+				t.Errorf("method = %s, want PUT", req.method)
+			}
+			paths = append(paths, req.path)
+		}
+		return paths
+	}
+
+	room := newTestMatrixNotifier(server.URL, "!room:example.org")
+	first := send(room)
+	// The rate limited first message is retried with its transaction ID
+	if len(first) < 3 || first[0] != first[1] {
+		t.Fatalf("first delivery sent %q, want a retry of the first message", first)
+	}
+	first = first[1:]
+	seen := make(map[string]bool)
+	for _, path := range first {
+		if !strings.HasPrefix(path, "/_matrix/client/v3/rooms/") || !strings.Contains(path, "/send/m.room.message/tna-") {
+			t.Errorf("unexpected path %s", path)
+		}
+		if seen[path] {
+			t.Errorf("transaction ID reused within one digest: %s", path)
+		}
+		seen[path] = true
+	}
+
+	if again := send(room); strings.Join(again, " ") != strings.Join(first, " ") {
+		t.Errorf("resending the digest used new transaction IDs")
+	}
+
+	// Transaction IDs are per access token, so rooms sharing one must not
+	// share IDs
+	for _, path := range send(newTestMatrixNotifier(server.URL, "!other:example.org")) {
+		if seen[strings.Replace(path, "other", "room", 1)] {
+			t.Errorf("another room reused transaction ID %s", path)
+		}
+	}
+
+	summary.GeneratedAt = summary.GeneratedAt.Add(time.Hour)
+	for _, path := range send(room) {
+		if seen[path] {
+			t.Errorf("a new digest reused transaction ID %s", path)
+		}
+	}
+}
+
+func TestMatrixSplitsAtEventLimit(t *testing.T) {
+	tests := []struct {
+		paragraphs int
+		want       int
+	}{
+		{1, 1},
+		// The summary is split into chunks of a third of the limit, which
+		// leaves room for their HTML; two chunks do not share a message
+		{20, 2},
+		{80, 9},
+	}
+
+	notifier := newTestMatrixNotifier("http://matrix.invalid", "!room:example.org")
+	for _, tt := range tests {
+		summary := longSummary(tt.paragraphs)
+		messages := notifier.buildMessages(summary)
+		if len(messages) != tt.want {
+			t.Errorf("%d paragraphs: got %d messages, want %d", tt.paragraphs, len(messages), tt.want)
+		}
+
+		var body, formatted strings.Builder
+		for i, message := range messages {
+			if n := len(encodeMatrixContent(message)); n > matrixMaxEvent {
+				t.Errorf("%d paragraphs: message %d is %d bytes, limit %d", tt.paragraphs, i, n, matrixMaxEvent)
+			}
+			if message.MsgType != "m.text" || message.Format != "org.matrix.custom.html" {
+				t.Errorf("message %d has type %q and format %q", i, message.MsgType, message.Format)
+			}
+			body.WriteString(message.Body)
+			formatted.WriteString(message.FormattedBody)
+		}
+
+		// Every paragraph is sent once in both representations
+		want := strings.Count(summary.Summary, "Chipmakers")
+		if got := strings.Count(body.String(), "Chipmakers"); got != want {
+			t.Errorf("%d paragraphs: bodies repeat the summary %d times, want %d", tt.paragraphs, got, want)
+		}
+		if got := strings.Count(formatted.String(), "Chipmakers"); got != want {
+			t.Errorf("%d paragraphs: formatted bodies repeat the summary %d times, want %d", tt.paragraphs, got, want)
+		}
+	}
+}
+
+func TestEncodeMatrixContentKeepsHTML(t *testing.T) {
+	got := string(encodeMatrixContent(newMatrixMessage("m.text", "a & b", "<b>a &amp; b</b>")))
+	want := `{"msgtype":"m.text","body":"a & b","format":"org.matrix.custom.html","formatted_body":"<b>a &amp; b</b>"}`
+	if got != want {
+		t.Errorf("encodeMatrixContent = %s, want %s", got, want)
+	}
+	var decoded matrixMessage
+	if err := json.Unmarshal([]byte(got), &decoded); err != nil {
+		t.Errorf("content is not valid JSON: %v", err)
+	}
+}
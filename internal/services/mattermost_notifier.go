@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"tech-news-agent/internal/config"
+	"tech-news-agent/internal/i18n"
+	"tech-news-agent/internal/models"
+	"tech-news-agent/internal/renderer"
+	"time"
+)
+
+// mattermostMaxPost is the number of characters Mattermost accepts in one
+// post, attachments included
+const mattermostMaxPost = 16000
+
+// mattermostColor marks digest attachments
+const mattermostColor = "#1E88E5"
+
+var mattermostRenderer = renderer.New(renderer.CommonMark)
+
+// MattermostNotifier posts digests to a Mattermost incoming webhook, one
+// markdown attachment per section
+type MattermostNotifier struct {
+	webhookURL string
+	language   string
+	maxChars   int
+	httpClient *http.Client
+}
+
+var _ Notifier = (*MattermostNotifier)(nil)
+
+// NewMattermostNotifier creates a new Mattermost notifier for dest
+func NewMattermostNotifier(dest config.Destination) *MattermostNotifier {
+	return &MattermostNotifier{
+		webhookURL: dest.Target,
+		language:   i18n.Normalize(dest.Language),
+		maxChars:   dest.MaxChars,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Destination identifies the webhook
+func (mn *MattermostNotifier) Destination() string {
+	return webhookDestination("mattermost", mn.webhookURL)
+}
+
+// Language returns the digest language of the channel
+func (mn *MattermostNotifier) Language() string {
+	return mn.language
+}
+
+// MaxChars returns the length budget of a digest (0 is unlimited)
+func (mn *MattermostNotifier) MaxChars() int {
+	return mn.maxChars
+}
+
+type mattermostAttachment struct {
+	Fallback string `json:"fallback"`
+	Color    string `json:"color,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Text     string `json:"text"`
+	Footer   string `json:"footer,omitempty"`
+}
+
+type mattermostPost struct {
+	Text        string                 `json:"text,omitempty"`
+	Attachments []mattermostAttachment `json:"attachments,omitempty"`
+}
+
+// size is the post's share of Mattermost's length limit
+func (p mattermostPost) size() int {
+	n := runeLen(p.Text)
+	for _, a := range p.Attachments {
+		n += runeLen(a.Title) + runeLen(a.Text) + runeLen(a.Footer)
+	}
+	return n
+}
+
+// SendSummary posts the digest, split over several posts when it exceeds
+// Mattermost's length limit
+func (mn *MattermostNotifier) SendSummary(ctx context.Context, summary *models.NewsSummary) error {
+	for _, post := range mn.buildPosts(summary) {
+		if err := postJSON(ctx, mn.httpClient, mn.webhookURL, post); err != nil {
+			return fmt.Errorf("sending message: %w", err)
+		}
+	}
+	return nil
+}
+
+// SendError posts an error notification
+func (mn *MattermostNotifier) SendError(ctx context.Context, errMsg string) error {
+	text := fmt.Sprintf("⚠️ **%s**\n```\n%s\n```", i18n.T(mn.language, i18n.ErrorTitle), truncateRunes(errMsg, mattermostMaxPost/2))
+	if err := postJSON(ctx, mn.httpClient, mn.webhookURL, mattermostPost{Text: text}); err != nil {
+		return fmt.Errorf("sending error message: %w", err)
+	}
+	return nil
+}
+
+// TestConnection posts a test message to verify the webhook
+func (mn *MattermostNotifier) TestConnection(ctx context.Context) error {
+	if err := postJSON(ctx, mn.httpClient, mn.webhookURL, mattermostPost{Text: "✅ " + i18n.T(mn.language, i18n.Connected)}); err != nil {
+		return fmt.Errorf("test message failed: %w", err)
+	}
+	return nil
+}
+
+// FormatMessage renders the digest as one markdown document
+func (mn *MattermostNotifier) FormatMessage(summary *models.NewsSummary) string {
+	var parts []string
+	for _, post := range mn.buildPosts(summary) {
+		parts = append(parts, post.Text)
+		for _, a := range post.Attachments {
+			parts = append(parts, a.Title, a.Text, a.Footer)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+func (mn *MattermostNotifier) buildPosts(summary *models.NewsSummary) []mattermostPost {
+	doc := renderer.Build(summary)
+
+	header := fmt.Sprintf("#### %s %s\n", doc.Icon, doc.Title)
+	var meta []string
+	for _, item := range doc.Meta {
+		meta = append(meta, mattermostRenderer.Item(item))
+	}
+	header += strings.Join(meta, " · ")
+
+	var attachments []mattermostAttachment
+	for _, section := range doc.Sections {
+		content := mattermostRenderer.Content(section)
+		if len(section.Body) > 0 {
+			for _, chunk := range splitText(content, mattermostMaxPost/2) {
+				attachments = append(attachments, mattermostAttachment{Fallback: doc.Title, Color: mattermostColor, Text: chunk})
+			}
+			continue
+		}
+		title := section.Heading()
+		for _, chunk := range splitText(content, mattermostMaxPost/2) {
+			attachments = append(attachments, mattermostAttachment{Fallback: title, Color: mattermostColor, Title: title, Text: chunk})
+		}
+	}
+
+	var footer []string
+	for _, item := range doc.Footer {
+		footer = append(footer, mattermostRenderer.Item(item))
+	}
+	attachments = append(attachments, mattermostAttachment{Fallback: doc.Title, Text: strings.Join(footer, "\n")})
+
+	// Pack the attachments into as few posts as the length limit allows
+	posts := []mattermostPost{{Text: header}}
+	for _, attachment := range attachments {
+		current := &posts[len(posts)-1]
+		next := mattermostPost{Attachments: []mattermostAttachment{attachment}}
+		if len(current.Attachments) > 0 && current.size()+next.size() > mattermostMaxPost {
+			posts = append(posts, next)
+			continue
+		}
+		current.Attachments = append(current.Attachments, attachment)
+	}
+	return posts
+}
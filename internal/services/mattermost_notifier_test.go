@@ -0,0 +1,27 @@
+package services
+
+import (
+	"encoding/json"
+	"tech-news-agent/internal/config"
+	"testing"
+)
+
+func TestMattermostPostGolden(t *testing.T) {
+	notifier := NewMattermostNotifier(config.Destination{Kind: config.DestinationMattermost, Target: "https://mattermost.invalid/hooks/x", Language: "en"})
+	posts := notifier.buildPosts(goldenSummary())
+	if len(posts) != 1 {
+		t.Fatalf("got %d posts, want 1", len(posts))
+	}
+
+	data, err := json.MarshalIndent(posts[0], "", "  ")
+	if err != nil {
+		t.Fatalf("encoding post: %v", err)
+	}
+	checkGolden(t, "mattermost.json.golden", string(data)+"\n")
+
+	for _, a := range posts[0].Attachments {
+		if a.Fallback == "" {
+			t.Errorf("attachment %q has no fallback text", a.Title)
+		}
+	}
+}
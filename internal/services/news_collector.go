@@ -6,15 +6,19 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"slices"
+	"tech-news-agent/internal/langdetect"
 	"tech-news-agent/internal/models"
 	"time"
 )
 
 // NewsCollector handles fetching news from various sources
 type NewsCollector struct {
-	apiKey     string
-	maxResults int
-	httpClient *http.Client
+	apiKey           string
+	maxResults       int
+	languages        []string
+	allowedLanguages []string
+	httpClient       *http.Client
 }
 
 // NewsAPIResponse represents the response from NewsAPI
@@ -27,16 +31,19 @@ type NewsAPIResponse struct {
 		} `json:"source"`
 		Title       string `json:"title"`
 		Description string `json:"description"`
+		Content     string `json:"content"`
 		URL         string `json:"url"`
 		PublishedAt string `json:"publishedAt"`
 	} `json:"articles"`
 }
 
 // NewNewsCollector creates a new news collector instance
-func NewNewsCollector(apiKey string, maxResults int) *NewsCollector {
+func NewNewsCollector(apiKey string, maxResults int, languages, allowedLanguages []string) *NewsCollector {
 	return &NewsCollector{
-		apiKey:     apiKey,
-		maxResults: maxResults,
+		apiKey:           apiKey,
+		maxResults:       maxResults,
+		languages:        languages,
+		allowedLanguages: allowedLanguages,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
@@ -47,16 +54,23 @@ func NewNewsCollector(apiKey string, maxResults int) *NewsCollector {
 func (nc *NewsCollector) FetchWeeklyNews(categories []string) ([]models.Article, error) {
 	var allArticles []models.Article
 
-	for _, category := range categories {
-		articles, err := nc.fetchByCategory(category)
-		if err != nil {
-			// Log error but continue with other categories
-			fmt.Printf("Error fetching %s news: %v\n", category, err)
-			continue
+	// Split the article budget evenly across all category/language queries
+	pageSize := max(nc.maxResults/(len(categories)*len(nc.languages)), 1)
+
+	for _, language := range nc.languages {
+		for _, category := range categories {
+			articles, err := nc.fetchByCategory(category, language, pageSize)
+			if err != nil {
+				// Log error but continue with other categories
+				fmt.Printf("Error fetching %s news (%s): %v\n", category, language, err)
+				continue
+			}
+			allArticles = append(allArticles, articles...)
 		}
-		allArticles = append(allArticles, articles...)
 	}
 
+	allArticles = FilterByLanguage(allArticles, nc.allowedLanguages)
+
 	if len(allArticles) == 0 {
 		return nil, fmt.Errorf("no articles found")
 	}
@@ -64,7 +78,51 @@ func (nc *NewsCollector) FetchWeeklyNews(categories []string) ([]models.Article,
 	return allArticles, nil
 }
 
-func (nc *NewsCollector) fetchByCategory(category string) ([]models.Article, error) {
+// FilterByLanguage keeps the articles whose detected language is allowed.
+// An empty allow list keeps every article.
+func FilterByLanguage(articles []models.Article, allowed []string) []models.Article {
+	if len(allowed) == 0 {
+		return articles
+	}
+
+	filtered := articles[:0:0]
+	for _, article := range articles {
+		if slices.Contains(allowed, article.Language) {
+			filtered = append(filtered, article)
+		}
+	}
+	return filtered
+}
+
+// FilterByCategory keeps the articles in one of the given categories,
+// matching either the AI assigned category or the NewsAPI category.
+// An empty list keeps every article.
+func FilterByCategory(articles []models.Article, categories []string) []models.Article {
+	if len(categories) == 0 {
+		return articles
+	}
+
+	filtered := articles[:0:0]
+	for _, article := range articles {
+		if slices.Contains(categories, article.Category) ||
+			(article.Enrichment != nil && slices.Contains(categories, article.Enrichment.Category)) {
+			filtered = append(filtered, article)
+		}
+	}
+	return filtered
+}
+
+// detectLanguage identifies the article language from its title and
+// description, falling back to the language requested from the API
+func detectLanguage(title, desc, requested string) string {
+	lang, confidence := langdetect.Detect(title + ". " + desc)
+	if lang == langdetect.Unknown || confidence < 0.3 {
+		return requested
+	}
+	return lang
+}
+
+func (nc *NewsCollector) fetchByCategory(category, language string, pageSize int) ([]models.Article, error) {
 	// Calculate date range (last 7 days)
 	to := time.Now()
 	from := to.AddDate(0, 0, -7)
@@ -76,8 +134,8 @@ func (nc *NewsCollector) fetchByCategory(category string) ([]models.Article, err
 	params.Add("from", from.Format("2006-01-02"))
 	params.Add("to", to.Format("2006-01-02"))
 	params.Add("sortBy", "popularity")
-	params.Add("language", "en")
-	params.Add("pageSize", fmt.Sprintf("%d", nc.maxResults/len([]string{"politic", "sport", "business"})))
+	params.Add("language", language)
+	params.Add("pageSize", fmt.Sprintf("%d", pageSize))
 
 	fullURL := fmt.Sprintf("%s?%s", baseURL, params.Encode())
 
@@ -109,12 +167,15 @@ func (nc *NewsCollector) fetchByCategory(category string) ([]models.Article, err
 		publishedAt, _ := time.Parse(time.RFC3339, a.PublishedAt)
 
 		articles = append(articles, models.Article{
+			ID:          models.ArticleID(a.URL),
 			Title:       a.Title,
 			Desc:        a.Description,
+			Content:     a.Content,
 			URL:         a.URL,
 			Source:      a.Source.Name,
 			PublishedAt: publishedAt,
 			Category:    category,
+			Language:    detectLanguage(a.Title, a.Description, language),
 		})
 	}
 
@@ -125,28 +186,34 @@ func (nc *NewsCollector) fetchByCategory(category string) ([]models.Article, err
 func (nc *NewsCollector) GetMockNews() []models.Article {
 	return []models.Article{
 		{
+			ID:          models.ArticleID("https://example.com/ai-breakthrough"),
 			Title:       "AI Breakthrough: New Language Model Surpasses Human Performance",
 			Desc:        "Researchers announce a groundbreaking AI model that demonstrates superior performance across multiple benchmarks.",
 			URL:         "https://example.com/ai-breakthrough",
 			Source:      "TechCrunch",
 			PublishedAt: time.Now().AddDate(0, 0, -1),
 			Category:    "technology",
+			Language:    "en",
 		},
 		{
+			ID:          models.ArticleID("https://example.com/quantum"),
 			Title:       "Quantum Computing Reaches New Milestone",
 			Desc:        "Scientists achieve quantum supremacy with a 1000-qubit processor.",
 			URL:         "https://example.com/quantum",
 			Source:      "MIT Technology Review",
 			PublishedAt: time.Now().AddDate(0, 0, -2),
 			Category:    "science",
+			Language:    "en",
 		},
 		{
+			ID:          models.ArticleID("https://example.com/climate"),
 			Title:       "Major Tech Companies Announce Climate Initiatives",
 			Desc:        "Leading technology firms commit to carbon neutrality by 2030.",
 			URL:         "https://example.com/climate",
 			Source:      "Bloomberg",
 			PublishedAt: time.Now().AddDate(0, 0, -3),
 			Category:    "business",
+			Language:    "en",
 		},
 	}
 }
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"tech-news-agent/internal/config"
+	"tech-news-agent/internal/models"
+	"time"
+)
+
+// sendTimeout bounds a single delivery so a hanging destination cannot
+// hold up the others
+const sendTimeout = 2 * time.Minute
+
+// Notifier delivers digests to one destination, such as a Telegram chat
+type Notifier interface {
+	// Destination identifies the destination in logs and run results,
+	// e.g. "telegram:-100123"
+	Destination() string
+	// Language returns the digest language of the destination
+	Language() string
+	// MaxChars returns the length budget of a digest message (0 is unlimited)
+	MaxChars() int
+	// FormatMessage renders summary the way it is delivered, which is
+	// what the length budget applies to
+	FormatMessage(summary *models.NewsSummary) string
+	SendSummary(ctx context.Context, summary *models.NewsSummary) error
+	SendError(ctx context.Context, errMsg string) error
+	TestConnection(ctx context.Context) error
+}
+
+// webhookDestination identifies a webhook by a hash of its URL, which is a
+// secret and must not end up in logs
+func webhookDestination(kind, url string) string {
+	sum := sha1.Sum([]byte(url))
+	return kind + ":webhook-" + hex.EncodeToString(sum[:4])
+}
+
+// postJSON posts payload to a webhook URL and fails on non-2xx answers
+func postJSON(ctx context.Context, client *http.Client, url string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encoding payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+		return fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+// NewDestinationNotifier creates the notifier for a channel destination
+func NewDestinationNotifier(cfg *config.Config, dest config.Destination) (Notifier, error) {
+	switch dest.Kind {
+	case config.DestinationSlack:
+		return NewSlackNotifier(dest, cfg.SlackBotToken, cfg.SlackAPIURL), nil
+	case config.DestinationDiscord:
+		return NewDiscordNotifier(dest), nil
+	case config.DestinationEmail:
+		return NewEmailNotifier(cfg, dest), nil
+	case config.DestinationTeams:
+		return NewTeamsNotifier(dest), nil
+	case config.DestinationMattermost:
+		return NewMattermostNotifier(dest), nil
+	case config.DestinationWebhook:
+		return NewWebhookNotifier(cfg, dest)
+	case config.DestinationMatrix:
+		return NewMatrixNotifier(cfg, dest), nil
+	default:
+		return nil, fmt.Errorf("unknown destination kind %q", dest.Kind)
+	}
+}
+
+// DeliveryResult is the outcome of delivering a digest to one destination
+type DeliveryResult struct {
+	Persona     string
+	Destination string
+	Err         error
+	Duration    time.Duration
+}
+
+// RunResult reports what a run delivered. A run that reached some but not
+// all destinations succeeds, with the failures listed here.
+type RunResult struct {
+	Articles   int
+	Deliveries []DeliveryResult
+}
+
+// Delivered returns the number of successful deliveries
+func (r *RunResult) Delivered() int {
+	delivered := 0
+	for _, d := range r.Deliveries {
+		if d.Err == nil {
+			delivered++
+		}
+	}
+	return delivered
+}
+
+// Failed returns the deliveries that did not succeed
+func (r *RunResult) Failed() []DeliveryResult {
+	var failed []DeliveryResult
+	for _, d := range r.Deliveries {
+		if d.Err != nil {
+			failed = append(failed, d)
+		}
+	}
+	return failed
+}
+
+// Err joins the errors of all failed deliveries
+func (r *RunResult) Err() error {
+	var errs []error
+	for _, d := range r.Failed() {
+		errs = append(errs, fmt.Errorf("%s (%s): %w", d.Destination, d.Persona, d.Err))
+	}
+	return errors.Join(errs...)
+}
+
+// deliveryError fails a run that delivered nothing, either because every
+// delivery failed or because no destination had a digest to send
+func (r *RunResult) deliveryError() error {
+	if len(r.Deliveries) == 0 {
+		return errors.New("no digest delivered: no destination had a digest to send")
+	}
+	if r.Delivered() == 0 {
+		return fmt.Errorf("no digest delivered: %w", r.Err())
+	}
+	return nil
+}
+
+// fanOut runs send for every notifier concurrently and returns the results
+// in notifier order
+func fanOut(ctx context.Context, persona string, notifiers []Notifier, send func(context.Context, int, Notifier) error) []DeliveryResult {
+	results := make([]DeliveryResult, len(notifiers))
+
+	var wg sync.WaitGroup
+	for i, notifier := range notifiers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := send(sendCtx, i, notifier)
+			results[i] = DeliveryResult{
+				Persona:     persona,
+				Destination: notifier.Destination(),
+				Err:         err,
+				Duration:    time.Since(start),
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// failAll records err as the result for every notifier, used when there is
+// no digest to deliver
+func failAll(persona string, notifiers []Notifier, err error) []DeliveryResult {
+	results := make([]DeliveryResult, len(notifiers))
+	for i, notifier := range notifiers {
+		results[i] = DeliveryResult{Persona: persona, Destination: notifier.Destination(), Err: err}
+	}
+	return results
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"tech-news-agent/internal/models"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// goldenSummary returns a digest that fills every section, with markup
+// each channel has to strip or escape
+func goldenSummary() *models.NewsSummary {
+	return &models.NewsSummary{
+		Language:      "en",
+		PeriodStart:   time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:     time.Date(2026, 10, 11, 0, 0, 0, 0, time.UTC),
+		WeekRange:     "Oct 5 - Oct 11",
+		TotalArticles: 42,
+		Summary: "## Chips & *AI*\n\n" +
+			"Chipmakers raised **guidance** again (+12.5%) and *all* eyes are on `N2_node`.\n\n" +
+			"- Read [the Go_(lang) page](https://en.wikipedia.org/wiki/Go_(programming_language)) for <context>.",
+		KeyTopics: []models.Topic{
+			{Name: "AI agents", Size: 4, Articles: []models.ArticleBrief{{Title: "Agents [beta] ship", URL: "https://example.com/agents"}}},
+			{Name: "Chips_&_fabs"},
+		},
+		TopArticles: []models.ArticleBrief{
+			{Title: "TSMC beats estimates", URL: "https://example.com/tsmc", Source: "Reuters", Category: "business", TLDR: "Revenue up 40%."},
+			{Title: "Go 1.30 released", URL: "https://go.dev/blog/go1.30", Category: "software"},
+		},
+		Watchlist: []models.WatchlistMatch{
+			{Entity: "Nvidia", Articles: []models.ArticleBrief{{Title: "Nvidia_H300 ships", URL: "https://example.com/h300"}}},
+			{Entity: "Intel"},
+		},
+		Trends: &models.Trends{
+			PreviousPeriodEnd: time.Date(2026, 10, 4, 0, 0, 0, 0, time.UTC),
+			Changes:           []models.TopicTrend{{Topic: "ai", Current: 10, Previous: 6}},
+			Emerging:          []models.TopicTrend{{Topic: "quantum", Current: 3}},
+		},
+		MarketPulse: &models.MarketPulse{
+			Sentiment: "positive",
+			Positive:  5,
+			Neutral:   2,
+			Negative:  1,
+			Companies: []models.CompanySignal{
+				{CompanyRef: models.CompanyRef{Name: "TSMC", Ticker: "TSM"}, Mentions: 3, Sentiment: "positive"},
+			},
+		},
+		TrendingStories: []string{"**Chip** export rules tighten", "Rust 2.0 lands"},
+		FullURL:         "https://news.example.com/digests/2026-10-11-default-en.html",
+		Usage:           &models.UsageReport{PromptTokens: 12000, CompletionTokens: 800, CostUSD: 0.0123},
+		GeneratedAt:     time.Date(2026, 10, 12, 8, 0, 0, 0, time.UTC),
+	}
+}
+
+// longSummary returns a digest whose summary has the given number of
+// long paragraphs, for tests of message splitting
+func longSummary(paragraphs int) *models.NewsSummary {
+	var sb strings.Builder
+	for i := 0; i < paragraphs; i++ {
+		if i > 0 {
+			sb.WriteString("\n\n")
+		}
+		sb.WriteString(strings.TrimSpace(strings.Repeat("Chipmakers raised guidance again. ", 60)))
+	}
+	return &models.NewsSummary{
+		Language:      "en",
+		PeriodStart:   time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:     time.Date(2026, 10, 11, 0, 0, 0, 0, time.UTC),
+		WeekRange:     "Oct 5 - Oct 11",
+		TotalArticles: 42,
+		Summary:       sb.String(),
+		GeneratedAt:   time.Date(2026, 10, 12, 8, 0, 0, 0, time.UTC),
+	}
+}
+
+// recordedRequest is what a recording server received in one request
+type recordedRequest struct {
+	method string
+	path   string
+	header http.Header
+	body   []byte
+}
+
+// form decodes a form-encoded body
+func (r recordedRequest) form() url.Values {
+	values, _ := url.ParseQuery(string(r.body))
+	return values
+}
+
+// recordingServer records every request and answers it with reply, or
+// with an empty 200 when reply is nil
+func recordingServer(t *testing.T, reply func(w http.ResponseWriter, req recordedRequest)) (*httptest.Server, *[]recordedRequest) {
+	t.Helper()
+	var requests []recordedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("reading body: %v", err)
+		}
+		req := recordedRequest{method: r.Method, path: r.URL.EscapedPath(), header: r.Header.Clone(), body: body}
+		requests = append(requests, req)
+		if reply != nil {
+			reply(w, req)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+// replyStatuses answers with statuses in order and errBody, then with 200
+// and okBody
+func replyStatuses(errBody, okBody string, statuses ...int) func(http.ResponseWriter, recordedRequest) {
+	return func(w http.ResponseWriter, _ recordedRequest) {
+		if len(statuses) > 0 {
+			w.WriteHeader(statuses[0])
+			w.Write([]byte(errBody))
+			statuses = statuses[1:]
+			return
+		}
+		w.Write([]byte(okBody))
+	}
+}
+
+// checkGolden compares got with testdata/name, rewriting the file first
+// when the tests run with -update
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatalf("creating testdata: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("writing golden file: %v", err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file: %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("output differs from %s; rerun with -update if the change is intended\ngot:\n%s", path, got)
+	}
+}
+
+// fakeNotifier records deliveries and fails them with err
+type fakeNotifier struct {
+	Notifier
+	name string
+	err  error
+	sent *models.NewsSummary
+}
+
+func (n *fakeNotifier) Destination() string { return n.name }
+
+func (n *fakeNotifier) SendSummary(ctx context.Context, summary *models.NewsSummary) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.sent = summary
+	return nil
+}
+
+func TestFanOutReportsPartialFailure(t *testing.T) {
+	failing := &fakeNotifier{name: "slack:C1", err: errors.New("channel_not_found")}
+	notifiers := []*fakeNotifier{{name: "telegram:1"}, failing, {name: "discord:webhook-1"}}
+	summary := goldenSummary()
+
+	result := &RunResult{Deliveries: fanOut(context.Background(), "execs", []Notifier{notifiers[0], notifiers[1], notifiers[2]},
+		func(ctx context.Context, _ int, notifier Notifier) error {
+			return notifier.SendSummary(ctx, summary)
+		})}
+
+	for i, n := range notifiers {
+		d := result.Deliveries[i]
+		if d.Destination != n.name || d.Persona != "execs" {
+			t.Errorf("delivery %d = %s (%s), want %s (execs)", i, d.Destination, d.Persona, n.name)
+		}
+		if n != failing && n.sent != summary {
+			t.Errorf("%s did not receive the summary", n.name)
+		}
+	}
+	if got := result.Delivered(); got != 2 {
+		t.Errorf("delivered = %d, want 2", got)
+	}
+	if failed := result.Failed(); len(failed) != 1 || failed[0].Destination != "slack:C1" {
+		t.Errorf("failed = %+v, want only slack:C1", failed)
+	}
+	if err := result.Err(); err == nil || !errors.Is(err, failing.err) || !strings.Contains(err.Error(), "slack:C1 (execs)") {
+		t.Errorf("run error = %v, want the slack failure", err)
+	}
+}
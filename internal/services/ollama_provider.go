@@ -0,0 +1,137 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"tech-news-agent/internal/config"
+	"time"
+)
+
+// OllamaProvider generates text with a model served by a local Ollama instance
+type OllamaProvider struct {
+	baseURL    string
+	model      string
+	httpClient *http.Client
+}
+
+type ollamaGenerateRequest struct {
+	Model   string         `json:"model"`
+	Prompt  string         `json:"prompt"`
+	Stream  bool           `json:"stream"`
+	Options map[string]any `json:"options,omitempty"`
+}
+
+type ollamaGenerateResponse struct {
+	Response        string `json:"response"`
+	DoneReason      string `json:"done_reason"`
+	PromptEvalCount int    `json:"prompt_eval_count"`
+	EvalCount       int    `json:"eval_count"`
+}
+
+// NewOllamaProvider creates a new Ollama provider instance
+func NewOllamaProvider(baseURL, modelName string) *OllamaProvider {
+	return &OllamaProvider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		model:   modelName,
+		httpClient: &http.Client{
+			// Local models can be slow on modest hardware
+			Timeout: 5 * time.Minute,
+		},
+	}
+}
+
+// Name returns the provider name
+func (o *OllamaProvider) Name() string {
+	return "ollama"
+}
+
+// Model returns the Ollama model name
+func (o *OllamaProvider) Model() string {
+	return o.model
+}
+
+// Close is a no-op, the HTTP client holds no resources
+func (o *OllamaProvider) Close() error {
+	return nil
+}
+
+// ValidateParams checks params against the ranges Ollama accepts.
+// Safety settings are Gemini specific and ignored here.
+func (o *OllamaProvider) ValidateParams(params config.GenerationParams) error {
+	if err := checkRange("temperature", params.Temperature, 0, 2); err != nil {
+		return err
+	}
+	if err := checkRange("top_p", params.TopP, 0, 1); err != nil {
+		return err
+	}
+	if err := checkRange("top_k", params.TopK, 1, 1000); err != nil {
+		return err
+	}
+	return checkRange("max_tokens", params.MaxOutputTokens, 1, 131072)
+}
+
+// Generate sends the prompt to Ollama's /api/generate endpoint
+func (o *OllamaProvider) Generate(ctx context.Context, prompt string, params config.GenerationParams) (*LLMResult, error) {
+	options := map[string]any{
+		"temperature": params.Temperature,
+		"top_p":       params.TopP,
+		"top_k":       params.TopK,
+		"num_predict": params.MaxOutputTokens,
+	}
+	if len(params.StopSequences) > 0 {
+		options["stop"] = params.StopSequences
+	}
+
+	body, err := json.Marshal(ollamaGenerateRequest{
+		Model:   o.model,
+		Prompt:  prompt,
+		Stream:  false,
+		Options: options,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encoding request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.baseURL+"/api/generate", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, &httpStatusError{StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+
+	var genResp ollamaGenerateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&genResp); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	if genResp.DoneReason == "length" {
+		return nil, fmt.Errorf("%w (limit %d)", ErrTruncated, params.MaxOutputTokens)
+	}
+	if strings.TrimSpace(genResp.Response) == "" {
+		return nil, ErrEmptyResponse
+	}
+
+	return &LLMResult{
+		Text:             genResp.Response,
+		Provider:         o.Name(),
+		Model:            o.model,
+		FinishReason:     genResp.DoneReason,
+		PromptTokens:     genResp.PromptEvalCount,
+		CompletionTokens: genResp.EvalCount,
+	}, nil
+}
@@ -0,0 +1,338 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"tech-news-agent/internal/config"
+	"tech-news-agent/internal/i18n"
+	"tech-news-agent/internal/models"
+	"tech-news-agent/internal/renderer"
+	"time"
+	"unicode/utf8"
+)
+
+// Slack Block Kit limits
+const (
+	slackMaxBlocks      = 50
+	slackMaxSectionText = 3000
+	slackMaxHeaderText  = 150
+)
+
+// slackRenderer renders digest text as Slack mrkdwn
+var slackRenderer = renderer.New(renderer.SlackMrkdwn)
+
+// SlackNotifier posts digests to Slack as Block Kit messages, either via an
+// incoming webhook or via chat.postMessage with a bot token
+type SlackNotifier struct {
+	webhookURL string
+	channel    string
+	token      string
+	apiURL     string
+	language   string
+	maxChars   int
+	httpClient *http.Client
+}
+
+var _ Notifier = (*SlackNotifier)(nil)
+
+// NewSlackNotifier creates a new Slack notifier for dest. The API URL can
+// point at a local stand-in for testing.
+func NewSlackNotifier(dest config.Destination, token, apiURL string) *SlackNotifier {
+	n := &SlackNotifier{
+		token:      token,
+		apiURL:     strings.TrimRight(apiURL, "/"),
+		language:   i18n.Normalize(dest.Language),
+		maxChars:   dest.MaxChars,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+	if dest.IsWebhook() {
+		n.webhookURL = dest.Target
+	} else {
+		n.channel = dest.Target
+	}
+	return n
+}
+
+// Destination identifies the channel, or a hash of the webhook URL so the
+// secret does not end up in logs
+func (sn *SlackNotifier) Destination() string {
+	if sn.webhookURL != "" {
+		return webhookDestination("slack", sn.webhookURL)
+	}
+	return "slack:" + sn.channel
+}
+
+// Language returns the digest language of the channel
+func (sn *SlackNotifier) Language() string {
+	return sn.language
+}
+
+// MaxChars returns the length budget of a digest (0 is unlimited)
+func (sn *SlackNotifier) MaxChars() int {
+	return sn.maxChars
+}
+
+// slackBlock is a Block Kit layout block
+type slackBlock struct {
+	Type     string       `json:"type"`
+	Text     *slackText   `json:"text,omitempty"`
+	Elements []*slackText `json:"elements,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type slackMessage struct {
+	Channel     string       `json:"channel,omitempty"`
+	Text        string       `json:"text"`
+	Blocks      []slackBlock `json:"blocks,omitempty"`
+	UnfurlLinks bool         `json:"unfurl_links"`
+}
+
+// SendSummary posts the digest, split over several messages when it has
+// more blocks than Slack allows in one
+func (sn *SlackNotifier) SendSummary(ctx context.Context, summary *models.NewsSummary) error {
+	blocks := sn.buildBlocks(summary)
+	title := i18n.T(summary.Language, i18n.DigestTitle)
+
+	for start := 0; start < len(blocks); start += slackMaxBlocks {
+		message := slackMessage{
+			Text:   title,
+			Blocks: blocks[start:min(start+slackMaxBlocks, len(blocks))],
+		}
+		if err := sn.post(ctx, message); err != nil {
+			return fmt.Errorf("sending message: %w", err)
+		}
+	}
+	return nil
+}
+
+// SendError posts an error notification
+func (sn *SlackNotifier) SendError(ctx context.Context, errMsg string) error {
+	title := i18n.T(sn.language, i18n.ErrorTitle)
+	message := slackMessage{
+		Text: title,
+		Blocks: []slackBlock{
+			slackSection("⚠️ " + slackRenderer.Bold(title) + "\n" + slackRenderer.CodeBlock(truncateRunes(errMsg, slackMaxSectionText-len(title)-20))),
+		},
+	}
+	if err := sn.post(ctx, message); err != nil {
+		return fmt.Errorf("sending error message: %w", err)
+	}
+	return nil
+}
+
+// TestConnection posts a test message to verify the webhook or token
+func (sn *SlackNotifier) TestConnection(ctx context.Context) error {
+	if err := sn.post(ctx, slackMessage{Text: "✅ " + i18n.T(sn.language, i18n.Connected)}); err != nil {
+		return fmt.Errorf("test message failed: %w", err)
+	}
+	return nil
+}
+
+// FormatMessage renders the text of all blocks, which is what the length
+// budget applies to
+func (sn *SlackNotifier) FormatMessage(summary *models.NewsSummary) string {
+	var parts []string
+	for _, block := range sn.buildBlocks(summary) {
+		if block.Text != nil {
+			parts = append(parts, block.Text.Text)
+		}
+		for _, element := range block.Elements {
+			parts = append(parts, element.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+func (sn *SlackNotifier) buildBlocks(summary *models.NewsSummary) []slackBlock {
+	doc := renderer.Build(summary)
+
+	var meta []string
+	for _, item := range doc.Meta {
+		meta = append(meta, slackRenderer.Item(item))
+	}
+	blocks := []slackBlock{
+		{Type: "header", Text: &slackText{Type: "plain_text", Text: truncateRunes(doc.Icon+" "+doc.Title, slackMaxHeaderText)}},
+		slackContext(strings.Join(meta, " · ")),
+		{Type: "divider"},
+	}
+
+	for _, section := range doc.Sections {
+		for _, chunk := range splitText(slackRenderer.Section(section), slackMaxSectionText) {
+			blocks = append(blocks, slackSection(chunk))
+		}
+	}
+
+	// Context footer
+	var footer []string
+	for _, item := range doc.Footer {
+		footer = append(footer, slackRenderer.Item(item))
+	}
+	blocks = append(blocks, slackBlock{Type: "divider"}, slackContext(footer...))
+
+	return blocks
+}
+
+// post sends message to the webhook, or to chat.postMessage for the channel
+func (sn *SlackNotifier) post(ctx context.Context, message slackMessage) error {
+	url := sn.webhookURL
+	if url == "" {
+		url = sn.apiURL + "/chat.postMessage"
+		message.Channel = sn.channel
+	}
+
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("encoding message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if sn.webhookURL == "" {
+		req.Header.Set("Authorization", "Bearer "+sn.token)
+	}
+
+	resp, err := sn.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode != http.StatusOK {
+		if retry := resp.Header.Get("Retry-After"); retry != "" {
+			return fmt.Errorf("Slack returned status %d (retry after %ss): %s", resp.StatusCode, retry, string(respBody))
+		}
+		return fmt.Errorf("Slack returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	// Webhooks answer "ok"; the Web API answers 200 with an ok flag
+	if sn.webhookURL == "" {
+		var result struct {
+			OK    bool   `json:"ok"`
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal(respBody, &result); err != nil {
+			return fmt.Errorf("decoding response: %w", err)
+		}
+		if !result.OK {
+			return fmt.Errorf("Slack API error: %s", result.Error)
+		}
+	}
+	return nil
+}
+
+func slackSection(text string) slackBlock {
+	return slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn", Text: text}}
+}
+
+// slackContext builds a context block; Slack allows at most 10 elements
+func slackContext(texts ...string) slackBlock {
+	block := slackBlock{Type: "context"}
+	for _, text := range texts[:min(len(texts), 10)] {
+		block.Elements = append(block.Elements, &slackText{Type: "mrkdwn", Text: text})
+	}
+	return block
+}
+
+// splitText splits text into chunks of at most limit runes, preferring
+// line boundaries
+func splitText(text string, limit int) []string {
+	return splitTextBy(text, limit, func(rune) int { return 1 })
+}
+
+// splitTextBytes splits text into chunks of at most limit bytes, preferring
+// line boundaries and never cutting a character
+func splitTextBytes(text string, limit int) []string {
+	return splitTextBy(text, limit, utf8.RuneLen)
+}
+
+// splitTextBy splits text into chunks of at most limit, measured as the sum
+// of width over their runes, preferring line boundaries
+func splitTextBy(text string, limit int, width func(rune) int) []string {
+	length := func(s string) int {
+		n := 0
+		for _, r := range s {
+			n += width(r)
+		}
+		return n
+	}
+
+	var chunks []string
+	var current strings.Builder
+	currentLen := 0
+
+	flush := func() {
+		if chunk := strings.TrimSpace(current.String()); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		if currentLen > 0 {
+			current.Reset()
+			currentLen = 0
+		}
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		// Lines longer than a whole chunk are cut hard
+		for length(line) > limit {
+			flush()
+			head := truncateWidth(line, limit, width)
+			chunks = append(chunks, head)
+			line = line[len(head):]
+		}
+		if currentLen > 0 && currentLen+1+length(line) > limit {
+			flush()
+		}
+		if currentLen > 0 {
+			current.WriteString("\n")
+			currentLen++
+		}
+		current.WriteString(line)
+		currentLen += length(line)
+	}
+	flush()
+
+	return chunks
+}
+
+// truncateWidth returns the longest prefix of text whose runes add up to at
+// most n by width, keeping at least one rune so splitting makes progress
+func truncateWidth(text string, n int, width func(rune) int) string {
+	total := 0
+	for i, r := range text {
+		total += width(r)
+		if total > n {
+			if i == 0 {
+				return text[:utf8.RuneLen(r)]
+			}
+			return text[:i]
+		}
+	}
+	return text
+}
+
+// truncateRunes returns at most n runes of text
+func truncateRunes(text string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	count := 0
+	for i := range text {
+		if count == n {
+			return text[:i]
+		}
+		count++
+	}
+	return text
+}
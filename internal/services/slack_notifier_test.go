@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"tech-news-agent/internal/config"
+	"testing"
+)
+
+func TestSlackWebhookSplitsAtBlockLimit(t *testing.T) {
+	var messages []slackMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("webhook request sent Authorization %q", auth)
+		}
+		var message slackMessage
+		if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+			t.Errorf("decoding message: %v", err)
+		}
+		messages = append(messages, message)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	notifier := NewSlackNotifier(config.Destination{Kind: config.DestinationSlack, Target: server.URL, Language: "en"}, "", "")
+	summary := longSummary(80)
+	blocks := len(notifier.buildBlocks(summary))
+	if blocks <= slackMaxBlocks {
+		t.Fatalf("fixture renders %d blocks, want more than %d", blocks, slackMaxBlocks)
+	}
+
+	if err := notifier.SendSummary(context.Background(), summary); err != nil {
+		t.Fatalf("SendSummary: %v", err)
+	}
+
+	if want := (blocks + slackMaxBlocks - 1) / slackMaxBlocks; len(messages) != want {
+		t.Fatalf("sent %d messages, want %d", len(messages), want)
+	}
+	sent := 0
+	for i, message := range messages {
+		if len(message.Blocks) > slackMaxBlocks {
+			t.Errorf("message %d has %d blocks, limit is %d", i, len(message.Blocks), slackMaxBlocks)
+		}
+		if message.Text == "" {
+			t.Errorf("message %d has no fallback text", i)
+		}
+		if message.Channel != "" {
+			t.Errorf("message %d names channel %q on a webhook", i, message.Channel)
+		}
+		sent += len(message.Blocks)
+	}
+	if sent != blocks {
+		t.Errorf("sent %d blocks, want %d", sent, blocks)
+	}
+}
+
+func TestSlackPostMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		header  string
+		body    string
+		wantErr string
+	}{
+		{name: "ok", status: http.StatusOK, body: `{"ok":true}`},
+		{name: "api error", status: http.StatusOK, body: `{"ok":false,"error":"channel_not_found"}`, wantErr: "channel_not_found"},
+		{name: "rate limited", status: http.StatusTooManyRequests, header: "30", body: `{"ok":false,"error":"ratelimited"}`, wantErr: "retry after 30s"},
+		{name: "invalid response", status: http.StatusOK, body: `ok`, wantErr: "decoding response"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/chat.postMessage" {
+					t.Errorf("request to %s, want /chat.postMessage", r.URL.Path)
+				}
+				if auth := r.Header.Get("Authorization"); auth != "Bearer xoxb-test" {
+					t.Errorf("Authorization is %q", auth)
+				}
+				var message slackMessage
+				if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+					t.Errorf("decoding message: %v", err)
+				}
+				if message.Channel != "C0123" {
+					t.Errorf("channel is %q, want C0123", message.Channel)
+				}
+				if tt.header != "" {
+					w.Header().Set("Retry-After", tt.header)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			notifier := NewSlackNotifier(config.Destination{Kind: config.DestinationSlack, Target: "C0123", Language: "en"}, "xoxb-test", server.URL+"/")
+			err := notifier.SendSummary(context.Background(), longSummary(1))
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("SendSummary: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("SendSummary error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
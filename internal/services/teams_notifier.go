@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"tech-news-agent/internal/config"
+	"tech-news-agent/internal/i18n"
+	"tech-news-agent/internal/models"
+	"tech-news-agent/internal/renderer"
+	"time"
+)
+
+// teamsMaxPayload keeps each card below the ~28 KB Teams accepts per
+// webhook message
+const teamsMaxPayload = 24000
+
+// teamsRenderer renders the CommonMark subset Adaptive Card TextBlocks show
+var teamsRenderer = renderer.New(renderer.CommonMark)
+
+// TeamsNotifier posts digests to a Microsoft Teams incoming webhook as
+// Adaptive Cards
+type TeamsNotifier struct {
+	webhookURL string
+	language   string
+	maxChars   int
+	httpClient *http.Client
+}
+
+var _ Notifier = (*TeamsNotifier)(nil)
+
+// NewTeamsNotifier creates a new Teams notifier for dest
+func NewTeamsNotifier(dest config.Destination) *TeamsNotifier {
+	return &TeamsNotifier{
+		webhookURL: dest.Target,
+		language:   i18n.Normalize(dest.Language),
+		maxChars:   dest.MaxChars,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Destination identifies the webhook
+func (tn *TeamsNotifier) Destination() string {
+	return webhookDestination("teams", tn.webhookURL)
+}
+
+// Language returns the digest language of the channel
+func (tn *TeamsNotifier) Language() string {
+	return tn.language
+}
+
+// MaxChars returns the length budget of a digest (0 is unlimited)
+func (tn *TeamsNotifier) MaxChars() int {
+	return tn.maxChars
+}
+
+// teamsElement is an Adaptive Card TextBlock
+type teamsElement struct {
+	Type      string `json:"type"`
+	Text      string `json:"text"`
+	Wrap      bool   `json:"wrap"`
+	Size      string `json:"size,omitempty"`
+	Weight    string `json:"weight,omitempty"`
+	IsSubtle  bool   `json:"isSubtle,omitempty"`
+	Spacing   string `json:"spacing,omitempty"`
+	Separator bool   `json:"separator,omitempty"`
+}
+
+type teamsAction struct {
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+type teamsCard struct {
+	Schema  string         `json:"$schema"`
+	Type    string         `json:"type"`
+	Version string         `json:"version"`
+	Body    []teamsElement `json:"body"`
+	Actions []teamsAction  `json:"actions,omitempty"`
+	MSTeams struct {
+		Width string `json:"width"`
+	} `json:"msteams"`
+}
+
+type teamsAttachment struct {
+	ContentType string    `json:"contentType"`
+	Content     teamsCard `json:"content"`
+}
+
+type teamsMessage struct {
+	Type        string            `json:"type"`
+	Attachments []teamsAttachment `json:"attachments"`
+}
+
+// SendSummary posts the digest as one or more cards
+func (tn *TeamsNotifier) SendSummary(ctx context.Context, summary *models.NewsSummary) error {
+	for _, card := range tn.buildCards(summary) {
+		if err := postJSON(ctx, tn.httpClient, tn.webhookURL, teamsPayload(card)); err != nil {
+			return fmt.Errorf("sending message: %w", err)
+		}
+	}
+	return nil
+}
+
+// SendError posts an error notification
+func (tn *TeamsNotifier) SendError(ctx context.Context, errMsg string) error {
+	card := newTeamsCard([]teamsElement{
+		{Type: "TextBlock", Text: "⚠️ " + i18n.T(tn.language, i18n.ErrorTitle), Weight: "Bolder", Size: "Medium", Wrap: true},
+		{Type: "TextBlock", Text: truncateRunes(errMsg, teamsMaxPayload/2), Wrap: true},
+	})
+	if err := postJSON(ctx, tn.httpClient, tn.webhookURL, teamsPayload(card)); err != nil {
+		return fmt.Errorf("sending error message: %w", err)
+	}
+	return nil
+}
+
+// TestConnection posts a test card to verify the webhook
+func (tn *TeamsNotifier) TestConnection(ctx context.Context) error {
+	card := newTeamsCard([]teamsElement{{Type: "TextBlock", Text: "✅ " + i18n.T(tn.language, i18n.Connected), Wrap: true}})
+	if err := postJSON(ctx, tn.httpClient, tn.webhookURL, teamsPayload(card)); err != nil {
+		return fmt.Errorf("test message failed: %w", err)
+	}
+	return nil
+}
+
+// FormatMessage renders the text of all card elements
+func (tn *TeamsNotifier) FormatMessage(summary *models.NewsSummary) string {
+	var parts []string
+	for _, card := range tn.buildCards(summary) {
+		for _, element := range card.Body {
+			parts = append(parts, element.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+func (tn *TeamsNotifier) buildCards(summary *models.NewsSummary) []teamsCard {
+	doc := renderer.Build(summary)
+
+	var meta []string
+	for _, item := range doc.Meta {
+		meta = append(meta, teamsRenderer.Item(item))
+	}
+	elements := []teamsElement{
+		{Type: "TextBlock", Text: doc.Icon + " " + doc.Title, Size: "Large", Weight: "Bolder", Wrap: true},
+		{Type: "TextBlock", Text: strings.Join(meta, " · "), IsSubtle: true, Spacing: "None", Wrap: true},
+	}
+
+	for _, section := range doc.Sections {
+		if len(section.Body) > 0 {
+			elements = append(elements, teamsMarkdown(teamsRenderer.Content(section))...)
+			continue
+		}
+		elements = append(elements, teamsElement{
+			Type: "TextBlock", Text: section.Heading(),
+			Size: "Medium", Weight: "Bolder", Separator: true, Spacing: "Medium", Wrap: true,
+		})
+		for _, line := range strings.Split(teamsRenderer.Content(section), "\n") {
+			element := teamsElement{Type: "TextBlock", Text: strings.TrimSpace(line), Spacing: "None", Wrap: true}
+			// Indented lines are TL;DRs and articles under a list item
+			element.IsSubtle = strings.HasPrefix(line, " ") && !strings.HasPrefix(strings.TrimSpace(line), "- ")
+			elements = append(elements, element)
+		}
+	}
+
+	// Links move into card actions; the rest is a subtle footer
+	var actions []teamsAction
+	separator := true
+	for _, item := range doc.Footer {
+		if item.URL != "" {
+			actions = append(actions, teamsAction{Type: "Action.OpenUrl", Title: item.Text, URL: item.URL})
+			continue
+		}
+		elements = append(elements, teamsElement{Type: "TextBlock", Text: teamsRenderer.Item(item), Size: "Small", IsSubtle: true, Separator: separator, Spacing: "None", Wrap: true})
+		separator = false
+	}
+
+	// Split into several cards when the payload would be too large
+	var cards []teamsCard
+	current := newTeamsCard(nil)
+	size := 0
+	for _, element := range elements {
+		encoded, _ := json.Marshal(element)
+		if len(current.Body) > 0 && size+len(encoded) > teamsMaxPayload {
+			cards = append(cards, current)
+			current, size = newTeamsCard(nil), 0
+		}
+		current.Body = append(current.Body, element)
+		size += len(encoded)
+	}
+	current.Actions = actions
+	return append(cards, current)
+}
+
+func newTeamsCard(body []teamsElement) teamsCard {
+	card := teamsCard{
+		Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+		Type:    "AdaptiveCard",
+		Version: "1.4",
+		Body:    body,
+	}
+	card.MSTeams.Width = "Full"
+	return card
+}
+
+func teamsPayload(card teamsCard) teamsMessage {
+	return teamsMessage{
+		Type: "message",
+		Attachments: []teamsAttachment{{
+			ContentType: "application/vnd.microsoft.card.adaptive",
+			Content:     card,
+		}},
+	}
+}
+
+var teamsHeadingPattern = regexp.MustCompile(`^#{1,6}\s+(.+)$`)
+
+// teamsMarkdown splits the model's markdown into TextBlocks, one per line,
+// since TextBlocks support neither headings nor reliable line breaks
+func teamsMarkdown(markdown string) []teamsElement {
+	var elements []teamsElement
+	spacing := "Default"
+	for _, line := range strings.Split(markdown, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			spacing = "Default"
+			continue
+		}
+		element := teamsElement{Type: "TextBlock", Text: line, Spacing: spacing, Wrap: true}
+		if m := teamsHeadingPattern.FindStringSubmatch(line); m != nil {
+			element.Text = m[1]
+			element.Weight = "Bolder"
+			element.Spacing = "Medium"
+		}
+		elements = append(elements, element)
+		spacing = "None"
+	}
+	return elements
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"encoding/json"
+	"strings"
+	"tech-news-agent/internal/config"
+	"testing"
+)
+
+func TestTeamsCardGolden(t *testing.T) {
+	notifier := NewTeamsNotifier(config.Destination{Kind: config.DestinationTeams, Target: "https://teams.invalid/webhook", Language: "en"})
+	cards := notifier.buildCards(goldenSummary())
+	if len(cards) != 1 {
+		t.Fatalf("got %d cards, want 1", len(cards))
+	}
+
+	data, err := json.MarshalIndent(teamsPayload(cards[0]), "", "  ")
+	if err != nil {
+		t.Fatalf("encoding card: %v", err)
+	}
+	checkGolden(t, "teams.json.golden", string(data)+"\n")
+
+	// The full digest link becomes a card action
+	actions := cards[0].Actions
+	if len(actions) != 1 || actions[0].URL != goldenSummary().FullURL {
+		t.Errorf("actions = %+v, want the full digest link", actions)
+	}
+	if text := notifier.FormatMessage(goldenSummary()); strings.Contains(text, "## ") {
+		t.Errorf("markdown headings are not turned into bold TextBlocks:\n%s", text)
+	}
+}
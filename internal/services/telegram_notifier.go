@@ -1,138 +1,142 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log"
 	"strings"
+	"tech-news-agent/internal/config"
+	"tech-news-agent/internal/i18n"
 	"tech-news-agent/internal/models"
+	"tech-news-agent/internal/renderer"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 // TelegramNotifier handles sending notifications via Telegram
 type TelegramNotifier struct {
-	bot    *tgbotapi.BotAPI
-	chatID int64
+	bot       *tgbotapi.BotAPI
+	chatID    int64
+	language  string
+	maxChars  int
+	format    renderer.Format
+	parseMode string
+	renderer  *renderer.Renderer
+	logger    *log.Logger
 }
 
-// NewTelegramNotifier creates a new Telegram notifier instance
-func NewTelegramNotifier(token string, chatID int64) (*TelegramNotifier, error) {
-	bot, err := tgbotapi.NewBotAPI(token)
-	if err != nil {
-		return nil, fmt.Errorf("creating Telegram bot: %w", err)
+var _ Notifier = (*TelegramNotifier)(nil)
+
+// NewTelegramNotifier creates a new Telegram notifier for chat on a bot
+// shared by all chats, rendering messages in the configured parse mode
+func NewTelegramNotifier(cfg *config.Config, bot *tgbotapi.BotAPI, chat config.TelegramChat, logger *log.Logger) *TelegramNotifier {
+	format, parseMode := renderer.TelegramMarkdownV2, tgbotapi.ModeMarkdownV2
+	if cfg.TelegramParseMode == "html" {
+		format, parseMode = renderer.TelegramHTML, tgbotapi.ModeHTML
 	}
 
 	return &TelegramNotifier{
-		bot:    bot,
-		chatID: chatID,
-	}, nil
+		bot:       bot,
+		chatID:    chat.ID,
+		language:  i18n.Normalize(chat.Language),
+		maxChars:  chat.MaxChars,
+		format:    format,
+		parseMode: parseMode,
+		renderer:  renderer.New(format),
+		logger:    logger,
+	}
 }
 
-// SendSummary sends the news summary to the configured chat
-func (tn *TelegramNotifier) SendSummary(summary *models.NewsSummary) error {
-	message := tn.formatMessage(summary)
-
-	// Split message if it's too long (Telegram limit is 4096 characters)
-	messages := tn.splitMessage(message, 4000)
+// ChatID returns the chat the notifier delivers to
+func (tn *TelegramNotifier) ChatID() int64 {
+	return tn.chatID
+}
 
-	for _, msg := range messages {
-		telegramMsg := tgbotapi.NewMessage(tn.chatID, msg)
-		telegramMsg.ParseMode = "Markdown"
-		telegramMsg.DisableWebPagePreview = true
+// Destination identifies the chat in logs and run results
+func (tn *TelegramNotifier) Destination() string {
+	return fmt.Sprintf("telegram:%d", tn.chatID)
+}
 
-		if _, err := tn.bot.Send(telegramMsg); err != nil {
-			return fmt.Errorf("sending message: %w", err)
-		}
-	}
+// Language returns the digest language of the chat
+func (tn *TelegramNotifier) Language() string {
+	return tn.language
+}
 
-	return nil
+// MaxChars returns the length budget of a digest message (0 is unlimited)
+func (tn *TelegramNotifier) MaxChars() int {
+	return tn.maxChars
 }
 
-// SendError sends an error notification
-func (tn *TelegramNotifier) SendError(errMsg string) error {
-	message := fmt.Sprintf("⚠️ *Tech News Agent Error*\n\n```\n%s\n```", errMsg)
-	msg := tgbotapi.NewMessage(tn.chatID, message)
-	msg.ParseMode = "Markdown"
+// SendSummary sends the news summary to the configured chat
+func (tn *TelegramNotifier) SendSummary(ctx context.Context, summary *models.NewsSummary) error {
+	message := tn.FormatMessage(summary)
 
-	if _, err := tn.bot.Send(msg); err != nil {
-		return fmt.Errorf("sending error message: %w", err)
+	for _, msg := range renderer.SplitTelegram(tn.format, message, renderer.TelegramMaxMessage) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := tn.send(msg); err != nil {
+			return fmt.Errorf("sending message: %w", err)
+		}
 	}
 
 	return nil
 }
 
-func (tn *TelegramNotifier) formatMessage(summary *models.NewsSummary) string {
-	var sb strings.Builder
-
-	// Header
-	sb.WriteString("📰 *Weekly Tech News Summary*\n")
-	sb.WriteString(fmt.Sprintf("📅 *%s*\n", summary.WeekRange))
-	sb.WriteString(fmt.Sprintf("📊 Articles analyzed: %d\n", summary.TotalArticles))
-	sb.WriteString("\n━━━━━━━━━━━━━━━━━\n\n")
-
-	// Main Summary
-	sb.WriteString(summary.Summary)
-	sb.WriteString("\n\n")
-
-	// Key Topics
-	if len(summary.KeyTopics) > 0 {
-		sb.WriteString("━━━━━━━━━━━━━━━━━\n")
-		sb.WriteString("🔑 *Key Topics*\n\n")
-		for _, topic := range summary.KeyTopics {
-			sb.WriteString(fmt.Sprintf("• %s\n", topic))
+// SendError sends an error notification
+func (tn *TelegramNotifier) SendError(ctx context.Context, errMsg string) error {
+	message := "⚠️ " + tn.renderer.Bold(i18n.T(tn.language, i18n.ErrorTitle)) + "\n\n" + tn.renderer.CodeBlock(errMsg)
+	for _, msg := range renderer.SplitTelegram(tn.format, message, renderer.TelegramMaxMessage) {
+		if err := ctx.Err(); err != nil {
+			return err
 		}
-		sb.WriteString("\n")
-	}
 
-	// Trending Stories
-	if len(summary.TrendingStories) > 0 {
-		sb.WriteString("━━━━━━━━━━━━━━━━━\n")
-		sb.WriteString("🔥 *Trending Stories*\n\n")
-		for i, story := range summary.TrendingStories {
-			sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, story))
+		if err := tn.send(msg); err != nil {
+			return fmt.Errorf("sending error message: %w", err)
 		}
-		sb.WriteString("\n")
 	}
 
-	// Footer
-	sb.WriteString("━━━━━━━━━━━━━━━━━\n")
-	sb.WriteString(fmt.Sprintf("🤖 Generated on %s\n", summary.GeneratedAt.Format("Jan 02, 2006 15:04 MST")))
-	sb.WriteString("_Powered by Gemini AI & Go_")
-
-	return sb.String()
+	return nil
 }
 
-func (tn *TelegramNotifier) splitMessage(message string, maxLength int) []string {
-	if len(message) <= maxLength {
-		return []string{message}
-	}
-
-	var messages []string
-	lines := strings.Split(message, "\n")
-	var currentMsg strings.Builder
+// FormatMessage renders summary in the parse mode of the notifier
+func (tn *TelegramNotifier) FormatMessage(summary *models.NewsSummary) string {
+	return tn.renderer.Document(renderer.Build(summary))
+}
 
-	for _, line := range lines {
-		// If adding this line would exceed limit, start new message
-		if currentMsg.Len()+len(line)+1 > maxLength {
-			messages = append(messages, currentMsg.String())
-			currentMsg.Reset()
-		}
+// send posts a formatted message. When Telegram cannot parse its entities
+// the message is resent as plain text rather than failing the delivery.
+func (tn *TelegramNotifier) send(text string) error {
+	msg := tgbotapi.NewMessage(tn.chatID, text)
+	msg.ParseMode = tn.parseMode
+	msg.DisableWebPagePreview = true
 
-		if currentMsg.Len() > 0 {
-			currentMsg.WriteString("\n")
-		}
-		currentMsg.WriteString(line)
+	_, err := tn.bot.Send(msg)
+	if !isTelegramParseError(err) {
+		return err
 	}
 
-	if currentMsg.Len() > 0 {
-		messages = append(messages, currentMsg.String())
+	tn.logger.Printf("⚠️ Telegram rejected the %s formatting for chat %d, resending as plain text: %v", tn.parseMode, tn.chatID, err)
+	msg.Text = renderer.TelegramPlain(tn.format, text)
+	msg.ParseMode = ""
+	if _, err := tn.bot.Send(msg); err != nil {
+		return fmt.Errorf("resending as plain text: %w", err)
 	}
+	return nil
+}
 
-	return messages
+// isTelegramParseError reports whether Telegram refused a message because
+// of malformed entities
+func isTelegramParseError(err error) bool {
+	var apiErr *tgbotapi.Error
+	return errors.As(err, &apiErr) && apiErr.Code == 400 && strings.Contains(apiErr.Message, "can't parse entities")
 }
 
 // TestConnection sends a test message to verify the bot is working
-func (tn *TelegramNotifier) TestConnection() error {
-	msg := tgbotapi.NewMessage(tn.chatID, "✅ Tech News Agent is connected and ready!")
+func (tn *TelegramNotifier) TestConnection(ctx context.Context) error {
+	msg := tgbotapi.NewMessage(tn.chatID, "✅ "+i18n.T(tn.language, i18n.Connected))
 	if _, err := tn.bot.Send(msg); err != nil {
 		return fmt.Errorf("test message failed: %w", err)
 	}
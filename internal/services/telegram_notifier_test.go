@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"tech-news-agent/internal/config"
+	"tech-news-agent/internal/renderer"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// telegramSend is a sendMessage call received by the fake Bot API
+type telegramSend struct {
+	text      string
+	parseMode string
+}
+
+// telegramServer fakes the Bot API. sendMessage calls with a parse mode
+// fail with failure, unless it is empty.
+func telegramServer(t *testing.T, failure string) (*tgbotapi.BotAPI, *[]recordedRequest) {
+	t.Helper()
+	server, requests := recordingServer(t, func(w http.ResponseWriter, req recordedRequest) {
+		switch req.path {
+		case "/bottoken/getMe":
+			fmt.Fprint(w, `{"ok": true, "result": {"id": 1, "is_bot": true, "username": "news_bot"}}`)
+		case "/bottoken/sendMessage":
+			if req.form().Get("parse_mode") != "" && failure != "" {
+				fmt.Fprint(w, failure)
+				return
+			}
+			fmt.Fprint(w, `{"ok": true, "result": {"message_id": 1, "date": 0, "chat": {"id": 42, "type": "group"}}}`)
+		default:
+			t.Errorf("unexpected request %s", req.path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	bot, err := tgbotapi.NewBotAPIWithAPIEndpoint("token", server.URL+"/bot%s/%s")
+	if err != nil {
+		t.Fatalf("creating bot: %v", err)
+	}
+	return bot, requests
+}
+
+// telegramSends returns the sendMessage calls among requests
+func telegramSends(requests []recordedRequest) []telegramSend {
+	var sends []telegramSend
+	for _, req := range requests {
+		if req.path == "/bottoken/sendMessage" {
+			form := req.form()
+			sends = append(sends, telegramSend{text: form.Get("text"), parseMode: form.Get("parse_mode")})
+		}
+	}
+	return sends
+}
+
+func TestTelegramFallsBackToPlainText(t *testing.T) {
+	const parseError = `{"ok": false, "error_code": 400, "description": "Bad Request: can't parse entities: Can't find end of the entity starting at byte offset 12"}`
+	tests := []struct {
+		name      string
+		parseMode string
+		failure   string
+		wantSends int
+		wantErr   bool
+	}{
+		{"markdown accepted", "markdownv2", "", 1, false},
+		{"markdown rejected", "markdownv2", parseError, 2, false},
+		{"html rejected", "html", parseError, 2, false},
+		{"other errors are not resent", "markdownv2", `{"ok": false, "error_code": 403, "description": "Forbidden: bot was kicked"}`, 1, true},
+		{"other bad requests are not resent", "html", `{"ok": false, "error_code": 400, "description": "Bad Request: chat not found"}`, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot, requests := telegramServer(t, tt.failure)
+			notifier := NewTelegramNotifier(&config.Config{TelegramParseMode: tt.parseMode}, bot,
+				config.TelegramChat{ID: 42, Language: "en"}, log.New(io.Discard, "", 0))
+
+			summary := goldenSummary()
+			summary.FullURL = ""
+			summary.Summary = "Chipmakers raised **guidance** (+12.5%) in [Taiwan](https://example.com/tw_(island))."
+			err := notifier.SendSummary(context.Background(), summary)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SendSummary err = %v, want error %v", err, tt.wantErr)
+			}
+			sends := telegramSends(*requests)
+			if len(sends) != tt.wantSends {
+				t.Fatalf("got %d sendMessage calls, want %d", len(sends), tt.wantSends)
+			}
+
+			first := sends[0]
+			if first.parseMode != notifier.parseMode || first.text != notifier.FormatMessage(summary) {
+				t.Errorf("first send is %q in %q, want the formatted digest in %q", first.text, first.parseMode, notifier.parseMode)
+			}
+			if tt.wantSends == 2 {
+				resend := sends[1]
+				if resend.parseMode != "" {
+					t.Errorf("resend parse mode = %q, want none", resend.parseMode)
+				}
+				if want := renderer.TelegramPlain(notifier.format, first.text); resend.text != want {
+					t.Errorf("resend text = %q, want %q", resend.text, want)
+				}
+				for _, want := range []string{"raised guidance (+12.5%) in Taiwan <https://example.com/tw_(island)>.", "Chip export rules tighten"} {
+					if !strings.Contains(resend.text, want) {
+						t.Errorf("resend text lacks %q:\n%s", want, resend.text)
+					}
+				}
+				if strings.ContainsAny(resend.text, `\*`) {
+					t.Errorf("resend text keeps markup:\n%s", resend.text)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,343 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"tech-news-agent/internal/config"
+	"tech-news-agent/internal/models"
+	"time"
+	"unicode"
+)
+
+// kmeansSeed keeps clustering deterministic between runs on the same input
+const kmeansSeed = 42
+
+// kmeansMaxIterations bounds the k-means refinement loop
+const kmeansMaxIterations = 50
+
+// TopicClusterer detects the week's themes by clustering article embeddings
+// and asking the LLM to name each cluster
+type TopicClusterer struct {
+	embedder EmbeddingProvider
+	analyzer *AIAnalyzer
+	params   config.GenerationParams
+	k        int
+	logger   *log.Logger
+}
+
+// NewTopicClusterer creates a new topic clusterer. A k of 0 picks the
+// number of clusters from the number of articles.
+func NewTopicClusterer(embedder EmbeddingProvider, analyzer *AIAnalyzer, params config.GenerationParams, k int, logger *log.Logger) *TopicClusterer {
+	return &TopicClusterer{
+		embedder: embedder,
+		analyzer: analyzer,
+		params:   params,
+		k:        k,
+		logger:   logger,
+	}
+}
+
+// Cluster groups articles into labelled topics, largest first
+func (tc *TopicClusterer) Cluster(ctx context.Context, articles []models.Article) ([]models.Topic, error) {
+	if len(articles) < 2 {
+		return nil, fmt.Errorf("need at least 2 articles to cluster, got %d", len(articles))
+	}
+
+	texts := make([]string, len(articles))
+	for i, article := range articles {
+		texts[i] = embeddingText(article)
+	}
+
+	vectors, err := tc.embedder.Embed(ctx, texts, TaskClustering)
+	if err != nil {
+		return nil, fmt.Errorf("embedding articles with %s: %w", tc.embedder.Name(), err)
+	}
+
+	k := tc.k
+	if k == 0 {
+		k = autoClusterCount(len(articles))
+	}
+	k = min(k, len(articles))
+
+	assignments := kmeans(vectors, k)
+
+	groups := make([][]models.Article, k)
+	for i, cluster := range assignments {
+		groups[cluster] = append(groups[cluster], articles[i])
+	}
+
+	var clusters [][]models.Article
+	for _, group := range groups {
+		if len(group) > 0 {
+			clusters = append(clusters, RankArticles(group))
+		}
+	}
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return len(clusters[i]) > len(clusters[j])
+	})
+
+	labels := tc.label(ctx, clusters)
+
+	topics := make([]models.Topic, len(clusters))
+	for i, cluster := range clusters {
+		topic := models.Topic{Name: labels[i], Size: len(cluster)}
+		for _, article := range cluster {
+			topic.Articles = append(topic.Articles, article.Brief())
+		}
+		topics[i] = topic
+	}
+
+	return topics, nil
+}
+
+// filterTopics restricts topics to the given articles, dropping topics
+// that lose all their members
+func filterTopics(topics []models.Topic, articles []models.Article) []models.Topic {
+	urls := make(map[string]bool, len(articles))
+	for _, article := range articles {
+		urls[article.URL] = true
+	}
+
+	var filtered []models.Topic
+	for _, topic := range topics {
+		if topic.Size == 0 {
+			filtered = append(filtered, topic)
+			continue
+		}
+		var members []models.ArticleBrief
+		for _, brief := range topic.Articles {
+			if urls[brief.URL] {
+				members = append(members, brief)
+			}
+		}
+		if len(members) == 0 {
+			continue
+		}
+		topic.Articles = members
+		topic.Size = len(members)
+		filtered = append(filtered, topic)
+	}
+	return filtered
+}
+
+// label names every cluster with a single LLM call, falling back to the
+// most frequent title keywords when the call fails
+func (tc *TopicClusterer) label(ctx context.Context, clusters [][]models.Article) []string {
+	labels := make([]string, len(clusters))
+	for i, cluster := range clusters {
+		labels[i] = keywordLabel(cluster)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("The following groups of tech news headlines were clustered by topic.\n")
+	sb.WriteString("Give each group a short, specific topic name of 2-5 words in English (e.g. \"EU AI Act enforcement\", not \"Technology\").\n")
+	sb.WriteString("Reply with a JSON object only: {\"labels\": [one name per group, in order]}\n\n")
+	for i, cluster := range clusters {
+		sb.WriteString(fmt.Sprintf("Group %d:\n", i+1))
+		for j, article := range cluster {
+			if j == 10 {
+				break
+			}
+			sb.WriteString(fmt.Sprintf("- %s\n", article.Title))
+		}
+		sb.WriteString("\n")
+	}
+
+	labelCtx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	result, err := tc.analyzer.generate(labelCtx, sb.String(), tc.params)
+	if err != nil {
+		tc.logger.Printf("⚠️ Topic labelling failed, using keywords: %v", err)
+		return labels
+	}
+
+	var response struct {
+		Labels []string `json:"labels"`
+	}
+	if err := parseJSONResponse(result.Text, &response); err != nil {
+		tc.logger.Printf("⚠️ Topic labelling returned invalid JSON, using keywords: %v", err)
+		return labels
+	}
+
+	for i, label := range response.Labels {
+		if i < len(labels) && strings.TrimSpace(label) != "" {
+			labels[i] = strings.TrimSpace(label)
+		}
+	}
+	return labels
+}
+
+// embeddingText is the text embedded for an article
+func embeddingText(article models.Article) string {
+	text := article.Title
+	if article.Desc != "" {
+		text += "\n" + article.Desc
+	}
+	if e := article.Enrichment; e != nil && e.TLDR != "" {
+		text += "\n" + e.TLDR
+	}
+	return text
+}
+
+// autoClusterCount uses the sqrt(n/2) rule of thumb, clamped so a digest
+// shows a readable number of topics
+func autoClusterCount(n int) int {
+	k := int(math.Round(math.Sqrt(float64(n) / 2)))
+	return max(2, min(k, 8))
+}
+
+// kmeans clusters vectors by cosine similarity and returns the cluster
+// index of every vector. Centroids are seeded with k-means++.
+func kmeans(vectors [][]float32, k int) []int {
+	points := make([][]float32, len(vectors))
+	for i, v := range vectors {
+		points[i] = append([]float32(nil), v...)
+		normalize(points[i])
+	}
+
+	rng := rand.New(rand.NewPCG(kmeansSeed, 0))
+	centroids := initCentroids(points, k, rng)
+
+	assignments := make([]int, len(points))
+	for i := range assignments {
+		assignments[i] = -1
+	}
+
+	for iter := 0; iter < kmeansMaxIterations; iter++ {
+		changed := false
+		for i, p := range points {
+			best := nearestCentroid(p, centroids)
+			if best != assignments[i] {
+				assignments[i] = best
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+
+		dims := len(points[0])
+		for c := range centroids {
+			sum := make([]float32, dims)
+			var count int
+			for i, p := range points {
+				if assignments[i] != c {
+					continue
+				}
+				count++
+				for d := range sum {
+					sum[d] += p[d]
+				}
+			}
+			// Keep the old centroid for an empty cluster
+			if count == 0 {
+				continue
+			}
+			normalize(sum)
+			centroids[c] = sum
+		}
+	}
+
+	return assignments
+}
+
+// initCentroids picks k starting centroids with k-means++, preferring
+// points far from the centroids chosen so far
+func initCentroids(points [][]float32, k int, rng *rand.Rand) [][]float32 {
+	centroids := [][]float32{points[rng.IntN(len(points))]}
+	distances := make([]float64, len(points))
+
+	for len(centroids) < k {
+		var total float64
+		for i, p := range points {
+			d := 1 - cosine(p, centroids[nearestCentroid(p, centroids)])
+			distances[i] = d * d
+			total += distances[i]
+		}
+
+		// All remaining points coincide with a centroid
+		if total == 0 {
+			centroids = append(centroids, points[rng.IntN(len(points))])
+			continue
+		}
+
+		target := rng.Float64() * total
+		next := len(points) - 1
+		for i, d := range distances {
+			target -= d
+			if target <= 0 {
+				next = i
+				break
+			}
+		}
+		centroids = append(centroids, points[next])
+	}
+
+	return centroids
+}
+
+func nearestCentroid(p []float32, centroids [][]float32) int {
+	best, bestSim := 0, math.Inf(-1)
+	for c, centroid := range centroids {
+		if sim := cosine(p, centroid); sim > bestSim {
+			best, bestSim = c, sim
+		}
+	}
+	return best
+}
+
+// labelStopwords are skipped when naming a cluster from its titles
+var labelStopwords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "from": true, "that": true, "this": true,
+	"are": true, "has": true, "have": true, "will": true, "its": true, "new": true, "how": true,
+	"what": true, "why": true, "into": true, "over": true, "after": true, "about": true, "says": true,
+	"more": true, "than": true, "you": true, "your": true, "can": true, "now": true, "just": true,
+}
+
+// keywordLabel names a cluster after its most frequent title words
+func keywordLabel(cluster []models.Article) string {
+	counts := make(map[string]int)
+	display := make(map[string]string)
+	for _, article := range cluster {
+		seen := make(map[string]bool)
+		for _, word := range strings.FieldsFunc(article.Title, isTokenSeparator) {
+			key := strings.ToLower(word)
+			if len([]rune(key)) < 3 || labelStopwords[key] || seen[key] {
+				continue
+			}
+			seen[key] = true
+			counts[key]++
+			if _, ok := display[key]; !ok || unicode.IsUpper([]rune(word)[0]) {
+				display[key] = word
+			}
+		}
+	}
+
+	words := make([]string, 0, len(counts))
+	for word := range counts {
+		words = append(words, word)
+	}
+	sort.Slice(words, func(i, j int) bool {
+		if counts[words[i]] != counts[words[j]] {
+			return counts[words[i]] > counts[words[j]]
+		}
+		return words[i] < words[j]
+	})
+
+	if len(words) > 3 {
+		words = words[:3]
+	}
+	for i, word := range words {
+		words[i] = display[word]
+	}
+	if len(words) == 0 {
+		return cluster[0].Title
+	}
+	return strings.Join(words, ", ")
+}
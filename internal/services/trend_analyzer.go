@@ -0,0 +1,138 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"tech-news-agent/internal/models"
+)
+
+// maxTrendsPerSection caps each trend list in the digest
+const maxTrendsPerSection = 5
+
+// minTrendCount is the article count a topic needs in one of the two
+// periods before a change is reported
+const minTrendCount = 2
+
+// Topic term sources recorded with every run
+const (
+	TopicSourceEnrichment = "enrichment"
+	TopicSourceTitles     = "titles"
+)
+
+// TopicCounts counts articles per normalized topic term. Enriched articles
+// contribute their category and tags; others fall back to title keywords,
+// which are stable across weeks unlike generated cluster names.
+func TopicCounts(articles []models.Article) map[string]int {
+	counts := make(map[string]int)
+	for _, article := range articles {
+		seen := make(map[string]bool)
+		for _, term := range topicTerms(article) {
+			term = strings.ToLower(strings.TrimSpace(term))
+			if term == "" || term == "other" || seen[term] {
+				continue
+			}
+			seen[term] = true
+			counts[term]++
+		}
+	}
+	return counts
+}
+
+// TopicSource reports where the terms of TopicCounts come from. A run
+// counts as enriched when any article carries enrichment.
+func TopicSource(articles []models.Article) string {
+	for _, article := range articles {
+		if article.Enrichment != nil {
+			return TopicSourceEnrichment
+		}
+	}
+	return TopicSourceTitles
+}
+
+func topicTerms(article models.Article) []string {
+	if e := article.Enrichment; e != nil {
+		return append([]string{e.Category}, e.Tags...)
+	}
+
+	var terms []string
+	for _, word := range strings.FieldsFunc(article.Title, isTokenSeparator) {
+		if len([]rune(word)) < 4 || labelStopwords[strings.ToLower(word)] {
+			continue
+		}
+		terms = append(terms, word)
+	}
+	return terms
+}
+
+// ComputeTrends compares the current topic counts, taken from source, with
+// the previous run. It returns nil when there is no previous run or its
+// counts came from a different source, which would report every term as
+// emerging or fading.
+func ComputeTrends(previous *models.HistoryEntry, current map[string]int, source string) *models.Trends {
+	if previous == nil || previous.TopicSource != source {
+		return nil
+	}
+
+	trends := &models.Trends{PreviousPeriodEnd: previous.PeriodEnd}
+
+	for topic, count := range current {
+		before := previous.TopicCounts[topic]
+		trend := models.TopicTrend{Topic: topic, Current: count, Previous: before}
+		switch {
+		case before == 0 && count >= minTrendCount:
+			trends.Emerging = append(trends.Emerging, trend)
+		case before > 0 && significantChange(before, count):
+			trends.Changes = append(trends.Changes, trend)
+		}
+	}
+
+	for topic, before := range previous.TopicCounts {
+		if _, ok := current[topic]; !ok && before >= minTrendCount {
+			trends.Fading = append(trends.Fading, models.TopicTrend{Topic: topic, Previous: before})
+		}
+	}
+
+	sortTrends(trends.Changes, func(t models.TopicTrend) int { return abs(t.Delta()) })
+	sortTrends(trends.Emerging, func(t models.TopicTrend) int { return t.Current })
+	sortTrends(trends.Fading, func(t models.TopicTrend) int { return t.Previous })
+
+	trends.Changes = capTrends(trends.Changes)
+	trends.Emerging = capTrends(trends.Emerging)
+	trends.Fading = capTrends(trends.Fading)
+
+	return trends
+}
+
+// significantChange requires both an absolute and a relative change so a
+// topic going from 1 to 2 articles is not reported as doubling
+func significantChange(before, after int) bool {
+	delta := abs(after - before)
+	if max(before, after) < minTrendCount || delta < minTrendCount {
+		return false
+	}
+	return float64(delta) >= 0.5*float64(before)
+}
+
+// sortTrends orders trends by weight descending, then by name
+func sortTrends(trends []models.TopicTrend, weight func(models.TopicTrend) int) {
+	sort.Slice(trends, func(i, j int) bool {
+		if wi, wj := weight(trends[i]), weight(trends[j]); wi != wj {
+			return wi > wj
+		}
+		return trends[i].Topic < trends[j].Topic
+	})
+}
+
+func capTrends(trends []models.TopicTrend) []models.TopicTrend {
+	if len(trends) > maxTrendsPerSection {
+		return trends[:maxTrendsPerSection]
+	}
+	return trends
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"reflect"
+	"strconv"
+	"tech-news-agent/internal/models"
+	"testing"
+	"time"
+)
+
+func TestTopicCounts(t *testing.T) {
+	articles := []models.Article{
+		{Title: "ignored", Enrichment: &models.ArticleEnrichment{Category: "ai", Tags: []string{"Agents", "agents", "GPUs"}}},
+		{Title: "ignored", Enrichment: &models.ArticleEnrichment{Category: "other", Tags: []string{"gpus"}}},
+		// Without enrichment, title words of four or more letters count
+		{Title: "Nvidia ships new GPUs for agents"},
+	}
+	want := map[string]int{"ai": 1, "agents": 2, "gpus": 3, "nvidia": 1, "ships": 1}
+
+	if got := TopicCounts(articles); !reflect.DeepEqual(got, want) {
+		t.Errorf("TopicCounts = %v, want %v", got, want)
+	}
+	if got := TopicSource(articles); got != TopicSourceEnrichment {
+		t.Errorf("TopicSource = %q, want %q", got, TopicSourceEnrichment)
+	}
+	if got := TopicSource(articles[2:]); got != TopicSourceTitles {
+		t.Errorf("TopicSource without enrichment = %q, want %q", got, TopicSourceTitles)
+	}
+}
+
+func TestSignificantChange(t *testing.T) {
+	tests := []struct {
+		before, after int
+		want          bool
+	}{
+		{1, 2, false},
+		{2, 4, true},
+		{4, 2, true},
+		{10, 12, false},
+		{10, 15, true},
+		{10, 5, true},
+		{0, 1, false},
+	}
+
+	for _, tt := range tests {
+		if got := significantChange(tt.before, tt.after); got != tt.want {
+			t.Errorf("significantChange(%d, %d) = %v, want %v", tt.before, tt.after, got, tt.want)
+		}
+	}
+}
+
+func TestComputeTrends(t *testing.T) {
+	if trends := ComputeTrends(nil, map[string]int{"ai": 3}, TopicSourceTitles); trends != nil {
+		t.Errorf("ComputeTrends without a previous run = %+v, want nil", trends)
+	}
+
+	end := time.Date(2026, 10, 4, 0, 0, 0, 0, time.UTC)
+	previous := &models.HistoryEntry{
+		PeriodEnd:   end,
+		TopicCounts: map[string]int{"ai": 6, "cloud": 10, "crypto": 5, "vr": 1, "chips": 4},
+		TopicSource: TopicSourceEnrichment,
+	}
+	current := map[string]int{"ai": 10, "cloud": 11, "quantum": 3, "robots": 1, "chips": 2}
+
+	want := &models.Trends{
+		PreviousPeriodEnd: end,
+		Changes:           []models.TopicTrend{{Topic: "ai", Current: 10, Previous: 6}, {Topic: "chips", Current: 2, Previous: 4}},
+		Emerging:          []models.TopicTrend{{Topic: "quantum", Current: 3}},
+		Fading:            []models.TopicTrend{{Topic: "crypto", Previous: 5}},
+	}
+	if got := ComputeTrends(previous, current, TopicSourceEnrichment); !reflect.DeepEqual(got, want) {
+		t.Errorf("ComputeTrends = %+v, want %+v", got, want)
+	}
+
+	// Enrichment terms and title keywords are not compared, nor are runs
+	// stored before the source was recorded
+	if got := ComputeTrends(previous, current, TopicSourceTitles); got != nil {
+		t.Errorf("ComputeTrends across term sources = %+v, want nil", got)
+	}
+	previous.TopicSource = ""
+	if got := ComputeTrends(previous, current, TopicSourceEnrichment); got != nil {
+		t.Errorf("ComputeTrends against a run without a source = %+v, want nil", got)
+	}
+}
+
+func TestComputeTrendsCapsEachList(t *testing.T) {
+	current := make(map[string]int)
+	for i := 0; i < maxTrendsPerSection+3; i++ {
+		current["topic"+strconv.Itoa(i)] = 2 + i
+	}
+
+	trends := ComputeTrends(&models.HistoryEntry{TopicSource: TopicSourceTitles}, current, TopicSourceTitles)
+	if len(trends.Emerging) != maxTrendsPerSection {
+		t.Fatalf("got %d emerging topics, want %d", len(trends.Emerging), maxTrendsPerSection)
+	}
+	// The largest topics come first
+	if first := trends.Emerging[0]; first.Topic != "topic7" || first.Current != 9 {
+		t.Errorf("first emerging topic is %+v, want topic7 with 9 articles", first)
+	}
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"tech-news-agent/internal/config"
+	"tech-news-agent/internal/models"
+	"time"
+)
+
+// ErrBudgetExceeded is returned when an LLM call would exceed the monthly budget
+var ErrBudgetExceeded = errors.New("monthly LLM budget exceeded")
+
+// Process-wide LLM usage counters exported through expvar
+var (
+	metricPromptTokens     = expvar.NewMap("llm_prompt_tokens")
+	metricCompletionTokens = expvar.NewMap("llm_completion_tokens")
+	metricCalls            = expvar.NewMap("llm_calls")
+	metricCostUSD          = expvar.NewFloat("llm_cost_usd")
+)
+
+// UsageTracker records token usage and cost per run, persists it to a
+// ledger file and enforces the monthly budget
+type UsageTracker struct {
+	prices     map[string]config.ModelPrice
+	ledgerPath string
+	budget     float64
+
+	mu         sync.Mutex
+	run        models.UsageReport
+	monthSpent float64
+	// reserved is the estimated cost of calls allowed but not yet finished
+	reserved float64
+}
+
+// NewUsageTracker creates a new usage tracker instance
+func NewUsageTracker(cfg *config.Config) *UsageTracker {
+	return &UsageTracker{
+		prices:     cfg.LLMPrices,
+		ledgerPath: filepath.Join(cfg.DataDir, "usage.jsonl"),
+		budget:     cfg.MonthlyBudgetUSD,
+		run:        newUsageReport(),
+	}
+}
+
+func newUsageReport() models.UsageReport {
+	return models.UsageReport{
+		StartedAt: time.Now(),
+		ByModel:   make(map[string]*models.ModelUsage),
+	}
+}
+
+// StartRun resets the per-run counters and loads the month-to-date spend
+func (t *UsageTracker) StartRun() error {
+	err := t.LoadMonthToDate()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.run = newUsageReport()
+
+	return err
+}
+
+// LoadMonthToDate loads the month-to-date spend from the ledger
+func (t *UsageTracker) LoadMonthToDate() error {
+	spent, err := t.monthToDate(time.Now())
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.monthSpent = spent
+
+	return err
+}
+
+// Record adds the usage of a completed LLM call to the current run
+func (t *UsageTracker) Record(result *LLMResult) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.add(&t.run, result)
+}
+
+// RecordStandalone appends the usage of an LLM call made outside a run,
+// such as an archive question, to the ledger as an entry of its own
+func (t *UsageTracker) RecordStandalone(result *LLMResult) error {
+	report := newUsageReport()
+
+	t.mu.Lock()
+	t.monthSpent += t.add(&report, result)
+	t.mu.Unlock()
+
+	return t.appendLedger(report)
+}
+
+// add adds the usage of an LLM call to report and returns its cost
+func (t *UsageTracker) add(report *models.UsageReport, result *LLMResult) float64 {
+	report.Calls++
+	if result.Cached {
+		report.CachedCalls++
+		return 0
+	}
+
+	key := result.Provider + "/" + result.Model
+	cost := t.cost(result.Model, result.PromptTokens, result.CompletionTokens)
+
+	usage, ok := report.ByModel[key]
+	if !ok {
+		usage = &models.ModelUsage{}
+		report.ByModel[key] = usage
+	}
+	usage.Calls++
+	usage.PromptTokens += result.PromptTokens
+	usage.CompletionTokens += result.CompletionTokens
+	usage.CostUSD += cost
+
+	report.PromptTokens += result.PromptTokens
+	report.CompletionTokens += result.CompletionTokens
+	report.CostUSD += cost
+
+	metricCalls.Add(key, 1)
+	metricPromptTokens.Add(key, int64(result.PromptTokens))
+	metricCompletionTokens.Add(key, int64(result.CompletionTokens))
+	metricCostUSD.Add(cost)
+	return cost
+}
+
+// Allow checks whether a call to model with the given prompt could exceed the
+// monthly budget, assuming the full output token limit is used. The estimate
+// is reserved so concurrent calls cannot overshoot the budget together; the
+// caller passes the returned reservation to Release once the call's usage is
+// recorded or the call failed.
+func (t *UsageTracker) Allow(model, prompt string, params config.GenerationParams) (float64, error) {
+	if t.budget <= 0 {
+		return 0, nil
+	}
+
+	// Roughly four characters per token for English text
+	estimate := t.cost(model, len(prompt)/4, int(params.MaxOutputTokens))
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	spent := t.monthSpent + t.run.CostUSD + t.reserved
+	if spent+estimate > t.budget {
+		return 0, fmt.Errorf("%w: spent or reserved $%.4f of $%.2f, next call to %s estimated at $%.4f",
+			ErrBudgetExceeded, spent, t.budget, model, estimate)
+	}
+	t.reserved += estimate
+	return estimate, nil
+}
+
+// Release returns a reservation made by Allow
+func (t *UsageTracker) Release(reservation float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.reserved = max(t.reserved-reservation, 0)
+}
+
+// Report returns a snapshot of the current run's usage
+func (t *UsageTracker) Report() models.UsageReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	report := t.run
+	report.ByModel = make(map[string]*models.ModelUsage, len(t.run.ByModel))
+	for key, usage := range t.run.ByModel {
+		u := *usage
+		report.ByModel[key] = &u
+	}
+	return report
+}
+
+// FinishRun appends the current run to the usage ledger
+func (t *UsageTracker) FinishRun() (models.UsageReport, error) {
+	report := t.Report()
+	return report, t.appendLedger(report)
+}
+
+// appendLedger appends report to the usage ledger as one JSON line
+func (t *UsageTracker) appendLedger(report models.UsageReport) error {
+	if err := os.MkdirAll(filepath.Dir(t.ledgerPath), 0o755); err != nil {
+		return fmt.Errorf("creating data directory: %w", err)
+	}
+
+	f, err := os.OpenFile(t.ledgerPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening usage ledger: %w", err)
+	}
+	defer f.Close()
+
+	if err := json.NewEncoder(f).Encode(report); err != nil {
+		return fmt.Errorf("writing usage ledger: %w", err)
+	}
+	return nil
+}
+
+// monthToDate sums the ledger entries of the calendar month containing now
+func (t *UsageTracker) monthToDate(now time.Time) (float64, error) {
+	f, err := os.Open(t.ledgerPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("opening usage ledger: %w", err)
+	}
+	defer f.Close()
+
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	var spent float64
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry models.UsageReport
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if !entry.StartedAt.Before(monthStart) {
+			spent += entry.CostUSD
+		}
+	}
+	return spent, scanner.Err()
+}
+
+func (t *UsageTracker) cost(model string, promptTokens, completionTokens int) float64 {
+	price := t.prices[model]
+	return (float64(promptTokens)*price.InputPerMillion + float64(completionTokens)*price.OutputPerMillion) / 1_000_000
+}
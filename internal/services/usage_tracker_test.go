@@ -0,0 +1,32 @@
+package services
+
+import (
+	"errors"
+	"tech-news-agent/internal/config"
+	"testing"
+)
+
+func TestUsageTrackerReservesAllowedCalls(t *testing.T) {
+	tracker := NewUsageTracker(&config.Config{
+		DataDir:          t.TempDir(),
+		MonthlyBudgetUSD: 1,
+		// An output token costs a micro-dollar, so 600k are $0.60
+		LLMPrices: map[string]config.ModelPrice{"m": {OutputPerMillion: 1}},
+	})
+	params := config.GenerationParams{MaxOutputTokens: 600_000}
+
+	first, err := tracker.Allow("m", "", params)
+	if err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+	// The first call is still running, so its estimate counts
+	if _, err := tracker.Allow("m", "", params); !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("second concurrent call: err = %v, want ErrBudgetExceeded", err)
+	}
+
+	tracker.Record(&LLMResult{Provider: "p", Model: "m", CompletionTokens: 100_000})
+	tracker.Release(first)
+	if _, err := tracker.Allow("m", "", params); err != nil {
+		t.Fatalf("call after the first finished at $0.10: %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"tech-news-agent/internal/config"
+	"tech-news-agent/internal/models"
+)
+
+// Watchlist finds the collected articles that mention the watched entities
+type Watchlist struct {
+	entities []watchPattern
+}
+
+type watchPattern struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// NewWatchlist creates a new watchlist matcher
+func NewWatchlist(entities []config.WatchEntity) *Watchlist {
+	w := &Watchlist{}
+	for _, entity := range entities {
+		var names []string
+		for _, name := range append([]string{entity.Name}, entity.Aliases...) {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, regexp.QuoteMeta(name))
+			}
+		}
+		sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+
+		// Letters and digits around a name mean it is part of another word;
+		// \b is not used so names like "C++" or ".NET" still match
+		expr := `(?:^|[^\pL\pN_])(?:` + strings.Join(names, "|") + `)(?:[^\pL\pN_]|$)`
+		if !entity.CaseSensitive {
+			expr = "(?i)" + expr
+		}
+		w.entities = append(w.entities, watchPattern{name: entity.Name, pattern: regexp.MustCompile(expr)})
+	}
+	return w
+}
+
+// Match returns one entry per watched entity with the articles mentioning
+// it in their title, description or content, most important first
+func (w *Watchlist) Match(articles []models.Article) []models.WatchlistMatch {
+	ranked := RankArticles(articles)
+
+	matches := make([]models.WatchlistMatch, 0, len(w.entities))
+	for _, entity := range w.entities {
+		match := models.WatchlistMatch{Entity: entity.name}
+		seen := make(map[string]bool)
+		for _, article := range ranked {
+			if seen[article.URL] {
+				continue
+			}
+			text := article.Title + "\n" + article.Desc + "\n" + article.Content
+			if entity.pattern.MatchString(text) {
+				seen[article.URL] = true
+				match.Articles = append(match.Articles, article.Brief())
+			}
+		}
+		matches = append(matches, match)
+	}
+	return matches
+}
+
+// watchlistMentions counts the articles matched across all entities
+func watchlistMentions(matches []models.WatchlistMatch) int {
+	total := 0
+	for _, match := range matches {
+		total += len(match.Articles)
+	}
+	return total
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"reflect"
+	"tech-news-agent/internal/config"
+	"tech-news-agent/internal/models"
+	"testing"
+	"time"
+)
+
+func TestWatchlistMatchesEntity(t *testing.T) {
+	tests := []struct {
+		name   string
+		entity config.WatchEntity
+		text   string
+		want   bool
+	}{
+		{"name", config.WatchEntity{Name: "Nvidia"}, "Nvidia ships H300", true},
+		{"any case", config.WatchEntity{Name: "Nvidia"}, "NVIDIA ships H300", true},
+		{"case sensitive", config.WatchEntity{Name: "Rust", CaseSensitive: true}, "rust on old bridges", false},
+		{"case sensitive hit", config.WatchEntity{Name: "Rust", CaseSensitive: true}, "Rust 2.0 lands", true},
+		{"alias", config.WatchEntity{Name: "Alphabet", Aliases: []string{"Google", " "}}, "Google cuts prices", true},
+		{"inside a word", config.WatchEntity{Name: "Rust"}, "Rustaceans meet", false},
+		{"inside a longer name", config.WatchEntity{Name: "Go"}, "Google cuts prices", false},
+		{"digit after the name", config.WatchEntity{Name: "GPT"}, "GPT5 is out", false},
+		{"underscore after the name", config.WatchEntity{Name: "Go"}, "go_vet runs", false},
+		{"punctuation around the name", config.WatchEntity{Name: "Go"}, `"Go", they said`, true},
+		{"trailing symbols", config.WatchEntity{Name: "C++"}, "Written in C++ and C", true},
+		{"trailing symbols at the end", config.WatchEntity{Name: "C++"}, "Written in C++", true},
+		{"trailing symbols inside a word", config.WatchEntity{Name: "C++"}, "C++x is new", false},
+		{"leading dot", config.WatchEntity{Name: ".NET"}, "Ships on .NET 10", true},
+		{"leading dot inside a word", config.WatchEntity{Name: ".NET"}, "Ships on ASP.NET", false},
+		{"pattern characters are literal", config.WatchEntity{Name: "A.I"}, "AxI is not it", false},
+		{"on its own line", config.WatchEntity{Name: "TSMC"}, "\nTSMC beats estimates\n", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			article := models.Article{Title: tt.text, URL: "https://x.io/a"}
+			matches := NewWatchlist([]config.WatchEntity{tt.entity}).Match([]models.Article{article})
+			if got := len(matches[0].Articles) == 1; got != tt.want {
+				t.Errorf("%s matches %q: %v, want %v", tt.entity.Name, tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWatchlistMatch(t *testing.T) {
+	day := time.Date(2026, 10, 11, 0, 0, 0, 0, time.UTC)
+	articles := []models.Article{
+		{Title: "Old Nvidia news", URL: "https://x.io/1", PublishedAt: day.AddDate(0, 0, -2)},
+		{Title: "Chips", Content: "Nvidia and Intel", URL: "https://x.io/2", PublishedAt: day,
+			Enrichment: &models.ArticleEnrichment{Importance: 9}},
+		{Title: "New Nvidia news", URL: "https://x.io/3", PublishedAt: day},
+		{Title: "Same story, other feed", Desc: "nvidia", URL: "https://x.io/3", PublishedAt: day},
+	}
+	watchlist := NewWatchlist([]config.WatchEntity{{Name: "Nvidia"}, {Name: "Intel"}, {Name: "AMD"}})
+
+	var got [][]string
+	matches := watchlist.Match(articles)
+	for _, match := range matches {
+		titles := []string{match.Entity}
+		for _, brief := range match.Articles {
+			titles = append(titles, brief.Title)
+		}
+		got = append(got, titles)
+	}
+	// Important articles first, then newest; every URL once; entities
+	// without mentions are kept
+	want := [][]string{
+		{"Nvidia", "Chips", "New Nvidia news", "Old Nvidia news"},
+		{"Intel", "Chips"},
+		{"AMD"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Match = %q, want %q", got, want)
+	}
+	if n := watchlistMentions(matches); n != 4 {
+		t.Errorf("watchlistMentions = %d, want 4", n)
+	}
+}
@@ -0,0 +1,280 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"tech-news-agent/internal/config"
+	"tech-news-agent/internal/i18n"
+	"tech-news-agent/internal/models"
+	"tech-news-agent/internal/renderer"
+	"text/template"
+	"time"
+)
+
+var webhookRenderer = renderer.New(renderer.CommonMark)
+
+// Events posted to generic webhooks
+const (
+	webhookEventDigest = "digest"
+	webhookEventError  = "error"
+	webhookEventPing   = "ping"
+)
+
+// Headers of generic webhook posts. The signature covers
+// "<timestamp>.<body>" so receivers can reject replayed deliveries.
+const (
+	webhookSignatureHeader = "X-Webhook-Signature"
+	webhookTimestampHeader = "X-Webhook-Timestamp"
+	webhookDeliveryHeader  = "X-Webhook-Delivery"
+	webhookEventHeader     = "X-Webhook-Event"
+)
+
+// WebhookNotifier posts digests as JSON to an arbitrary HTTP endpoint,
+// optionally signed and reshaped by a template
+type WebhookNotifier struct {
+	url        string
+	language   string
+	maxChars   int
+	secret     string
+	headers    map[string]string
+	template   *template.Template
+	maxRetries int
+	httpClient *http.Client
+}
+
+var _ Notifier = (*WebhookNotifier)(nil)
+
+// NewWebhookNotifier creates a new webhook notifier for dest, failing when
+// WEBHOOK_TEMPLATE_FILE is not a valid template
+func NewWebhookNotifier(cfg *config.Config, dest config.Destination) (*WebhookNotifier, error) {
+	wn := &WebhookNotifier{
+		url:        dest.Target,
+		language:   i18n.Normalize(dest.Language),
+		maxChars:   dest.MaxChars,
+		secret:     cfg.WebhookSecret,
+		headers:    cfg.WebhookHeaders,
+		maxRetries: cfg.WebhookMaxRetries,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+
+	if cfg.WebhookTemplate != "" {
+		tmpl, err := template.New("webhook").Funcs(template.FuncMap{"json": templateJSON}).Parse(cfg.WebhookTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("parsing WEBHOOK_TEMPLATE_FILE: %w", err)
+		}
+		wn.template = tmpl
+	}
+
+	return wn, nil
+}
+
+// Destination identifies the webhook
+func (wn *WebhookNotifier) Destination() string {
+	return webhookDestination("webhook", wn.url)
+}
+
+// Language returns the digest language of the endpoint
+func (wn *WebhookNotifier) Language() string {
+	return wn.language
+}
+
+// MaxChars returns the length budget of a digest (0 is unlimited)
+func (wn *WebhookNotifier) MaxChars() int {
+	return wn.maxChars
+}
+
+// WebhookPayload is the body posted to generic webhooks, and the data a
+// WEBHOOK_TEMPLATE_FILE is executed with
+type WebhookPayload struct {
+	Event  string    `json:"event"`
+	SentAt time.Time `json:"sentAt"`
+	// Summary is set for digest events
+	Summary *models.NewsSummary `json:"summary,omitempty"`
+	// Articles references every article the digest mentions, deduplicated
+	Articles []models.ArticleBrief `json:"articles,omitempty"`
+	// Text is the digest rendered as markdown
+	Text  string `json:"text,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// FormatMessage renders the digest as markdown, the text receivers would
+// display
+func (wn *WebhookNotifier) FormatMessage(summary *models.NewsSummary) string {
+	return webhookRenderer.Document(renderer.Build(summary))
+}
+
+// SendSummary posts the digest event
+func (wn *WebhookNotifier) SendSummary(ctx context.Context, summary *models.NewsSummary) error {
+	payload := WebhookPayload{
+		Event:    webhookEventDigest,
+		SentAt:   time.Now().UTC(),
+		Summary:  summary,
+		Articles: articleRefs(summary),
+		Text:     wn.FormatMessage(summary),
+	}
+	if err := wn.post(ctx, payload); err != nil {
+		return fmt.Errorf("sending digest: %w", err)
+	}
+	return nil
+}
+
+// SendError posts an error event
+func (wn *WebhookNotifier) SendError(ctx context.Context, errMsg string) error {
+	payload := WebhookPayload{Event: webhookEventError, SentAt: time.Now().UTC(), Error: errMsg}
+	if err := wn.post(ctx, payload); err != nil {
+		return fmt.Errorf("sending error event: %w", err)
+	}
+	return nil
+}
+
+// TestConnection posts a ping event to verify the endpoint
+func (wn *WebhookNotifier) TestConnection(ctx context.Context) error {
+	payload := WebhookPayload{Event: webhookEventPing, SentAt: time.Now().UTC()}
+	if err := wn.post(ctx, payload); err != nil {
+		return fmt.Errorf("ping failed: %w", err)
+	}
+	return nil
+}
+
+// post encodes payload and delivers it, retrying network errors and
+// transient statuses with backoff. Retries resend the same body, signature
+// and delivery ID so receivers can deduplicate.
+func (wn *WebhookNotifier) post(ctx context.Context, payload WebhookPayload) error {
+	body, err := wn.encode(payload)
+	if err != nil {
+		return err
+	}
+
+	deliveryID, err := newDeliveryID()
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(payload.SentAt.Unix(), 10)
+
+	var lastErr error
+	for attempt := 0; attempt <= wn.maxRetries; attempt++ {
+		if attempt > 0 {
+			if err := sleepContext(ctx, backoff(attempt)); err != nil {
+				return errors.Join(lastErr, err)
+			}
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, wn.url, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("creating request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "tech-news-agent")
+		for name, value := range wn.headers {
+			req.Header.Set(name, value)
+		}
+		req.Header.Set(webhookEventHeader, payload.Event)
+		req.Header.Set(webhookDeliveryHeader, deliveryID)
+		req.Header.Set(webhookTimestampHeader, timestamp)
+		if wn.secret != "" {
+			req.Header.Set(webhookSignatureHeader, "sha256="+signWebhook(wn.secret, timestamp, body))
+		}
+
+		resp, err := wn.httpClient.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("making request: %w", err)
+			}
+			lastErr = fmt.Errorf("making request: %w", err)
+			continue
+		}
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+		resp.Body.Close()
+
+		if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+			return nil
+		}
+		lastErr = fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, string(respBody))
+		if !isRetryableStatus(resp.StatusCode) && resp.StatusCode != http.StatusRequestTimeout {
+			return lastErr
+		}
+	}
+	return fmt.Errorf("giving up after %d attempts: %w", wn.maxRetries+1, lastErr)
+}
+
+// encode renders payload as JSON, through the template when one is
+// configured
+func (wn *WebhookNotifier) encode(payload WebhookPayload) ([]byte, error) {
+	if wn.template == nil {
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("encoding payload: %w", err)
+		}
+		return body, nil
+	}
+
+	var buf bytes.Buffer
+	if err := wn.template.Execute(&buf, payload); err != nil {
+		return nil, fmt.Errorf("executing webhook template: %w", err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf("webhook template did not produce valid JSON for %s event", payload.Event)
+	}
+	return buf.Bytes(), nil
+}
+
+// signWebhook returns the hex HMAC-SHA256 of "<timestamp>.<body>"
+func signWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newDeliveryID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating delivery ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// templateJSON encodes v for use inside webhook templates, e.g.
+// {"content": {{json .Text}}}
+func templateJSON(v any) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// articleRefs collects the articles a digest links to: top stories, topic
+// clusters and watchlist matches, each URL once
+func articleRefs(summary *models.NewsSummary) []models.ArticleBrief {
+	var refs []models.ArticleBrief
+	seen := make(map[string]bool)
+	add := func(articles []models.ArticleBrief) {
+		for _, article := range articles {
+			if article.URL == "" || seen[article.URL] {
+				continue
+			}
+			seen[article.URL] = true
+			refs = append(refs, article)
+		}
+	}
+
+	add(summary.TopArticles)
+	for _, topic := range summary.KeyTopics {
+		add(topic.Articles)
+	}
+	for _, match := range summary.Watchlist {
+		add(match.Articles)
+	}
+	return refs
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"tech-news-agent/internal/config"
+	"testing"
+)
+
+func newTestWebhookNotifier(t *testing.T, cfg *config.Config, url string) *WebhookNotifier {
+	t.Helper()
+	notifier, err := NewWebhookNotifier(cfg, config.Destination{Kind: config.DestinationWebhook, Target: url, Language: "en"})
+	if err != nil {
+		t.Fatalf("NewWebhookNotifier: %v", err)
+	}
+	return notifier
+}
+
+func TestSignWebhook(t *testing.T) {
+	got := signWebhook("s3cret", "1700000000", []byte(`{"event":"ping"}`))
+	if want := "6846770b4cb3a67aa55cb7edb85678c8c36b8caf1022a60693ee1a47db73c48d"; got != want {
+		t.Errorf("signWebhook = %s, want %s", got, want)
+	}
+}
+
+func TestWebhookSignsAndRetriesWithSameDelivery(t *testing.T) {
+	const secret = "s3cret"
+	server, requests := recordingServer(t, replyStatuses("", "", http.StatusServiceUnavailable))
+	cfg := &config.Config{WebhookSecret: secret, WebhookHeaders: map[string]string{"X-Team": "news"}, WebhookMaxRetries: 2}
+	notifier := newTestWebhookNotifier(t, cfg, server.URL)
+
+	if err := notifier.SendSummary(context.Background(), longSummary(1)); err != nil {
+		t.Fatalf("SendSummary: %v", err)
+	}
+	if len(*requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(*requests))
+	}
+
+	first := (*requests)[0]
+	for _, req := range *requests {
+		timestamp := req.header.Get(webhookTimestampHeader)
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(timestamp + "."))
+		mac.Write(req.body)
+		if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); req.header.Get(webhookSignatureHeader) != want {
+			t.Errorf("signature = %q, want %q", req.header.Get(webhookSignatureHeader), want)
+		}
+		for _, name := range []string{webhookDeliveryHeader, webhookTimestampHeader, webhookSignatureHeader} {
+			if req.header.Get(name) != first.header.Get(name) {
+				t.Errorf("retry changed %s from %q to %q", name, first.header.Get(name), req.header.Get(name))
+			}
+		}
+		if string(req.body) != string(first.body) {
+			t.Errorf("retry changed the body")
+		}
+		if got := req.header.Get(webhookEventHeader); got != webhookEventDigest {
+			t.Errorf("event = %q, want %q", got, webhookEventDigest)
+		}
+		if got := req.header.Get("X-Team"); got != "news" {
+			t.Errorf("custom header = %q, want news", got)
+		}
+	}
+	if len(first.header.Get(webhookDeliveryHeader)) != 32 {
+		t.Errorf("delivery ID %q is not 16 hex bytes", first.header.Get(webhookDeliveryHeader))
+	}
+
+	var payload WebhookPayload
+	if err := json.Unmarshal(first.body, &payload); err != nil {
+		t.Fatalf("decoding payload: %v", err)
+	}
+	if payload.Event != webhookEventDigest || payload.Summary == nil || !strings.Contains(payload.Text, "Chipmakers raised guidance") {
+		t.Errorf("unexpected payload %+v", payload)
+	}
+	if want := strconv.FormatInt(payload.SentAt.Unix(), 10); first.header.Get(webhookTimestampHeader) != want {
+		t.Errorf("timestamp = %q, want sentAt %s", first.header.Get(webhookTimestampHeader), want)
+	}
+}
+
+func TestWebhookWithoutSecretIsUnsigned(t *testing.T) {
+	server, requests := recordingServer(t, nil)
+	if err := newTestWebhookNotifier(t, &config.Config{}, server.URL).TestConnection(context.Background()); err != nil {
+		t.Fatalf("TestConnection: %v", err)
+	}
+	if sig := (*requests)[0].header.Get(webhookSignatureHeader); sig != "" {
+		t.Errorf("unsigned webhook sent signature %q", sig)
+	}
+}
+
+func TestWebhookDoesNotRetryClientErrors(t *testing.T) {
+	server, requests := recordingServer(t, replyStatuses("", "", http.StatusBadRequest))
+	notifier := newTestWebhookNotifier(t, &config.Config{WebhookMaxRetries: 3}, server.URL)
+
+	if err := notifier.SendError(context.Background(), "boom"); err == nil {
+		t.Fatal("SendError succeeded on a 400")
+	}
+	if len(*requests) != 1 {
+		t.Errorf("got %d requests, want 1", len(*requests))
+	}
+}
+
+func TestWebhookTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "reshaped payload",
+			template: `{"kind": {{json .Event}}, "content": {{json .Error}}}`,
+			want:     `{"kind": "error", "content": "quota \"exceeded\"\n"}`,
+		},
+		{
+			name:     "invalid JSON",
+			template: `{"content": {{.Error}}}`,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := recordingServer(t, nil)
+			notifier := newTestWebhookNotifier(t, &config.Config{WebhookTemplate: tt.template}, server.URL)
+
+			err := notifier.SendError(context.Background(), "quota \"exceeded\"\n")
+			if tt.wantErr {
+				if err == nil || len(*requests) != 0 {
+					t.Errorf("err = %v after %d requests, want an error and none", err, len(*requests))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SendError: %v", err)
+			}
+			if got := string((*requests)[0].body); got != tt.want {
+				t.Errorf("body = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewWebhookNotifierRejectsBadTemplate(t *testing.T) {
+	_, err := NewWebhookNotifier(&config.Config{WebhookTemplate: `{{json .Text`}, config.Destination{Target: "http://x.io"})
+	if err == nil {
+		t.Error("NewWebhookNotifier accepted an unparsable template")
+	}
+}